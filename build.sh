@@ -14,5 +14,5 @@ fi
 # Build main package (CLI/game)
 go build -o goengine ./cmd/main.go
 
-# Build shared library from export/export.go
-go build -buildmode=c-shared -o libgoengine.so ./export/export.go
+# Build shared library from the export package
+go build -buildmode=c-shared -o libgoengine.so ./export
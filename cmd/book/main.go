@@ -0,0 +1,43 @@
+// Command book builds an opening book from SGF game collections.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/RubikNube/GoInGo/pkg/book"
+)
+
+func main() {
+	data := flag.String("data", "", "glob of SGF files to import")
+	out := flag.String("out", "book.json", "book file to write")
+	depth := flag.Int("depth", 12, "number of moves per game to import")
+	minCount := flag.Int("mincount", 2, "drop moves played fewer times")
+	extend := flag.Bool("append", false, "add to the existing book file instead of starting a new one")
+	flag.Parse()
+	if *data == "" {
+		log.Fatal("-data is required")
+	}
+
+	paths, err := filepath.Glob(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	b := book.New()
+	if *extend {
+		if b, err = book.Load(*out); err != nil {
+			log.Fatal(err)
+		}
+	}
+	games, err := b.Import(paths, *depth)
+	if err != nil {
+		log.Fatal(err)
+	}
+	b.Prune(*minCount)
+	if err := b.Save(*out); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("imported %d games, %d positions in %s\n", games, len(b.Positions), *out)
+}
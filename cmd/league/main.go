@@ -0,0 +1,124 @@
+// Command league runs round-robin or gauntlet tournaments between the
+// built-in engine versions and keeps their ratings in a JSON file.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/RubikNube/GoInGo/pkg/book"
+	"github.com/RubikNube/GoInGo/pkg/engine"
+	"github.com/RubikNube/GoInGo/pkg/league"
+	"github.com/RubikNube/GoInGo/pkg/pattern"
+)
+
+func main() {
+	ratingsPath := flag.String("ratings", "league.json", "league file holding players and results")
+	mode := flag.String("mode", "roundrobin", "tournament mode: roundrobin, gauntlet or table")
+	candidate := flag.String("candidate", "alphabeta", "engine playing the gauntlet")
+	players := flag.String("players", "", "comma separated engines to include (default: all built-in engines)")
+	games := flag.Int("games", 2, "games per pairing")
+	maxMoves := flag.Int("maxmoves", 100, "maximum moves per game")
+	asJSON := flag.Bool("json", false, "print the summary as JSON")
+	weightsPath := flag.String("weights", "", "weights file for an additional alphabeta-tuned engine")
+	bookPath := flag.String("book", "", "opening book for an additional alphabeta-book engine")
+	patternsPath := flag.String("patterns", "", "pattern table for additional alphabeta-patterns and random-smart engines")
+	flag.Parse()
+
+	builtin := league.Builtin()
+	if *weightsPath != "" {
+		weights, err := engine.LoadWeights(*weightsPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		builtin = append(builtin, league.Entrant{
+			Name: "alphabeta-tuned",
+			New:  func() engine.Engine { return engine.NewAlphaBetaEngineWithWeights(weights) },
+		})
+	}
+	if *bookPath != "" {
+		b, err := book.Load(*bookPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		builtin = append(builtin, league.Entrant{
+			Name: "alphabeta-book",
+			New:  func() engine.Engine { return book.NewEngine(b, engine.NewAlphaBetaEngine()) },
+		})
+	}
+	if *patternsPath != "" {
+		t, err := pattern.LoadTable(*patternsPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		builtin = append(builtin,
+			league.Entrant{Name: "alphabeta-patterns", New: func() engine.Engine {
+				e := engine.NewAlphaBetaEngine()
+				e.SetPatterns(t)
+				return e
+			}},
+			league.Entrant{Name: "random-smart", New: func() engine.Engine { return engine.NewSmartRandomEngine(t) }},
+		)
+	}
+	entrants := builtin
+	if *players != "" {
+		var selected []league.Entrant
+		for _, name := range strings.Split(*players, ",") {
+			e, ok := league.Lookup(builtin, strings.TrimSpace(name))
+			if !ok {
+				log.Fatalf("unknown engine %q", name)
+			}
+			selected = append(selected, e)
+		}
+		entrants = selected
+	}
+
+	l, err := league.Load(*ratingsPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts := league.Options{
+		Games:    *games,
+		MaxMoves: *maxMoves,
+		Progress: func(black, white string, result int) {
+			outcome := "draw"
+			if result > 0 {
+				outcome = black + " wins"
+			} else if result < 0 {
+				outcome = white + " wins"
+			}
+			fmt.Fprintf(os.Stderr, "%s (B) vs %s (W): %s\n", black, white, outcome)
+		},
+	}
+
+	switch *mode {
+	case "roundrobin":
+		l.RoundRobin(entrants, opts)
+	case "gauntlet":
+		c, ok := league.Lookup(builtin, *candidate)
+		if !ok {
+			log.Fatalf("unknown candidate %q", *candidate)
+		}
+		l.Gauntlet(c, entrants, opts)
+	case "table":
+	default:
+		log.Fatalf("unknown mode %q", *mode)
+	}
+
+	if *mode != "table" {
+		if err := l.Save(*ratingsPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *asJSON {
+		err = l.WriteJSON(os.Stdout)
+	} else {
+		err = l.WriteTable(os.Stdout)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
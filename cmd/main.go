@@ -2,14 +2,24 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 	"unicode"
 
+	"github.com/RubikNube/GoInGo/pkg/book"
+	"github.com/RubikNube/GoInGo/pkg/clock"
 	"github.com/RubikNube/GoInGo/pkg/engine"
 	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/netplay"
+	"github.com/RubikNube/GoInGo/pkg/pattern"
+	"github.com/RubikNube/GoInGo/pkg/sgf"
+	"github.com/RubikNube/GoInGo/pkg/tsumego"
 	"github.com/jroimartin/gocui"
 )
 
@@ -21,7 +31,6 @@ var (
 	cursorRow, cursorCol int8
 	gui                  game.Gui
 	keybindings          map[string]string
-	prevBoard            *game.Board       // Track previous board for Ko rule
 	currentPlayer        int8          = 1 // Track current player (1 or 2), start with Black
 	koPoint              *game.Point       // Track Ko point (nil if no Ko)
 	passCount            int8              // Track consecutive passes
@@ -30,6 +39,19 @@ var (
 	selectedEngine       engine.Engine     // The engine instance
 )
 
+// Tsumego training session (nil when playing a game)
+var training *tsumego.Session
+
+// Clocks of a local game (nil when playing without time control)
+var gameClock *clock.Clock
+
+// Networked game against another player (nil when playing locally)
+var (
+	remote     *netplay.Session
+	undoAsked  bool   // the opponent asked to undo and awaits an answer
+	recordPath string // SGF file for the networked game
+)
+
 func loadConfig(path string) (Config, error) {
 	var cfg Config
 	f, err := os.Open(path)
@@ -42,20 +64,50 @@ func loadConfig(path string) (Config, error) {
 }
 
 func printMovePrompt(v *gocui.View) {
+	if training != nil {
+		printTrainingPrompt(v)
+		return
+	}
+	if remote != nil {
+		printRemotePrompt(v)
+		return
+	}
+	if gameClock != nil && gameClock.Flagged() != game.Empty {
+		fmt.Fprintf(v, "Game Over! %s loses on time. %s to quit", colorLabel(gameClock.Flagged()), keybindings["quit"])
+		return
+	}
 	fmt.Fprintf(v, "Move (%s/%s/%s/%s), %s to place stone, %s to pass, %s to quit", keybindings["moveLeft"], keybindings["moveDown"], keybindings["moveUp"], keybindings["moveRight"], keybindings["placeStone"], keybindings["passTurn"], keybindings["quit"])
 }
 
 func layout(g *gocui.Gui) error {
 	maxX, _ := g.Size()
 	boardHeight := 22 // enough for 9x9 grid with borders
-	if v, err := g.SetView("board", 0, 0, maxX-1, boardHeight); err != nil {
+	boardRight := maxX - 1
+	if remote != nil && maxX > boardWidth+20 {
+		// Chat pane to the right of the board
+		boardRight = boardWidth
+		if err := layoutChat(g, boardWidth+1, maxX-1, boardHeight); err != nil {
+			return err
+		}
+	}
+	if v, err := g.SetView("board", 0, 0, boardRight, boardHeight); err != nil {
 		if err != gocui.ErrUnknownView {
 			return err
 		}
 		v.Title = "Go (Baduk)"
 		v.Wrap = false
+		if _, err := g.SetCurrentView("board"); err != nil {
+			return err
+		}
+	}
+	promptTop := boardHeight + 1
+	if gameClock != nil {
+		if err := layoutClock(g, promptTop, maxX-1); err != nil {
+			return err
+		}
+		promptTop += 3
 	}
-	if v, err := g.SetView("prompt", 0, boardHeight+1, maxX-1, boardHeight+3); err != nil {
+	if v, err := g.SetView("prompt", 0, promptTop, maxX-1, promptTop+2); err != nil {
 		if err != gocui.ErrUnknownView {
 			return err
 		}
@@ -65,6 +117,10 @@ func layout(g *gocui.Gui) error {
 	// Always redraw board
 	if v, err := g.View("board"); err == nil {
 		v.Clear()
+		v.Title = "Go (Baduk)"
+		if len(gui.Marked) > 0 {
+			v.Title = "Go (Baduk) - (stones) can be captured in a ladder"
+		}
 		gui.DrawGridToWriter(v, cursorRow, cursorCol)
 	}
 	return nil
@@ -111,130 +167,43 @@ func moveCursor(dRow, dCol int8, jumpOverOccupied bool) func(*gocui.Gui, *gocui.
 }
 
 func placeStone(g *gocui.Gui, v *gocui.View) error {
-	if gameOver {
-		return nil
+	if training != nil {
+		return trainingMove(g, &game.Point{Row: cursorRow, Col: cursorCol})
 	}
-	if gui.Grid[cursorRow][cursorCol] != game.Empty {
-		return nil
+	if remote != nil {
+		return remoteMove(g, &game.Point{Row: cursorRow, Col: cursorCol})
 	}
-	// Ko rule: forbid move at koPoint
-	if koPoint != nil && int8(cursorRow) == koPoint.Row && int8(cursorCol) == koPoint.Col {
-		if v, err := g.View("prompt"); err == nil && v != nil {
-			v.Clear()
-			fmt.Fprint(v, "Illegal move! Ko rule.")
-			go func() {
-				time.Sleep(1 * time.Second)
-				g.Update(func(g *gocui.Gui) error {
-					if v, err := g.View("prompt"); err == nil && v != nil {
-						v.Clear()
-						printMovePrompt(v)
-					}
-					return nil
-				})
-			}()
-		}
+	if gameOver || outOfTime(g) {
 		return nil
 	}
-
 	stone := game.Black
 	if currentPlayer == 2 {
 		stone = game.White
 	}
-	// Track previous board for Ko rule (simple implementation: store last board)
-	if prevBoard == nil {
-		prev := game.Board{}
-		copy(prev[:], gui.Grid[:])
-		prevBoard = &prev
-	}
-	// Simulate the move and captures on a copy of the board
-	var nextBoard game.Board
-	copy(nextBoard[:], gui.Grid[:])
-	nextBoard[cursorRow][cursorCol] = stone
-
-	opp := game.Black
-	if stone == game.Black {
-		opp = game.White
-	}
-	captured := []game.Point{}
-	for _, n := range game.Neighbors(game.Point{Row: int8(cursorRow), Col: int8(cursorCol)}) {
-		if nextBoard[n.Row][n.Col] == opp {
-			group, libs := game.Group(nextBoard, n)
-			if len(libs) == 0 {
-				for stonePt := range group {
-					nextBoard[stonePt.Row][stonePt.Col] = game.Empty
-					captured = append(captured, stonePt)
-				}
-			}
-		}
-	}
-
-	// Check for liberties of the placed stone's group (suicide rule)
-	_, libs := game.Group(nextBoard, game.Point{Row: int8(cursorRow), Col: int8(cursorCol)})
-	if len(libs) == 0 {
-		if v, err := g.View("prompt"); err == nil && v != nil {
-			v.Clear()
-			fmt.Fprint(v, "Illegal move! No liberties.")
-			go func() {
-				time.Sleep(1 * time.Second)
-				g.Update(func(g *gocui.Gui) error {
-					if v, err := g.View("prompt"); err == nil && v != nil {
-						v.Clear()
-						printMovePrompt(v)
-					}
-					return nil
-				})
-			}()
-		}
+	state := game.State{Board: gui.Grid, ToMove: stone, Ko: koPoint}
+	next, _, err := game.Play(state, &game.Point{Row: cursorRow, Col: cursorCol})
+	switch {
+	case errors.Is(err, game.ErrOccupied):
+		return nil
+	case errors.Is(err, game.ErrKo):
+		showMessage(g, "Illegal move! Ko rule.")
+		return nil
+	case errors.Is(err, game.ErrSuicide):
+		showMessage(g, "Illegal move! No liberties.")
+		return nil
+	case err != nil:
+		showMessage(g, "Illegal move! Try again.")
 		return nil
 	}
 
-	// Ko rule and legality check: resulting board must not match prevBoard
-	if prevBoard != nil {
-		same := true
-		for i := int8(0); i < int8(len(nextBoard)); i++ {
-			if nextBoard[i] != (*prevBoard)[i] {
-				same = false
-				break
-			}
-		}
-		if same {
-			if v, err := g.View("prompt"); err == nil && v != nil {
-				v.Clear()
-				fmt.Fprint(v, "Illegal move! Try again.")
-				go func() {
-					time.Sleep(1 * time.Second)
-					g.Update(func(g *gocui.Gui) error {
-						if v, err := g.View("prompt"); err == nil && v != nil {
-							v.Clear()
-							printMovePrompt(v)
-						}
-						return nil
-					})
-				}()
-			}
-			return nil
-		}
-	}
-
-	// Place the stone and update the board
-	gui.Grid[cursorRow][cursorCol] = stone
-	for _, pt := range captured {
-		gui.Grid[pt.Row][pt.Col] = game.Empty
-	}
-
-	// Ko rule: set koPoint if exactly one stone was captured and the group size is 1
-	if len(captured) == 1 {
-		koPoint = &captured[0]
-	} else {
-		koPoint = nil
-	}
-
-	// Update prevBoard for next move (for Ko rule)
-	copy(prevBoard[:], nextBoard[:])
+	// Place the stone, remove the captures and remember the ko point
+	gui.Grid, koPoint = next.Board, next.Ko
 
 	passCount = 0 // Reset pass count on a move
+	pressClock()
 
 	currentPlayer = 3 - currentPlayer // Switch player only after a legal move
+	markLadders()
 
 	// If engine is enabled and it's the engine's turn, make engine move
 	if engineEnabled && !gameOver && currentPlayer == 2 {
@@ -249,20 +218,45 @@ func placeStone(g *gocui.Gui, v *gocui.View) error {
 	return nil
 }
 
+// showMessage shows msg in the prompt for a second.
+func showMessage(g *gocui.Gui, msg string) {
+	v, err := g.View("prompt")
+	if err != nil || v == nil {
+		return
+	}
+	v.Clear()
+	fmt.Fprint(v, msg)
+	go func() {
+		time.Sleep(1 * time.Second)
+		g.Update(func(g *gocui.Gui) error {
+			if v, err := g.View("prompt"); err == nil && v != nil {
+				v.Clear()
+				printMovePrompt(v)
+			}
+			return nil
+		})
+	}()
+}
+
 func passTurn(g *gocui.Gui, v *gocui.View) error {
-	// Update prevBoard to current board for Ko rule
-	if prevBoard == nil {
-		prev := game.Board{}
-		copy(prev[:], gui.Grid[:])
-		prevBoard = &prev
-	} else {
-		copy(prevBoard[:], gui.Grid[:])
+	if training != nil {
+		return trainingMove(g, nil)
+	}
+	if remote != nil {
+		return remoteMove(g, nil)
+	}
+	if gameOver || outOfTime(g) {
+		return nil
 	}
 	koPoint = nil // Passing clears Ko
 
 	passCount++
+	pressClock()
 	if passCount >= 2 {
 		gameOver = true
+		if gameClock != nil {
+			gameClock.Stop()
+		}
 		if v, err := g.View("prompt"); err == nil {
 			v.Clear()
 			blackScore, whiteScore := game.CalculateScore(gui.Grid)
@@ -278,22 +272,10 @@ func passTurn(g *gocui.Gui, v *gocui.View) error {
 	}
 
 	// Show "Turn passed." message briefly
-	if v, err := g.View("prompt"); err == nil {
-		v.Clear()
-		fmt.Fprint(v, "Turn passed.")
-		go func() {
-			time.Sleep(1 * time.Second)
-			g.Update(func(_ *gocui.Gui) error {
-				if v, err := g.View("prompt"); err == nil && v != nil {
-					v.Clear()
-					printMovePrompt(v)
-				}
-				return nil
-			})
-		}()
-	}
+	showMessage(g, "Turn passed.")
 
 	currentPlayer = 3 - currentPlayer
+	markLadders()
 	return nil
 }
 
@@ -303,71 +285,135 @@ func quit(g *gocui.Gui, v *gocui.View) error {
 
 func engineMove(g *gocui.Gui) {
 	// Use the engine interface to get a move for White
-	if selectedEngine == nil {
+	if selectedEngine == nil || gameOver || outOfTime(g) {
 		return
 	}
-	move := selectedEngine.Move(gui.Grid, game.White, koPoint)
-	if move != nil {
-		// Do not move the cursor for the engine, just place the stone directly
-		row, col := move.Row, move.Col
-		gui.Grid[row][col] = game.White
-
-		// Simulate captures and ko logic as in placeStone
-		var nextBoard game.Board
-		copy(nextBoard[:], gui.Grid[:])
-		opp := game.Black
-		captured := []game.Point{}
-		for _, n := range game.Neighbors(game.Point{Row: row, Col: col}) {
-			if nextBoard[n.Row][n.Col] == opp {
-				group, libs := game.Group(nextBoard, n)
-				if len(libs) == 0 {
-					for stonePt := range group {
-						nextBoard[stonePt.Row][stonePt.Col] = game.Empty
-						captured = append(captured, stonePt)
-					}
-				}
-			}
-		}
-		for _, pt := range captured {
-			gui.Grid[pt.Row][pt.Col] = game.Empty
-		}
-		// Ko rule: set koPoint if exactly one stone was captured and the group size is 1
-		if len(captured) == 1 {
-			koPoint = &captured[0]
-		} else {
-			koPoint = nil
-		}
-		// Update prevBoard for next move (for Ko rule)
-		if prevBoard == nil {
-			prev := game.Board{}
-			copy(prev[:], gui.Grid[:])
-			prevBoard = &prev
+	if t, ok := selectedEngine.(engine.TimeAware); ok && gameClock != nil {
+		remaining, stones := gameClock.State(game.White).TimeLeft()
+		if ts, ok := t.(engine.TimeSettingsAware); ok {
+			ts.TimeSettings(gameClock.TimeControl())
 		}
-		copy(prevBoard[:], gui.Grid[:])
-		passCount = 0
-		currentPlayer = 1 // Switch back to player
-	} else {
+		t.TimeLeft(game.White, remaining, stones)
+	}
+	move := selectedEngine.Move(gui.Grid, game.White, koPoint)
+	state := game.State{Board: gui.Grid, ToMove: game.White, Ko: koPoint}
+	// Do not move the cursor for the engine, just place the stone directly
+	next, _, err := game.Play(state, move)
+	if move == nil || err != nil {
 		_ = passTurn(g, nil)
+		return
 	}
+	gui.Grid, koPoint = next.Board, next.Ko
+	passCount = 0
+	pressClock()
+	currentPlayer = 1 // Switch back to player
+	markLadders()
+}
+
+// markLadders marks the chains that the player to move captures in a
+// ladder. Reading every ladder is too slow for each redraw, so it runs
+// after every change of the position instead.
+func markLadders() {
+	toMove := game.Black
+	if currentPlayer == 2 {
+		toMove = game.White
+	}
+	gui.Marked = game.Ladders(gui.Grid, toMove)
 }
 
 func main() {
-	g, err := gocui.NewGui(gocui.OutputNormal)
-	if err != nil {
-		log.Panicln(err)
+	weightsPath := flag.String("weights", "", "evaluation weights file for the engine (see cmd/tune)")
+	evaluation := flag.String("eval", "classic", "engine evaluation: classic or territory")
+	patternsPath := flag.String("patterns", "", "pattern table for the engine's move ordering (see cmd/patterns)")
+	bookPath := flag.String("book", "", "opening book for the engine (see cmd/book)")
+	problems := flag.String("tsumego", "", "glob of SGF life-and-death problems to train with instead of playing a game")
+	hostAddr := flag.String("host", "", "host a game against another player on this address, e.g. :4242")
+	joinAddr := flag.String("join", "", "join the game hosted at this address, e.g. localhost:4242")
+	name := flag.String("name", "", "your name in networked games")
+	color := flag.String("color", "any", "preferred colour in networked games: black, white or any")
+	timeControl := flag.String("time", "", "time control of local games, e.g. 10m, fischer:5m+10s, byoyomi:10m+5x30s or canadian:10m+25/5m")
+	flag.StringVar(&recordPath, "record", "", "SGF file to save networked games to, with the chat as comments")
+	flag.Parse()
+
+	if *timeControl != "" {
+		if *hostAddr != "" || *joinAddr != "" || *problems != "" {
+			log.Panicln("Time control is only available in local games")
+		}
+		tc, err := clock.Parse(*timeControl)
+		if err != nil {
+			log.Panicln("Invalid time control:", err)
+		}
+		gameClock = clock.New(tc, nil)
 	}
-	cfg, err := loadConfig("config.json")
-	if err != nil {
-		log.Panicln("Failed to load config:", err)
+	if *hostAddr != "" || *joinAddr != "" {
+		s, err := connect(*hostAddr, *joinAddr, *name, *color)
+		if err != nil {
+			log.Panicln("Failed to connect:", err)
+		}
+		remote = s
+		defer remote.Close()
 	}
-	keybindings = cfg.Keybindings
 
 	// selectedEngine = &engine.RandomEngine{}
 	selectedEngine = engine.NewAlphaBetaEngine()
-	engineEnabled = true // Enable engine by default
+	if *weightsPath != "" {
+		weights, err := engine.LoadWeights(*weightsPath)
+		if err != nil {
+			log.Panicln("Failed to load weights:", err)
+		}
+		selectedEngine = engine.NewAlphaBetaEngineWithWeights(weights)
+	}
+	switch *evaluation {
+	case "classic":
+	case "territory":
+		if *weightsPath != "" {
+			log.Panicln("The territory evaluation does not use -weights")
+		}
+		selectedEngine = engine.NewAlphaBetaEngineWithEvaluator(engine.TerritoryEvaluator(engine.DefaultTerritoryWeights()))
+	default:
+		log.Panicln("Unknown evaluation:", *evaluation)
+	}
+	if *patternsPath != "" {
+		t, err := pattern.LoadTable(*patternsPath)
+		if err != nil {
+			log.Panicln("Failed to load patterns:", err)
+		}
+		selectedEngine.(*engine.AlphaBetaEngine).SetPatterns(t)
+	}
+	if *bookPath != "" {
+		b, err := book.Load(*bookPath)
+		if err != nil {
+			log.Panicln("Failed to load book:", err)
+		}
+		selectedEngine = book.NewEngine(b, selectedEngine)
+	}
+	engineEnabled = remote == nil // Enable engine by default unless playing over the network
+	if *problems != "" {
+		paths, err := filepath.Glob(*problems)
+		if err != nil {
+			log.Panicln("Invalid problem pattern:", err)
+		}
+		loaded, err := tsumego.LoadTraining(paths)
+		if err != nil {
+			log.Panicln("Failed to load problems:", err)
+		}
+		if len(loaded) == 0 {
+			log.Panicln("No problems match", *problems)
+		}
+		training = tsumego.NewSession(loaded)
+		engineEnabled = false
+		startProblem()
+	}
 
-	defer g.Close()
+	cfg, err := loadConfig("config.json")
+	if err != nil {
+		log.Panicln("Failed to load config:", err)
+	}
+	keybindings = cfg.Keybindings
 
+	// Everything that can fail on bad flags or files is done above, since
+	// a panic after NewGui leaves the terminal in raw mode.
+	g, err := gocui.NewGui(gocui.OutputNormal)
 	if err != nil {
 		log.Panicln(err)
 	}
@@ -397,53 +443,107 @@ func main() {
 	}
 
 	// Lowercase: move regardless of occupation
-	if err := g.SetKeybinding("", moveLeftKey, gocui.ModNone, moveCursor(0, -1, false)); err != nil {
+	if err := g.SetKeybinding("board", moveLeftKey, gocui.ModNone, moveCursor(0, -1, false)); err != nil {
 		log.Panicln(err)
 	}
-	if err := g.SetKeybinding("", moveRightKey, gocui.ModNone, moveCursor(0, 1, false)); err != nil {
+	if err := g.SetKeybinding("board", moveRightKey, gocui.ModNone, moveCursor(0, 1, false)); err != nil {
 		log.Panicln(err)
 	}
-	if err := g.SetKeybinding("", moveUpKey, gocui.ModNone, moveCursor(-1, 0, false)); err != nil {
+	if err := g.SetKeybinding("board", moveUpKey, gocui.ModNone, moveCursor(-1, 0, false)); err != nil {
 		log.Panicln(err)
 	}
-	if err := g.SetKeybinding("", moveDownKey, gocui.ModNone, moveCursor(1, 0, false)); err != nil {
+	if err := g.SetKeybinding("board", moveDownKey, gocui.ModNone, moveCursor(1, 0, false)); err != nil {
 		log.Panicln(err)
 	}
 	// Uppercase: jump over occupied intersections (Shift+key)
-	if err := g.SetKeybinding("", rune(unicode.ToUpper(moveLeftKey)), gocui.ModNone, moveCursor(0, -1, true)); err != nil {
+	if err := g.SetKeybinding("board", rune(unicode.ToUpper(moveLeftKey)), gocui.ModNone, moveCursor(0, -1, true)); err != nil {
 		log.Panicln(err)
 	}
-	if err := g.SetKeybinding("", rune(unicode.ToUpper(moveRightKey)), gocui.ModNone, moveCursor(0, 1, true)); err != nil {
+	if err := g.SetKeybinding("board", rune(unicode.ToUpper(moveRightKey)), gocui.ModNone, moveCursor(0, 1, true)); err != nil {
 		log.Panicln(err)
 	}
-	if err := g.SetKeybinding("", rune(unicode.ToUpper(moveUpKey)), gocui.ModNone, moveCursor(-1, 0, true)); err != nil {
+	if err := g.SetKeybinding("board", rune(unicode.ToUpper(moveUpKey)), gocui.ModNone, moveCursor(-1, 0, true)); err != nil {
 		log.Panicln(err)
 	}
-	if err := g.SetKeybinding("", rune(unicode.ToUpper(moveDownKey)), gocui.ModNone, moveCursor(1, 0, true)); err != nil {
+	if err := g.SetKeybinding("board", rune(unicode.ToUpper(moveDownKey)), gocui.ModNone, moveCursor(1, 0, true)); err != nil {
 		log.Panicln(err)
 	}
 
-	if err := g.SetKeybinding("", placeKey, gocui.ModNone, placeStone); err != nil {
+	if err := g.SetKeybinding("board", placeKey, gocui.ModNone, placeStone); err != nil {
 		log.Panicln(err)
 	}
-	if err := g.SetKeybinding("", passKey, gocui.ModNone, passTurn); err != nil {
+	if err := g.SetKeybinding("board", passKey, gocui.ModNone, passTurn); err != nil {
 		log.Panicln(err)
 	}
-	if err := g.SetKeybinding("", quitKey, gocui.ModNone, quit); err != nil {
+	if err := g.SetKeybinding("board", quitKey, gocui.ModNone, quit); err != nil {
 		log.Panicln(err)
 	}
 	// Engine toggle: enable for second player
 	enableEngineKey := []rune(keybindings["enableEngine"])[0]
-	if err := g.SetKeybinding("", enableEngineKey, gocui.ModNone, toggleEngine); err != nil {
+	if err := g.SetKeybinding("board", enableEngineKey, gocui.ModNone, toggleEngine); err != nil {
+		log.Panicln(err)
+	}
+	// Next tsumego problem; older config files lack the binding
+	nextProblemKey := 'n'
+	if key, ok := keybindings["nextProblem"]; ok && key != "" {
+		nextProblemKey = []rune(key)[0]
+	}
+	if err := g.SetKeybinding("board", nextProblemKey, gocui.ModNone, nextProblem); err != nil {
+		log.Panicln(err)
+	}
+
+	// Networked games; older config files lack these bindings
+	remoteKeys := []struct {
+		name, key string
+		handler   func(*gocui.Gui, *gocui.View) error
+	}{
+		{"resign", "r", resign},
+		{"chat", "c", startChat},
+		{"undo", "u", requestUndo},
+		{"acceptUndo", "y", replyUndo(true)},
+		{"declineUndo", "n", replyUndo(false)},
+		{"save", "w", saveRecord},
+	}
+	for _, k := range remoteKeys {
+		if err := g.SetKeybinding("board", []rune(keyLabel(k.name, k.key))[0], gocui.ModNone, k.handler); err != nil {
+			log.Panicln(err)
+		}
+	}
+	// The chat input sends on Enter and returns to the board on Escape
+	if err := g.SetKeybinding("chatInput", gocui.KeyEnter, gocui.ModNone, sendChat); err != nil {
+		log.Panicln(err)
+	}
+	if err := g.SetKeybinding("chatInput", gocui.KeyEsc, gocui.ModNone, endChat); err != nil {
 		log.Panicln(err)
 	}
+	g.InputEsc = true
+
+	if remote != nil {
+		g.Update(func(g *gocui.Gui) error {
+			syncRemote(g)
+			return nil
+		})
+		go listenRemote(g)
+	}
+	if gameClock != nil {
+		gameClock.Start(game.Black)
+		go tickClock(g)
+	}
 
 	if err := g.MainLoop(); err != nil && err != gocui.ErrQuit {
 		log.Panicln(err)
 	}
+	if remote != nil && recordPath != "" {
+		if err := writeRecord(recordPath); err != nil {
+			log.Println("Failed to save the game:", err)
+		}
+	}
 }
 
 func toggleEngine(g *gocui.Gui, v *gocui.View) error {
+	if training != nil || remote != nil {
+		return nil
+	}
 	engineEnabled = !engineEnabled
 	if v, err := g.View("prompt"); err == nil && v != nil {
 		v.Clear()
@@ -461,3 +561,409 @@ func toggleEngine(g *gocui.Gui, v *gocui.View) error {
 	}
 	return nil
 }
+
+// startProblem sets up the board for the current training problem.
+func startProblem() {
+	attempt := training.Attempt
+	gui.Grid = attempt.Board
+	currentPlayer = 1
+	if attempt.Player == game.White {
+		currentPlayer = 2
+	}
+	koPoint = nil
+	passCount, gameOver = 0, false
+	markLadders()
+}
+
+func printTrainingPrompt(v *gocui.View) {
+	attempt := training.Attempt
+	status := fmt.Sprintf("Problem %d/%d (%s), solved %d, failed %d.", training.Index+1, len(training.Problems), training.Current().Name, training.Solved, training.Failed)
+	switch attempt.State {
+	case tsumego.Solved:
+		fmt.Fprintf(v, "Correct! %s %s Press %s for the next problem.", attempt.Comment, status, nextProblemLabel())
+	case tsumego.Failed:
+		fmt.Fprintf(v, "Wrong. %s %s Press %s for the next problem.", attempt.Comment, status, nextProblemLabel())
+	default:
+		player := "Black"
+		if attempt.Player == game.White {
+			player = "White"
+		}
+		fmt.Fprintf(v, "%s to play. %s %s to place stone, %s to pass, %s to skip, %s to quit", player, status, keybindings["placeStone"], keybindings["passTurn"], nextProblemLabel(), keybindings["quit"])
+	}
+}
+
+func nextProblemLabel() string {
+	if key, ok := keybindings["nextProblem"]; ok && key != "" {
+		return key
+	}
+	return "n"
+}
+
+// trainingMove plays p (nil for a pass) in the training problem and shows
+// the reply and the result.
+func trainingMove(g *gocui.Gui, p *game.Point) error {
+	if training.Attempt.State != tsumego.Playing {
+		return nil
+	}
+	_, err := training.Play(p)
+	v, viewErr := g.View("prompt")
+	if err == tsumego.ErrIllegalMove {
+		if viewErr == nil {
+			v.Clear()
+			fmt.Fprint(v, "Illegal move! Try again.")
+		}
+		return nil
+	}
+	if err != nil {
+		// A broken problem file ends the attempt rather than the program.
+		training.Attempt.State = tsumego.Failed
+		training.Attempt.Comment = fmt.Sprintf("The problem cannot be played on: %v.", err)
+		training.Failed++
+	}
+	gui.Grid = training.Attempt.Board
+	markLadders()
+	if viewErr == nil {
+		v.Clear()
+		printMovePrompt(v)
+	}
+	return nil
+}
+
+func nextProblem(g *gocui.Gui, v *gocui.View) error {
+	if training == nil {
+		return nil
+	}
+	training.Next()
+	startProblem()
+	if v, err := g.View("prompt"); err == nil {
+		v.Clear()
+		printMovePrompt(v)
+	}
+	return nil
+}
+
+// connect hosts a game on hostAddr or joins the game at joinAddr.
+func connect(hostAddr, joinAddr, name, color string) (*netplay.Session, error) {
+	opts := netplay.Options{Name: name}
+	switch color {
+	case "black":
+		opts.Color = game.Black
+	case "white":
+		opts.Color = game.White
+	case "", "any":
+	default:
+		return nil, fmt.Errorf("unknown colour %q", color)
+	}
+	if joinAddr != "" {
+		return netplay.Dial(joinAddr, opts)
+	}
+	h, err := netplay.Listen(hostAddr)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Waiting for an opponent on %s...\n", h.Addr())
+	s, err := h.Accept(opts)
+	if err != nil {
+		h.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// listenRemote applies the opponent's moves and connection changes in the
+// GUI goroutine.
+func listenRemote(g *gocui.Gui) {
+	for e := range remote.Events() {
+		g.Update(func(g *gocui.Gui) error {
+			syncRemote(g)
+			switch e.Type {
+			case netplay.EventMove:
+				if e.Point == nil {
+					showMessage(g, "Opponent passed.")
+				}
+			case netplay.EventDisconnected:
+				showMessage(g, "Connection lost, reconnecting...")
+			case netplay.EventUndoRequest:
+				undoAsked = true
+				syncRemote(g)
+			case netplay.EventUndoReply:
+				if e.Accept {
+					showMessage(g, "Undo accepted.")
+				} else {
+					showMessage(g, "Undo declined.")
+				}
+			case netplay.EventReconnected:
+				undoAsked = false
+				showMessage(g, "Reconnected.")
+			case netplay.EventLeft:
+				showMessage(g, "Your opponent left the game.")
+			case netplay.EventError:
+				showMessage(g, e.Err.Error())
+			}
+			return nil
+		})
+	}
+}
+
+// syncRemote shows the position of the networked game.
+func syncRemote(g *gocui.Gui) {
+	s := remote.State()
+	gui.Grid, koPoint = s.Board, s.Ko
+	currentPlayer = 1
+	if s.ToMove == game.White {
+		currentPlayer = 2
+	}
+	markLadders()
+	gameOver = remote.Result() != ""
+	if v, err := g.View("prompt"); err == nil && v != nil {
+		v.Clear()
+		printMovePrompt(v)
+	}
+}
+
+// remoteMove plays p (nil for a pass) in the networked game.
+func remoteMove(g *gocui.Gui, p *game.Point) error {
+	err := remote.Play(p)
+	switch {
+	case err == nil:
+		syncRemote(g)
+	case errors.Is(err, netplay.ErrNotYourTurn):
+		showMessage(g, "Wait for your opponent's move.")
+	case errors.Is(err, netplay.ErrDisconnected):
+		showMessage(g, "Not connected to your opponent.")
+	case errors.Is(err, game.ErrKo):
+		showMessage(g, "Illegal move! Ko rule.")
+	case errors.Is(err, game.ErrSuicide):
+		showMessage(g, "Illegal move! No liberties.")
+	case errors.Is(err, game.ErrOccupied), errors.Is(err, netplay.ErrGameOver):
+	default:
+		showMessage(g, "Illegal move! Try again.")
+	}
+	return nil
+}
+
+func resign(g *gocui.Gui, v *gocui.View) error {
+	if remote == nil {
+		return nil
+	}
+	if err := remote.Resign(); err == nil {
+		syncRemote(g)
+	}
+	return nil
+}
+
+func printRemotePrompt(v *gocui.View) {
+	colour, opponent := "Black", remote.Opponent
+	if remote.Local == game.White {
+		colour = "White"
+	}
+	if opponent == "" {
+		opponent = "your opponent"
+	}
+	if result := remote.Result(); result != "" {
+		blackScore, whiteScore := game.CalculateScore(gui.Grid)
+		fmt.Fprintf(v, "Game Over! Black: %d, White: %d. Result: %s. %s to quit", blackScore, whiteScore, result, keybindings["quit"])
+		return
+	}
+	turn := "your move"
+	if remote.State().ToMove != remote.Local {
+		turn = "waiting for " + opponent
+	}
+	if undoAsked {
+		fmt.Fprintf(v, "%s asks to undo. %s to accept, %s to decline", opponent, keyLabel("acceptUndo", "y"), keyLabel("declineUndo", "n"))
+		return
+	}
+	fmt.Fprintf(v, "%s against %s, %s. %s to place stone, %s to pass, %s to resign, %s to ask for an undo, %s to chat, %s to quit", colour, opponent, turn, keybindings["placeStone"], keybindings["passTurn"], keyLabel("resign", "r"), keyLabel("undo", "u"), keyLabel("chat", "c"), keybindings["quit"])
+}
+
+// layoutClock shows both clocks in a line at the top y between the board and
+// the prompt.
+func layoutClock(g *gocui.Gui, y, x1 int) error {
+	v, err := g.SetView("clock", 0, y, x1, y+2)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Title = gameClock.TimeControl().String()
+	}
+	v.Clear()
+	for _, color := range []game.FieldState{game.Black, game.White} {
+		marker := "  "
+		if gameClock.Running() == color {
+			marker = "◀ "
+		}
+		fmt.Fprintf(v, " %s %s %s ", colorLabel(color), gameClock.State(color), marker)
+	}
+	return nil
+}
+
+// tickClock redraws the clocks and ends the game when a flag falls.
+func tickClock(g *gocui.Gui) {
+	for range time.Tick(200 * time.Millisecond) {
+		g.Update(func(g *gocui.Gui) error {
+			if !gameOver {
+				outOfTime(g)
+			}
+			return nil
+		})
+	}
+}
+
+// outOfTime reports whether a player ran out of time, ending the game.
+func outOfTime(g *gocui.Gui) bool {
+	if gameClock == nil || gameClock.Flagged() == game.Empty {
+		return false
+	}
+	if !gameOver {
+		gameOver = true
+		gameClock.Stop()
+		if v, err := g.View("prompt"); err == nil {
+			v.Clear()
+			printMovePrompt(v)
+		}
+	}
+	return true
+}
+
+// pressClock ends the turn of the player who just moved or passed.
+func pressClock() {
+	if gameClock != nil {
+		gameClock.Press()
+	}
+}
+
+func colorLabel(color game.FieldState) string {
+	if color == game.White {
+		return "White"
+	}
+	return "Black"
+}
+
+// keyLabel returns the key bound to name, or def if the config lacks it.
+func keyLabel(name, def string) string {
+	if key, ok := keybindings[name]; ok && key != "" {
+		return key
+	}
+	return def
+}
+
+// boardWidth is the width of the board view next to the chat pane.
+const boardWidth = 56
+
+// layoutChat places the chat log and input between x0 and x1.
+func layoutChat(g *gocui.Gui, x0, x1, height int) error {
+	if v, err := g.SetView("chat", x0, 0, x1, height-3); err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Title = "Chat"
+		v.Wrap = true
+		v.Autoscroll = true
+	}
+	if v, err := g.SetView("chatInput", x0, height-2, x1, height); err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Editable = true
+	}
+	if v, err := g.View("chat"); err == nil {
+		v.Clear()
+		for _, line := range remote.Log() {
+			fmt.Fprintln(v, line)
+		}
+	}
+	return nil
+}
+
+func startChat(g *gocui.Gui, v *gocui.View) error {
+	if remote == nil {
+		return nil
+	}
+	if _, err := g.SetCurrentView("chatInput"); err != nil {
+		return nil // no room for the chat pane
+	}
+	g.Cursor = true
+	return nil
+}
+
+// sendChat sends the typed message and returns to the board.
+func sendChat(g *gocui.Gui, v *gocui.View) error {
+	text := strings.TrimSpace(v.Buffer())
+	if err := endChat(g, v); err != nil {
+		return err
+	}
+	if text == "" {
+		return nil
+	}
+	if err := remote.Chat(text); err != nil {
+		showMessage(g, "Not connected to your opponent.")
+	}
+	return nil
+}
+
+func endChat(g *gocui.Gui, v *gocui.View) error {
+	v.Clear()
+	v.SetCursor(0, 0)
+	g.Cursor = false
+	_, err := g.SetCurrentView("board")
+	return err
+}
+
+func requestUndo(g *gocui.Gui, v *gocui.View) error {
+	if remote == nil {
+		return nil
+	}
+	switch err := remote.RequestUndo(); {
+	case err == nil:
+		showMessage(g, "Undo requested.")
+	case errors.Is(err, netplay.ErrNothingToUndo):
+		showMessage(g, "Nothing to undo.")
+	case errors.Is(err, netplay.ErrDisconnected):
+		showMessage(g, "Not connected to your opponent.")
+	}
+	return nil
+}
+
+// replyUndo answers the opponent's undo request.
+func replyUndo(accept bool) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if remote == nil || !undoAsked {
+			return nil
+		}
+		undoAsked = false
+		if err := remote.ReplyUndo(accept); err != nil {
+			showMessage(g, "Not connected to your opponent.")
+		}
+		syncRemote(g)
+		return nil
+	}
+}
+
+func saveRecord(g *gocui.Gui, v *gocui.View) error {
+	if remote == nil {
+		return nil
+	}
+	if recordPath == "" {
+		showMessage(g, "Start with -record to save the game.")
+		return nil
+	}
+	if err := writeRecord(recordPath); err != nil {
+		showMessage(g, "Failed to save the game: "+err.Error())
+		return nil
+	}
+	showMessage(g, "Game saved to "+recordPath+".")
+	return nil
+}
+
+// writeRecord writes the networked game with its chat to path.
+func writeRecord(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := sgf.Write(f, remote.Record().Tree()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
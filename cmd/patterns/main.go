@@ -0,0 +1,35 @@
+// Command patterns learns 3x3 pattern priors from SGF game collections.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/RubikNube/GoInGo/pkg/pattern"
+)
+
+func main() {
+	data := flag.String("data", "", "glob of SGF files to learn from")
+	out := flag.String("out", "patterns.json", "pattern table to write")
+	flag.Parse()
+	if *data == "" {
+		log.Fatal("-data is required")
+	}
+
+	paths, err := filepath.Glob(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	l := pattern.NewLearner()
+	games, err := l.Import(paths)
+	if err != nil {
+		log.Fatal(err)
+	}
+	t := l.Table()
+	if err := t.Save(*out); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("learned %d patterns from %d games into %s\n", len(t.Weights), games, *out)
+}
@@ -0,0 +1,117 @@
+// Command perft counts the legal move sequences from a position to a given
+// depth, to check the rules implementations against each other and to
+// measure their speed.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/perft"
+	"github.com/RubikNube/GoInGo/pkg/position"
+	"github.com/RubikNube/GoInGo/pkg/sgf"
+)
+
+func main() {
+	depth := flag.Int("depth", 3, "maximum number of moves")
+	sgfPath := flag.String("sgf", "", "start from the end of this SGF file's main line (default: empty board)")
+	superko := flag.Bool("superko", false, "forbid any repetition of an earlier position instead of only simple ko")
+	impl := flag.String("impl", "fast", "implementation to count with: fast, simple or both")
+	divide := flag.Bool("divide", false, "print the count below every first move at the maximum depth")
+	flag.Parse()
+
+	state := perft.State{ToMove: game.Black}
+	if *sgfPath != "" {
+		var err error
+		if state, err = load(*sgfPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+	rules := perft.Rules{Superko: *superko}
+
+	for d := 1; d <= *depth; d++ {
+		switch *impl {
+		case "fast":
+			report(d, "fast", func() uint64 { return perft.Fast(state, d, rules) })
+		case "simple":
+			report(d, "simple", func() uint64 { return perft.Simple(state, d, rules) })
+		case "both":
+			a := report(d, "fast", func() uint64 { return perft.Fast(state, d, rules) })
+			b := report(d, "simple", func() uint64 { return perft.Simple(state, d, rules) })
+			if a != b {
+				fmt.Printf("depth %d: MISMATCH\n", d)
+				os.Exit(1)
+			}
+		default:
+			log.Fatalf("unknown implementation %q", *impl)
+		}
+	}
+	if *divide {
+		for _, m := range perft.Divide(state, *depth, rules) {
+			fmt.Printf("%s: %d\n", label(m.Point), m.Nodes)
+		}
+	}
+}
+
+func report(depth int, name string, count func() uint64) uint64 {
+	start := time.Now()
+	n := count()
+	elapsed := time.Since(start)
+	fmt.Printf("depth %d (%s): %d sequences in %v (%.0f/s)\n", depth, name, n, elapsed.Round(time.Millisecond), float64(n)/elapsed.Seconds())
+	return n
+}
+
+func label(p *game.Point) string {
+	if p == nil {
+		return "pass"
+	}
+	return sgf.EncodePoint(p)
+}
+
+// load returns the position at the end of the main line of an SGF file.
+func load(path string) (perft.State, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return perft.State{}, err
+	}
+	defer f.Close()
+	roots, err := sgf.Parse(f)
+	if err != nil {
+		return perft.State{}, err
+	}
+	if len(roots) == 0 {
+		return perft.State{}, fmt.Errorf("%s: no game tree", path)
+	}
+	g, err := sgf.MainLine(roots[0])
+	if err != nil {
+		return perft.State{}, err
+	}
+	var board game.Board
+	for _, s := range g.SetupBlack {
+		board[s.Row][s.Col] = game.Black
+	}
+	for _, s := range g.SetupWhite {
+		board[s.Row][s.Col] = game.White
+	}
+	pos := position.FromBoard(board)
+	toMove := game.Black
+	if pl, ok := roots[0].Get("PL"); ok && pl == "W" {
+		toMove = game.White
+	}
+	for _, m := range g.Moves {
+		if m.Point != nil {
+			if _, err := pos.Play(*m.Point, m.Color); err != nil {
+				return perft.State{}, fmt.Errorf("%s: move %s: %w", path, sgf.EncodePoint(m.Point), err)
+			}
+		}
+		toMove = game.White
+		if m.Color == game.White {
+			toMove = game.Black
+		}
+	}
+	return perft.State{Board: pos.Board(), ToMove: toMove}, nil
+}
@@ -0,0 +1,45 @@
+// Command selfplay lets two engines play many randomised games and writes
+// them as SGF files together with a JSONL index.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/RubikNube/GoInGo/pkg/league"
+	"github.com/RubikNube/GoInGo/pkg/selfplay"
+)
+
+func main() {
+	cfg := selfplay.DefaultConfig()
+	out := flag.String("out", "selfplay", "output directory for SGF files and index.jsonl")
+	engineA := flag.String("a", "alphabeta", "first engine")
+	engineB := flag.String("b", "alphabeta", "second engine")
+	flag.IntVar(&cfg.Games, "games", cfg.Games, "number of games")
+	flag.IntVar(&cfg.MaxMoves, "maxmoves", cfg.MaxMoves, "maximum moves per game")
+	flag.Float64Var(&cfg.Komi, "komi", cfg.Komi, "komi for White")
+	flag.IntVar(&cfg.RandomOpeningMoves, "opening", cfg.RandomOpeningMoves, "number of random opening moves")
+	flag.Float64Var(&cfg.Temperature, "temperature", cfg.Temperature, "softmax temperature in evaluation units (0 plays the best move)")
+	flag.IntVar(&cfg.TemperatureMoves, "temperature-moves", cfg.TemperatureMoves, "number of moves sampled with temperature (0 for all)")
+	flag.Int64Var(&cfg.Seed, "seed", cfg.Seed, "random seed")
+	flag.Parse()
+
+	a, ok := league.Lookup(league.Builtin(), *engineA)
+	if !ok {
+		log.Fatalf("unknown engine %q", *engineA)
+	}
+	b, ok := league.Lookup(league.Builtin(), *engineB)
+	if !ok {
+		log.Fatalf("unknown engine %q", *engineB)
+	}
+	gen := selfplay.NewGenerator(cfg)
+	err := gen.Run(
+		selfplay.Player{Name: a.Name, New: a.New},
+		selfplay.Player{Name: b.Name, New: b.New},
+		*out, os.Stderr,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
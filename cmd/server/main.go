@@ -0,0 +1,19 @@
+// Command server runs the JSON game server of pkg/server with games kept
+// in memory.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/RubikNube/GoInGo/pkg/server"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	flag.Parse()
+
+	log.Printf("listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, server.New(server.NewMemoryStore())))
+}
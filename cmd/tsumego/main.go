@@ -0,0 +1,76 @@
+// Command tsumego solves a life-and-death problem given as SGF setup stones
+// and checks the first move of the file's main line against the solution.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/RubikNube/GoInGo/pkg/sgf"
+	"github.com/RubikNube/GoInGo/pkg/tsumego"
+)
+
+func main() {
+	target := flag.String("target", "", "SGF coordinate of a stone of the group in question, e.g. cb")
+	margin := flag.Int("margin", 1, "distance from the group within which moves are considered")
+	maxNodes := flag.Int("nodes", 1000000, "search budget in positions")
+	out := flag.String("out", "", "write the problem with the solution tree to this SGF file")
+	check := flag.Bool("check", false, "exit with status 1 unless the main line starts with a winning move")
+	flag.Parse()
+	if flag.NArg() != 1 || *target == "" {
+		fmt.Fprintln(os.Stderr, "usage: tsumego -target cb [flags] problem.sgf")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	f, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	roots, err := sgf.Parse(f)
+	f.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(roots) == 0 {
+		log.Fatal("no game tree in ", flag.Arg(0))
+	}
+	p, err := tsumego.FromSGF(roots[0])
+	if err != nil {
+		log.Fatal(err)
+	}
+	t, err := sgf.DecodePoint(*target)
+	if err != nil || t == nil {
+		log.Fatalf("invalid target %q", *target)
+	}
+	p.Target = *t
+	p.Region = tsumego.RegionAround(p.Board, p.Target, *margin)
+
+	r, err := tsumego.Solve(p, *maxNodes)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("status: %s (%d nodes)\n", r.Status, r.Nodes)
+	if r.Move != nil {
+		fmt.Printf("best move: %s\n", sgf.EncodePoint(r.Move))
+	}
+	if *out != "" {
+		if err := os.WriteFile(*out, []byte(p.SGF(r).String()), 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *check {
+		g, err := sgf.MainLine(roots[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		if r.Move == nil || len(g.Moves) == 0 || g.Moves[0].Point == nil || *g.Moves[0].Point != *r.Move {
+			fmt.Println("check: main line does not start with a winning move")
+			os.Exit(1)
+		}
+		fmt.Println("check: ok")
+	}
+}
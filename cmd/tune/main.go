@@ -0,0 +1,71 @@
+// Command tune optimises the AlphaBetaEngine evaluation weights and writes
+// them to a JSON file that the engine can load.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/RubikNube/GoInGo/pkg/engine"
+	"github.com/RubikNube/GoInGo/pkg/tuner"
+)
+
+func main() {
+	method := flag.String("method", "texel", "tuning method: texel or spsa")
+	start := flag.String("start", "", "weights file to start from (default: built-in weights)")
+	out := flag.String("out", "weights.json", "output weights file")
+	data := flag.String("data", "selfplay/*.sgf", "glob of SGF files for texel tuning")
+	skip := flag.Int("skip", 10, "opening moves skipped per game for texel tuning")
+	passes := flag.Int("passes", 0, "maximum texel passes (0 until converged)")
+	spsa := tuner.DefaultSPSAOptions()
+	flag.IntVar(&spsa.Iterations, "iterations", spsa.Iterations, "spsa iterations")
+	flag.IntVar(&spsa.GamesPerIteration, "games", spsa.GamesPerIteration, "spsa games per iteration")
+	flag.IntVar(&spsa.MaxMoves, "maxmoves", spsa.MaxMoves, "spsa maximum moves per game")
+	flag.Int64Var(&spsa.Seed, "seed", spsa.Seed, "spsa random seed")
+	flag.Parse()
+
+	weights := engine.DefaultWeights()
+	if *start != "" {
+		var err error
+		if weights, err = engine.LoadWeights(*start); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	switch *method {
+	case "texel":
+		paths, err := filepath.Glob(*data)
+		if err != nil {
+			log.Fatal(err)
+		}
+		samples, err := tuner.LoadSamples(paths, *skip)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(samples) == 0 {
+			log.Fatalf("no samples found in %s", *data)
+		}
+		fmt.Fprintf(os.Stderr, "%d positions from %d files\n", len(samples), len(paths))
+		weights, _ = tuner.Texel(samples, weights, tuner.TexelOptions{
+			MaxPasses: *passes,
+			Progress: func(pass int, w engine.Weights, err float64) {
+				fmt.Fprintf(os.Stderr, "pass %d: %+v error %.6f\n", pass, w, err)
+			},
+		})
+	case "spsa":
+		spsa.Progress = func(iteration int, w engine.Weights) {
+			fmt.Fprintf(os.Stderr, "iteration %d: %+v\n", iteration, w)
+		}
+		weights = tuner.SPSA(weights, spsa)
+	default:
+		log.Fatalf("unknown method %q", *method)
+	}
+
+	if err := weights.Save(*out); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%+v\n", weights)
+}
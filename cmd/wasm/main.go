@@ -0,0 +1,203 @@
+//go:build js && wasm
+
+// Command wasm exposes the rules and the engines to JavaScript as the
+// global object goingo when built for the browser:
+//
+//	GOOS=js GOARCH=wasm go build -o web/goingo.wasm ./cmd/wasm
+//
+// Boards are referred to by integer ids. Points are [row, col] arrays and
+// colours are 1 for Black and 2 for White.
+//
+//	goingo.newBoard()                  id of an empty board, Black to move
+//	goingo.freeBoard(id)
+//	goingo.play(id, row, col)          {captured: n} or {error: "ko"}
+//	goingo.pass(id)
+//	goingo.isLegal(id, row, col)       true or false
+//	goingo.legalMoves(id)              [[row, col], ...]
+//	goingo.cells(id)                   81 colours in row-major order, 0 for empty
+//	goingo.toMove(id)                  1 or 2
+//	goingo.score(id)                   {black: n, white: n}, area without komi
+//	goingo.engineMove(id, name)        Promise of [row, col], or null for a pass; not played
+//
+// The engines are "alphabeta" (the default) and "random". Functions return
+// an Error object, rather than throwing, for unknown board ids.
+package main
+
+import (
+	"errors"
+	"syscall/js"
+
+	"github.com/RubikNube/GoInGo/pkg/engine"
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+var (
+	boards  = map[int]*game.State{}
+	nextID  = 1
+	engines = map[string]engine.Engine{
+		"alphabeta": engine.NewAlphaBetaEngine(),
+		"random":    engine.NewRandomEngine(),
+	}
+)
+
+func main() {
+	js.Global().Set("goingo", js.ValueOf(map[string]any{
+		"newBoard":   js.FuncOf(newBoard),
+		"freeBoard":  withBoard(freeBoard),
+		"play":       withBoard(play),
+		"pass":       withBoard(pass),
+		"isLegal":    withBoard(isLegal),
+		"legalMoves": withBoard(legalMoves),
+		"cells":      withBoard(cells),
+		"toMove":     withBoard(toMove),
+		"score":      withBoard(score),
+		"engineMove": withBoard(engineMove),
+	}))
+	// Keep the exported functions alive.
+	select {}
+}
+
+func newBoard(js.Value, []js.Value) any {
+	s := game.NewState(game.NewBoard(), game.Black)
+	id := nextID
+	nextID++
+	boards[id] = &s
+	return id
+}
+
+// withBoard wraps a function of a board whose id is the first argument. It
+// returns an Error object for unknown ids, since a Go panic would end the
+// program instead of throwing.
+func withBoard(f func(id int, s *game.State, args []js.Value) any) js.Func {
+	return js.FuncOf(func(_ js.Value, args []js.Value) any {
+		if len(args) == 0 || args[0].Type() != js.TypeNumber {
+			return newError("missing board id")
+		}
+		id := args[0].Int()
+		s, ok := boards[id]
+		if !ok {
+			return newError("unknown board id")
+		}
+		return f(id, s, args[1:])
+	})
+}
+
+func newError(msg string) js.Value {
+	return js.Global().Get("Error").New("goingo: " + msg)
+}
+
+func freeBoard(id int, _ *game.State, _ []js.Value) any {
+	delete(boards, id)
+	return nil
+}
+
+// point reads a row and column argument pair, reporting false if it is
+// missing, not a pair of numbers or off the board.
+func point(args []js.Value) (game.Point, bool) {
+	if len(args) < 2 || args[0].Type() != js.TypeNumber || args[1].Type() != js.TypeNumber {
+		return game.Point{}, false
+	}
+	row, col := args[0].Int(), args[1].Int()
+	if row < 0 || row >= game.BoardSize || col < 0 || col >= game.BoardSize {
+		return game.Point{}, false
+	}
+	return game.Point{Row: int8(row), Col: int8(col)}, true
+}
+
+func play(_ int, s *game.State, args []js.Value) any {
+	p, ok := point(args)
+	if !ok {
+		return map[string]any{"error": "off the board"}
+	}
+	next, captured, err := game.Play(*s, &p)
+	if err != nil {
+		return map[string]any{"error": errorName(err)}
+	}
+	*s = next
+	return map[string]any{"captured": len(captured)}
+}
+
+// errorName returns the short name of an error of game.Play.
+func errorName(err error) string {
+	switch {
+	case errors.Is(err, game.ErrOccupied):
+		return "occupied"
+	case errors.Is(err, game.ErrSuicide):
+		return "suicide"
+	case errors.Is(err, game.ErrKo):
+		return "ko"
+	case errors.Is(err, game.ErrSuperko):
+		return "superko"
+	}
+	return err.Error()
+}
+
+func pass(_ int, s *game.State, _ []js.Value) any {
+	*s, _, _ = game.Play(*s, nil)
+	return nil
+}
+
+func isLegal(_ int, s *game.State, args []js.Value) any {
+	p, ok := point(args)
+	if !ok {
+		return false
+	}
+	_, _, err := game.Play(*s, &p)
+	return err == nil
+}
+
+func legalMoves(_ int, s *game.State, _ []js.Value) any {
+	moves := []any{}
+	for _, p := range game.LegalMoves(*s) {
+		moves = append(moves, []any{int(p.Row), int(p.Col)})
+	}
+	return moves
+}
+
+func cells(_ int, s *game.State, _ []js.Value) any {
+	out := make([]any, 0, game.BoardSize*game.BoardSize)
+	for i := range s.Board {
+		for _, c := range s.Board[i] {
+			out = append(out, int(c))
+		}
+	}
+	return out
+}
+
+func toMove(_ int, s *game.State, _ []js.Value) any {
+	return int(s.ToMove)
+}
+
+func score(_ int, s *game.State, _ []js.Value) any {
+	black, white := game.CalculateScore(s.Board)
+	return map[string]any{"black": int(black), "white": int(white)}
+}
+
+// engineMove returns a Promise of the engine's move, computed on its own
+// goroutine so that the search does not run nested in the JavaScript call.
+func engineMove(_ int, s *game.State, args []js.Value) any {
+	name := "alphabeta"
+	if len(args) > 0 && args[0].Type() == js.TypeString {
+		name = args[0].String()
+	}
+	e, ok := engines[name]
+	state := *s
+	executor := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		resolve, reject := args[0], args[1]
+		if !ok {
+			reject.Invoke(newError("unknown engine " + name))
+			return nil
+		}
+		go func() {
+			move := e.Move(state.Board, state.ToMove, state.Ko)
+			if move == nil {
+				resolve.Invoke(js.Null())
+				return
+			}
+			resolve.Invoke([]any{int(move.Row), int(move.Col)})
+		}()
+		return nil
+	})
+	defer executor.Release()
+	return js.Global().Get("Promise").New(executor)
+}
@@ -1,26 +1,19 @@
 #!/usr/bin/env python3
-from ctypes import cdll, c_uint, c_int
+import os
+import sys
 
-lib = cdll.LoadLibrary('./libgoengine.so')
+sys.path.insert(0, os.path.join(os.path.dirname(os.path.abspath(__file__)), 'python'))
 
-# Create engines and board
-engineA = lib.NewAlphaBetaEngine()
-engineB = lib.OldAlphaBetaEngine()
-board = lib.NewBoard(c_int(9))
+from goingo import Engine, Match, MatchResult  # noqa: E402
 
-# Call CompareEngines with valid handles
-result = lib.CompareEngines(
-    c_uint(engineA),
-    c_uint(engineB),
-    c_uint(board),
-    c_int(1),      # firstPlayer
-    c_int(100)     # maxMoves
-)
+# Create the engines and let them play from the empty board
+with Engine.alpha_beta() as engineA, Engine.old_alpha_beta() as engineB:
+    result = Match(engineA, engineB, max_moves=100).play()
 
 # Print the result and name the winner
-if result == 1:
+if result == MatchResult.A_WINS:
     print("Winner: Engine A (Alpha-Beta)")
-elif result == 2:
+elif result == MatchResult.B_WINS:
     print("Winner: Engine B (Old Alpha-Beta)")
-elif result == 0:
+else:
     print("Result: Draw")
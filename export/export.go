@@ -1,11 +1,43 @@
+// Command export builds the shared library of GoInGo for C and other
+// languages with a C foreign function interface:
+//
+//	go build -buildmode=c-shared -o libgoengine.so ./export
+//
+// Engines and boards are referred to by handles, which are never 0, and
+// are released with FreeEngine and FreeBoard. Functions that can fail
+// return a negative GoInGoError and LastErrorMessage describes the failure
+// of the last call made by the calling thread.
 package main
 
+//go:generate go build -buildmode=c-shared -o libgoengine.so .
+
 /*
 #include <stdint.h>
+#include <stdlib.h>
+
+// Error codes returned by the library. Functions that return a count or a
+// flag return it as a non-negative value and one of these on failure.
+typedef enum {
+	GOINGO_OK = 0,
+	GOINGO_ERR_INVALID_HANDLE = -1,    // unknown or freed engine or board handle
+	GOINGO_ERR_OCCUPIED = -2,          // the point is occupied
+	GOINGO_ERR_SUICIDE = -3,           // the move would be suicide
+	GOINGO_ERR_KO = -4,                // the move would retake a ko
+	GOINGO_ERR_SUPERKO = -5,           // the move would repeat an earlier position
+	GOINGO_ERR_OUT_OF_RANGE = -6,      // an argument is out of range
+	GOINGO_ERR_BUFFER_TOO_SMALL = -7,  // the caller's buffer cannot hold the result
+} GoInGoError;
+
+// Internal: the per-thread message of LastErrorMessage, see lasterror.c.
+void goingoSetLastError(char *message);
+const char *goingoLastError(void);
 */
 import "C"
 import (
+	"errors"
+	"fmt"
 	"sync"
+	"unsafe"
 
 	"github.com/RubikNube/GoInGo/pkg/compareengines"
 	"github.com/RubikNube/GoInGo/pkg/engine"
@@ -13,76 +45,178 @@ import (
 	"github.com/RubikNube/GoInGo/pkg/game"
 )
 
+// registry maps handles to objects. Handle 0 is never used.
+type registry[T any] struct {
+	sync.Mutex
+	nextID  uint64
+	objects map[uint64]T
+}
+
+func newRegistry[T any]() *registry[T] {
+	return &registry[T]{nextID: 1, objects: make(map[uint64]T)}
+}
+
+func (r *registry[T]) add(v T) C.uint64_t {
+	r.Lock()
+	defer r.Unlock()
+	id := r.nextID
+	r.nextID++
+	r.objects[id] = v
+	return C.uint64_t(id)
+}
+
+// with calls f with the object of id while holding the lock and reports
+// whether the handle is valid.
+func (r *registry[T]) with(id C.uint64_t, f func(T)) bool {
+	r.Lock()
+	defer r.Unlock()
+	v, ok := r.objects[uint64(id)]
+	if ok {
+		f(v)
+	}
+	return ok
+}
+
+func (r *registry[T]) get(id C.uint64_t) (T, bool) {
+	var v T
+	ok := r.with(id, func(o T) { v = o })
+	return v, ok
+}
+
+func (r *registry[T]) free(id C.uint64_t) bool {
+	r.Lock()
+	defer r.Unlock()
+	_, ok := r.objects[uint64(id)]
+	delete(r.objects, uint64(id))
+	return ok
+}
+
 var (
-	engineRegistry = struct {
-		sync.Mutex
-		nextID  uint64
-		objects map[uint64]engine.Engine
-	}{objects: make(map[uint64]engine.Engine)}
-	boardRegistry = struct {
-		sync.Mutex
-		nextID  uint64
-		objects map[uint64]*game.Board
-	}{objects: make(map[uint64]*game.Board)}
+	engineRegistry = newRegistry[engine.Engine]()
+	boardRegistry  = newRegistry[*game.State]()
 )
 
+// lookupBoard returns a copy of the state of a board handle.
+func lookupBoard(id C.uint64_t) (game.State, bool) {
+	var s game.State
+	ok := boardRegistry.with(id, func(p *game.State) { s = *p })
+	return s, ok
+}
+
+// report records the outcome of a call for the calling thread and returns
+// code. Go code called from C runs on the caller's thread, and so do the C
+// functions it calls.
+func report(code C.int, format string, args ...any) C.int {
+	var message *C.char
+	if format != "" {
+		message = C.CString(fmt.Sprintf(format, args...))
+	}
+	C.goingoSetLastError(message)
+	return code
+}
+
+func succeed() {
+	report(C.GOINGO_OK, "")
+}
+
+func invalidHandle(kind string, id C.uint64_t) C.int {
+	return report(C.GOINGO_ERR_INVALID_HANDLE, "invalid %s handle %d", kind, uint64(id))
+}
+
+// LastErrorMessage returns a description of the failure of the last call
+// into the library made by the calling thread, or an empty string if it
+// succeeded. Each thread has its own message, like errno. The string is
+// owned by the library and valid until the calling thread's next call into
+// the library other than LastErrorMessage; it must not be freed.
+//
+//export LastErrorMessage
+func LastErrorMessage() *C.char {
+	return (*C.char)(unsafe.Pointer(C.goingoLastError()))
+}
+
 //export NewAlphaBetaEngine
 func NewAlphaBetaEngine() C.uint64_t {
-	engineRegistry.Lock()
-	defer engineRegistry.Unlock()
-	e := engine.NewAlphaBetaEngine()
-	id := engineRegistry.nextID
-	engineRegistry.nextID++
-	engineRegistry.objects[id] = e
-	return C.uint64_t(id)
+	succeed()
+	return engineRegistry.add(engine.NewAlphaBetaEngine())
 }
 
 //export OldAlphaBetaEngine
 func OldAlphaBetaEngine() C.uint64_t {
-	engineRegistry.Lock()
-	defer engineRegistry.Unlock()
-	e := old.NewAlphaBetaEngine()
-	id := engineRegistry.nextID
-	engineRegistry.nextID++
-	engineRegistry.objects[id] = e
-	return C.uint64_t(id)
+	succeed()
+	return engineRegistry.add(old.NewAlphaBetaEngine())
 }
 
 //export NewRandomEngine
 func NewRandomEngine() C.uint64_t {
-	engineRegistry.Lock()
-	defer engineRegistry.Unlock()
-	e := engine.NewRandomEngine()
-	id := engineRegistry.nextID
-	engineRegistry.nextID++
-	engineRegistry.objects[id] = e
-	return C.uint64_t(id)
+	succeed()
+	return engineRegistry.add(engine.NewRandomEngine())
+}
+
+// FreeEngine releases an engine handle. It returns GOINGO_OK or
+// GOINGO_ERR_INVALID_HANDLE.
+//
+//export FreeEngine
+func FreeEngine(engineID C.uint64_t) C.int {
+	if !engineRegistry.free(engineID) {
+		return invalidHandle("engine", engineID)
+	}
+	succeed()
+	return C.GOINGO_OK
 }
 
+// NewBoard returns a handle to an empty board with Black to move, or 0 if
+// size is not 9, the only supported size.
+//
 //export NewBoard
 func NewBoard(size C.int) C.uint64_t {
-	boardRegistry.Lock()
-	defer boardRegistry.Unlock()
-	b := game.NewBoard()
-	id := boardRegistry.nextID
-	boardRegistry.nextID++
-	boardRegistry.objects[id] = &b
-	return C.uint64_t(id)
+	if size != game.BoardSize {
+		report(C.GOINGO_ERR_OUT_OF_RANGE, "unsupported board size %d, only %d is supported", int(size), game.BoardSize)
+		return 0
+	}
+	succeed()
+	s := game.NewState(game.NewBoard(), game.Black)
+	return boardRegistry.add(&s)
+}
+
+// FreeBoard releases a board handle. It returns GOINGO_OK or
+// GOINGO_ERR_INVALID_HANDLE.
+//
+//export FreeBoard
+func FreeBoard(boardID C.uint64_t) C.int {
+	if !boardRegistry.free(boardID) {
+		return invalidHandle("board", boardID)
+	}
+	succeed()
+	return C.GOINGO_OK
 }
 
+// CompareEngines plays a game between two engines from the position of a
+// board, which is not changed, with firstPlayer (1 for Black, 2 for White)
+// moving first. It returns 1 if engine A wins, 2 if engine B wins and 0
+// for a draw.
+//
 //export CompareEngines
 func CompareEngines(engineAID, engineBID, boardID C.uint64_t, firstPlayer C.int, maxMoves C.int) C.int {
-	engineRegistry.Lock()
-	engineA := engineRegistry.objects[uint64(engineAID)]
-	engineB := engineRegistry.objects[uint64(engineBID)]
-	engineRegistry.Unlock()
-	boardRegistry.Lock()
-	board := boardRegistry.objects[uint64(boardID)]
-	boardRegistry.Unlock()
-	if engineA == nil || engineB == nil || board == nil {
-		return -1 // error code
-	}
-	result := compareengines.CompareEngines(engineA, engineB, *board, game.FieldState(firstPlayer), int(maxMoves))
+	engineA, ok := engineRegistry.get(engineAID)
+	if !ok {
+		return invalidHandle("engine", engineAID)
+	}
+	engineB, ok := engineRegistry.get(engineBID)
+	if !ok {
+		return invalidHandle("engine", engineBID)
+	}
+	board, ok := lookupBoard(boardID)
+	if !ok {
+		return invalidHandle("board", boardID)
+	}
+	if firstPlayer != C.int(game.Black) && firstPlayer != C.int(game.White) {
+		return report(C.GOINGO_ERR_OUT_OF_RANGE, "first player %d is neither 1 (Black) nor 2 (White)", int(firstPlayer))
+	}
+	if maxMoves < 0 {
+		return report(C.GOINGO_ERR_OUT_OF_RANGE, "negative move limit %d", int(maxMoves))
+	}
+	succeed()
+	result := compareengines.CompareEngines(engineA, engineB, board.Board, game.FieldState(firstPlayer), int(maxMoves))
 	if result == 0 {
 		return 0 // draw
 	} else if result > 0 {
@@ -92,4 +226,199 @@ func CompareEngines(engineAID, engineBID, boardID C.uint64_t, firstPlayer C.int,
 	}
 }
 
+// BoardSize returns the number of rows and columns of a board.
+//
+//export BoardSize
+func BoardSize() C.int {
+	succeed()
+	return game.BoardSize
+}
+
+// PlayMove plays the side to move at row, col and returns the number of
+// captured stones. An illegal move returns GOINGO_ERR_OCCUPIED,
+// GOINGO_ERR_SUICIDE or GOINGO_ERR_KO and leaves the board unchanged.
+//
+//export PlayMove
+func PlayMove(boardID C.uint64_t, row, col C.int) C.int {
+	if code := checkPoint(row, col); code != C.GOINGO_OK {
+		return code
+	}
+	p := game.Point{Row: int8(row), Col: int8(col)}
+	var captured []game.Point
+	var err error
+	if !boardRegistry.with(boardID, func(s *game.State) {
+		var next game.State
+		if next, captured, err = game.Play(*s, &p); err == nil {
+			*s = next
+		}
+	}) {
+		return invalidHandle("board", boardID)
+	}
+	if err != nil {
+		return report(moveError(err), "illegal move at row %d, column %d: %v", p.Row, p.Col, err)
+	}
+	succeed()
+	return C.int(len(captured))
+}
+
+// PassMove passes for the side to move and returns GOINGO_OK.
+//
+//export PassMove
+func PassMove(boardID C.uint64_t) C.int {
+	if !boardRegistry.with(boardID, func(s *game.State) { *s, _, _ = game.Play(*s, nil) }) {
+		return invalidHandle("board", boardID)
+	}
+	succeed()
+	return C.GOINGO_OK
+}
+
+// IsLegalMove returns 1 if the side to move may play at row, col and 0 if
+// not.
+//
+//export IsLegalMove
+func IsLegalMove(boardID C.uint64_t, row, col C.int) C.int {
+	if code := checkPoint(row, col); code != C.GOINGO_OK {
+		return code
+	}
+	s, ok := lookupBoard(boardID)
+	if !ok {
+		return invalidHandle("board", boardID)
+	}
+	succeed()
+	if _, _, err := game.Play(s, &game.Point{Row: int8(row), Col: int8(col)}); err != nil {
+		return 0
+	}
+	return 1
+}
+
+// ToMove returns the colour to move, 1 for Black and 2 for White.
+//
+//export ToMove
+func ToMove(boardID C.uint64_t) C.int {
+	s, ok := lookupBoard(boardID)
+	if !ok {
+		return invalidHandle("board", boardID)
+	}
+	succeed()
+	return C.int(s.ToMove)
+}
+
+// LegalMoves writes the legal moves of the side to move as row*size+col
+// indexes, in row-major order, to moves, which has room for n entries, and
+// returns their number. Passing is always legal and not listed.
+//
+//export LegalMoves
+func LegalMoves(boardID C.uint64_t, moves *C.int, n C.int) C.int {
+	s, ok := lookupBoard(boardID)
+	if !ok {
+		return invalidHandle("board", boardID)
+	}
+	legal := game.LegalMoves(s)
+	if int(n) < len(legal) || (len(legal) > 0 && moves == nil) {
+		return report(C.GOINGO_ERR_BUFFER_TOO_SMALL, "%d legal moves do not fit into %d entries", len(legal), int(n))
+	}
+	succeed()
+	if len(legal) == 0 {
+		return 0
+	}
+	out := unsafe.Slice(moves, len(legal))
+	for i, p := range legal {
+		out[i] = C.int(int(p.Row)*game.BoardSize + int(p.Col))
+	}
+	return C.int(len(legal))
+}
+
+// GetBoard writes the board in row-major order to cells, which has room for
+// n entries, as 0 for empty, 1 for Black and 2 for White, and returns the
+// number of cells written.
+//
+//export GetBoard
+func GetBoard(boardID C.uint64_t, cells *C.int, n C.int) C.int {
+	s, ok := lookupBoard(boardID)
+	if !ok {
+		return invalidHandle("board", boardID)
+	}
+	const size = game.BoardSize * game.BoardSize
+	if n < size || cells == nil {
+		return report(C.GOINGO_ERR_BUFFER_TOO_SMALL, "%d cells do not fit into %d entries", size, int(n))
+	}
+	succeed()
+	out := unsafe.Slice(cells, size)
+	for i := range out {
+		out[i] = C.int(s.Board[i/game.BoardSize][i%game.BoardSize])
+	}
+	return size
+}
+
+// EngineMove asks an engine for its move for the side to move without
+// playing it. It returns 1 and stores the move in row and col, or 0 if the
+// engine passes.
+//
+//export EngineMove
+func EngineMove(engineID, boardID C.uint64_t, row, col *C.int) C.int {
+	e, ok := engineRegistry.get(engineID)
+	if !ok {
+		return invalidHandle("engine", engineID)
+	}
+	s, ok := lookupBoard(boardID)
+	if !ok {
+		return invalidHandle("board", boardID)
+	}
+	succeed()
+	move := e.Move(s.Board, s.ToMove, s.Ko)
+	if move == nil {
+		return 0
+	}
+	if row != nil {
+		*row = C.int(move.Row)
+	}
+	if col != nil {
+		*col = C.int(move.Col)
+	}
+	return 1
+}
+
+// Score stores the area score of the board, stones plus surrounded empty
+// points and without komi, in black and white and returns GOINGO_OK.
+//
+//export Score
+func Score(boardID C.uint64_t, black, white *C.int) C.int {
+	s, ok := lookupBoard(boardID)
+	if !ok {
+		return invalidHandle("board", boardID)
+	}
+	succeed()
+	b, w := game.CalculateScore(s.Board)
+	if black != nil {
+		*black = C.int(b)
+	}
+	if white != nil {
+		*white = C.int(w)
+	}
+	return C.GOINGO_OK
+}
+
+// checkPoint returns GOINGO_OK if row, col is on the board.
+func checkPoint(row, col C.int) C.int {
+	if row < 0 || row >= game.BoardSize || col < 0 || col >= game.BoardSize {
+		return report(C.GOINGO_ERR_OUT_OF_RANGE, "point at row %d, column %d is off the board", int(row), int(col))
+	}
+	return C.GOINGO_OK
+}
+
+// moveError maps the errors of game.Play to error codes.
+func moveError(err error) C.int {
+	switch {
+	case errors.Is(err, game.ErrOccupied):
+		return C.GOINGO_ERR_OCCUPIED
+	case errors.Is(err, game.ErrSuicide):
+		return C.GOINGO_ERR_SUICIDE
+	case errors.Is(err, game.ErrKo):
+		return C.GOINGO_ERR_KO
+	case errors.Is(err, game.ErrSuperko):
+		return C.GOINGO_ERR_SUPERKO
+	}
+	return C.GOINGO_ERR_OUT_OF_RANGE
+}
+
 func main() {}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestCAPI builds the shared library and runs the C program in testdata
+// against it, using the generated header. cgo is not allowed in tests, so
+// the program is compiled with the system C compiler.
+func TestCAPI(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the C build in short mode")
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("the C test program is built for Unix-like systems only")
+	}
+	cc, err := exec.LookPath("gcc")
+	if err != nil {
+		t.Skip("gcc not found")
+	}
+	if out, err := exec.Command("go", "env", "CGO_ENABLED").Output(); err != nil || strings.TrimSpace(string(out)) != "1" {
+		t.Skip("cgo is disabled")
+	}
+
+	dir := t.TempDir()
+	build := exec.Command("go", "build", "-buildmode=c-shared", "-o", filepath.Join(dir, "libgoengine.so"), ".")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("Expected the shared library to build: %v\n%s", err, out)
+	}
+	bin := filepath.Join(dir, "api_test")
+	compile := exec.Command(cc, "-std=c99", "-Wall", "-Werror", "-pthread", "-I", dir, "-o", bin,
+		filepath.Join("testdata", "api_test.c"), "-L", dir, "-lgoengine", "-Wl,-rpath,"+dir)
+	if out, err := compile.CombinedOutput(); err != nil {
+		t.Fatalf("Expected the C test program to compile: %v\n%s", err, out)
+	}
+	run := exec.Command(bin)
+	run.Env = append(os.Environ(), "LD_LIBRARY_PATH="+dir)
+	if out, err := run.CombinedOutput(); err != nil {
+		t.Fatalf("Expected the C test program to pass: %v\n%s", err, out)
+	}
+}
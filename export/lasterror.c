@@ -0,0 +1,15 @@
+// The message of the last call into the library is kept per thread, like
+// errno, so that threads using the library at the same time neither see
+// nor free each other's messages.
+#include <stdlib.h>
+
+static _Thread_local char *lastError;
+
+void goingoSetLastError(char *message) {
+	free(lastError);
+	lastError = message;
+}
+
+const char *goingoLastError(void) {
+	return lastError != NULL ? lastError : "";
+}
@@ -0,0 +1,128 @@
+/* Code generated by cmd/cgo; DO NOT EDIT. */
+
+/* package github.com/RubikNube/GoInGo/export */
+
+
+#line 1 "cgo-builtin-export-prolog"
+
+#include <stddef.h>
+
+#ifndef GO_CGO_EXPORT_PROLOGUE_H
+#define GO_CGO_EXPORT_PROLOGUE_H
+
+#ifndef GO_CGO_GOSTRING_TYPEDEF
+typedef struct { const char *p; ptrdiff_t n; } _GoString_;
+extern size_t _GoStringLen(_GoString_ s);
+extern const char *_GoStringPtr(_GoString_ s);
+#endif
+
+#endif
+
+/* Start of preamble from import "C" comments.  */
+
+
+#line 14 "export.go"
+
+#include <stdint.h>
+#include <stdlib.h>
+
+// Error codes returned by the library. Functions that return a count or a
+// flag return it as a non-negative value and one of these on failure.
+typedef enum {
+	GOINGO_OK = 0,
+	GOINGO_ERR_INVALID_HANDLE = -1,    // unknown or freed engine or board handle
+	GOINGO_ERR_OCCUPIED = -2,          // the point is occupied
+	GOINGO_ERR_SUICIDE = -3,           // the move would be suicide
+	GOINGO_ERR_KO = -4,                // the move would retake a ko
+	GOINGO_ERR_SUPERKO = -5,           // the move would repeat an earlier position
+	GOINGO_ERR_OUT_OF_RANGE = -6,      // an argument is out of range
+	GOINGO_ERR_BUFFER_TOO_SMALL = -7,  // the caller's buffer cannot hold the result
+} GoInGoError;
+
+// Internal: the per-thread message of LastErrorMessage, see lasterror.c.
+void goingoSetLastError(char *message);
+const char *goingoLastError(void);
+
+#line 1 "cgo-generated-wrapper"
+
+
+/* End of preamble from import "C" comments.  */
+
+
+/* Start of boilerplate cgo prologue.  */
+#line 1 "cgo-gcc-export-header-prolog"
+
+#ifndef GO_CGO_PROLOGUE_H
+#define GO_CGO_PROLOGUE_H
+
+typedef signed char GoInt8;
+typedef unsigned char GoUint8;
+typedef short GoInt16;
+typedef unsigned short GoUint16;
+typedef int GoInt32;
+typedef unsigned int GoUint32;
+typedef long long GoInt64;
+typedef unsigned long long GoUint64;
+typedef GoInt64 GoInt;
+typedef GoUint64 GoUint;
+typedef size_t GoUintptr;
+typedef float GoFloat32;
+typedef double GoFloat64;
+#ifdef _MSC_VER
+#if !defined(__cplusplus) || _MSVC_LANG <= 201402L
+#include <complex.h>
+typedef _Fcomplex GoComplex64;
+typedef _Dcomplex GoComplex128;
+#else
+#include <complex>
+typedef std::complex<float> GoComplex64;
+typedef std::complex<double> GoComplex128;
+#endif
+#else
+typedef float _Complex GoComplex64;
+typedef double _Complex GoComplex128;
+#endif
+
+/*
+  static assertion to make sure the file is being used on architecture
+  at least with matching size of GoInt.
+*/
+typedef char _check_for_64_bit_pointer_matching_GoInt[sizeof(void*)==64/8 ? 1:-1];
+
+#ifndef GO_CGO_GOSTRING_TYPEDEF
+typedef _GoString_ GoString;
+#endif
+typedef void *GoMap;
+typedef void *GoChan;
+typedef struct { void *t; void *v; } GoInterface;
+typedef struct { void *data; GoInt len; GoInt cap; } GoSlice;
+
+#endif
+
+/* End of boilerplate cgo prologue.  */
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+extern char* LastErrorMessage(void);
+extern uint64_t NewAlphaBetaEngine(void);
+extern uint64_t OldAlphaBetaEngine(void);
+extern uint64_t NewRandomEngine(void);
+extern int FreeEngine(uint64_t engineID);
+extern uint64_t NewBoard(int size);
+extern int FreeBoard(uint64_t boardID);
+extern int CompareEngines(uint64_t engineAID, uint64_t engineBID, uint64_t boardID, int firstPlayer, int maxMoves);
+extern int BoardSize(void);
+extern int PlayMove(uint64_t boardID, int row, int col);
+extern int PassMove(uint64_t boardID);
+extern int IsLegalMove(uint64_t boardID, int row, int col);
+extern int ToMove(uint64_t boardID);
+extern int LegalMoves(uint64_t boardID, int* moves, int n);
+extern int GetBoard(uint64_t boardID, int* cells, int n);
+extern int EngineMove(uint64_t engineID, uint64_t boardID, int* row, int* col);
+extern int Score(uint64_t boardID, int* black, int* white);
+
+#ifdef __cplusplus
+}
+#endif
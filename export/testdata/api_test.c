@@ -0,0 +1,102 @@
+// Exercises the C API of libgoengine. Built and run by TestCAPI.
+#include <pthread.h>
+#include <stdio.h>
+#include <string.h>
+
+#include "libgoengine.h"
+
+static int failures = 0;
+
+#define CHECK(cond)                                                       \
+	do {                                                                  \
+		if (!(cond)) {                                                    \
+			fprintf(stderr, "%s:%d: check failed: %s (last error: %s)\n", \
+			        __FILE__, __LINE__, #cond, LastErrorMessage());       \
+			failures++;                                                   \
+		}                                                                 \
+	} while (0)
+
+// succeedElsewhere makes a successful call on another thread and checks
+// that its message is empty.
+static void *succeedElsewhere(void *arg) {
+	(void)arg;
+	CHECK(BoardSize() == 9);
+	CHECK(strcmp(LastErrorMessage(), "") == 0);
+	return NULL;
+}
+
+int main(void) {
+	int size = BoardSize();
+	CHECK(size == 9);
+	CHECK(NewBoard(19) == 0);
+	CHECK(strlen(LastErrorMessage()) > 0);
+
+	uint64_t board = NewBoard(size);
+	CHECK(board != 0);
+	CHECK(strcmp(LastErrorMessage(), "") == 0);
+	CHECK(ToMove(board) == 1);
+
+	// Black and White build a ko on the top edge; White captures at (0,1).
+	int moves[][2] = {{0, 0}, {0, 3}, {1, 1}, {1, 2}, {0, 2}};
+	for (int i = 0; i < 5; i++) {
+		CHECK(PlayMove(board, moves[i][0], moves[i][1]) == 0);
+	}
+	CHECK(PlayMove(board, 0, 1) == 1);
+	CHECK(IsLegalMove(board, 0, 2) == 0);
+	CHECK(PlayMove(board, 0, 2) == GOINGO_ERR_KO);
+	CHECK(PlayMove(board, 0, 0) == GOINGO_ERR_OCCUPIED);
+	CHECK(PlayMove(board, 9, 0) == GOINGO_ERR_OUT_OF_RANGE);
+	CHECK(strlen(LastErrorMessage()) > 0);
+
+	int cells[81];
+	CHECK(GetBoard(board, cells, 10) == GOINGO_ERR_BUFFER_TOO_SMALL);
+	CHECK(GetBoard(board, cells, 81) == 81);
+	CHECK(cells[1] == 2 && cells[2] == 0);
+
+	int legal[81];
+	int n = LegalMoves(board, legal, 81);
+	CHECK(n == 81 - 5 - 1);
+	for (int i = 0; i < n; i++) {
+		CHECK(legal[i] != 2);
+	}
+
+	uint64_t engine = NewRandomEngine();
+	int row = -1, col = -1;
+	CHECK(EngineMove(engine, board, &row, &col) == 1);
+	CHECK(IsLegalMove(board, row, col) == 1);
+
+	CHECK(PassMove(board) == GOINGO_OK);
+	CHECK(ToMove(board) == 2);
+
+	int black = -1, white = -1;
+	CHECK(Score(board, &black, &white) == GOINGO_OK);
+	CHECK(black >= 0 && white >= 0);
+
+	uint64_t other = NewRandomEngine();
+	int result = CompareEngines(engine, other, board, 1, 20);
+	CHECK(result >= 0 && result <= 2);
+
+	CHECK(FreeEngine(other) == GOINGO_OK);
+	CHECK(FreeEngine(other) == GOINGO_ERR_INVALID_HANDLE);
+	CHECK(EngineMove(other, board, &row, &col) == GOINGO_ERR_INVALID_HANDLE);
+	CHECK(FreeBoard(board) == GOINGO_OK);
+	CHECK(ToMove(board) == GOINGO_ERR_INVALID_HANDLE);
+	CHECK(PlayMove(0, 4, 4) == GOINGO_ERR_INVALID_HANDLE);
+	CHECK(FreeEngine(engine) == GOINGO_OK);
+
+	// Each thread keeps the message of its own last call.
+	CHECK(FreeBoard(board) == GOINGO_ERR_INVALID_HANDLE);
+	const char *message = LastErrorMessage();
+	pthread_t thread;
+	CHECK(pthread_create(&thread, NULL, succeedElsewhere, NULL) == 0);
+	CHECK(pthread_join(thread, NULL) == 0);
+	CHECK(strstr(message, "invalid board handle") != NULL);
+	CHECK(LastErrorMessage() == message);
+
+	if (failures > 0) {
+		fprintf(stderr, "%d checks failed\n", failures);
+		return 1;
+	}
+	printf("ok\n");
+	return 0;
+}
@@ -0,0 +1,170 @@
+package bitboard
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+// randomBoard fills about two thirds of the points at random.
+func randomBoard(rng *rand.Rand) game.Board {
+	var b game.Board
+	for i := range b {
+		for j := range b[i] {
+			b[i][j] = game.FieldState(rng.Intn(3))
+		}
+	}
+	return b
+}
+
+func toMask(set map[game.Point]struct{}) Mask {
+	var m Mask
+	for p := range set {
+		m = m.Set(p)
+	}
+	return m
+}
+
+func TestRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 100; n++ {
+		b := randomBoard(rng)
+		if got := FromBoard(b).Board(); got != b {
+			t.Fatalf("Expected the board to survive the conversion:\n%v\n%v", b, got)
+		}
+	}
+}
+
+func TestNeighborsDoNotWrap(t *testing.T) {
+	n := Bit(game.Point{Row: 0, Col: 8}).Neighbors()
+	want := Bit(game.Point{Row: 0, Col: 7}).Set(game.Point{Row: 1, Col: 8})
+	if n != want {
+		t.Errorf("Expected %v, got %v", want.Points(), n.Points())
+	}
+	n = Bit(game.Point{Row: 8, Col: 0}).Neighbors()
+	want = Bit(game.Point{Row: 7, Col: 0}).Set(game.Point{Row: 8, Col: 1})
+	if n != want {
+		t.Errorf("Expected %v, got %v", want.Points(), n.Points())
+	}
+	if Full.Count() != 81 || Full.Neighbors() != Full {
+		t.Errorf("Expected the full board to be its own neighbourhood")
+	}
+}
+
+func TestChainMatchesGroup(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for n := 0; n < 100; n++ {
+		b := randomBoard(rng)
+		pos := FromBoard(b)
+		for i := int8(0); i < game.BoardSize; i++ {
+			for j := int8(0); j < game.BoardSize; j++ {
+				p := game.Point{Row: i, Col: j}
+				if b[i][j] == game.Empty {
+					continue
+				}
+				group, libs := game.Group(b, p)
+				stones, liberties := pos.Chain(p)
+				if stones != toMask(group) || liberties != toMask(libs) {
+					t.Fatalf("Expected chain at %v to match game.Group on\n%v", p, b)
+				}
+			}
+		}
+	}
+}
+
+func TestPlayMatchesRules(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for n := 0; n < 100; n++ {
+		b := randomBoard(rng)
+		pos := FromBoard(b)
+		for i := int8(0); i < game.BoardSize; i++ {
+			for j := int8(0); j < game.BoardSize; j++ {
+				p := game.Point{Row: i, Col: j}
+				for _, color := range []game.FieldState{game.Black, game.White} {
+					next, captured, ok := pos.Play(p, color)
+					if legal := game.IsLegalMove(b, p, color, game.Board{}); ok != legal {
+						t.Fatalf("Expected legality %v for %v at %v on\n%v", legal, color, p, b)
+					}
+					if !ok {
+						if next != pos {
+							t.Fatalf("Expected the position unchanged after illegal %v at %v on\n%v", color, p, b)
+						}
+						continue
+					}
+					if next.At(p) != color || !next.Stones(color).And(captured).IsEmpty() || captured.Count() != pos.Black.Or(pos.White).Count()+1-next.Black.Or(next.White).Count() {
+						t.Fatalf("Expected consistent capture for %v at %v on\n%v", color, p, b)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestCapture(t *testing.T) {
+	var b game.Board
+	b[0][0] = game.White
+	b[0][1] = game.Black
+	pos, captured, ok := FromBoard(b).Play(game.Point{Row: 1, Col: 0}, game.Black)
+	if !ok || captured != Bit(game.Point{Row: 0, Col: 0}) || pos.At(game.Point{Row: 0, Col: 0}) != game.Empty {
+		t.Errorf("Expected the corner stone to be captured, got %v", captured.Points())
+	}
+	if pos.IsLegal(game.Point{Row: 0, Col: 0}, game.White) {
+		t.Errorf("Expected suicide to be illegal")
+	}
+}
+
+func benchmarkBoard() game.Board {
+	return randomBoard(rand.New(rand.NewSource(4)))
+}
+
+func BenchmarkChain(b *testing.B) {
+	pos := FromBoard(benchmarkBoard())
+	for i := 0; i < b.N; i++ {
+		for p := pos.Black.Or(pos.White); !p.IsEmpty(); {
+			stones, _ := pos.Chain(p.First())
+			p = p.AndNot(stones)
+		}
+	}
+}
+
+func BenchmarkGroup(b *testing.B) {
+	board := benchmarkBoard()
+	for i := 0; i < b.N; i++ {
+		visited := make(map[game.Point]struct{})
+		for r := int8(0); r < game.BoardSize; r++ {
+			for c := int8(0); c < game.BoardSize; c++ {
+				p := game.Point{Row: r, Col: c}
+				if _, ok := visited[p]; ok || board[r][c] == game.Empty {
+					continue
+				}
+				stones, _ := game.Group(board, p)
+				for s := range stones {
+					visited[s] = struct{}{}
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkLegalMovesBitboard(b *testing.B) {
+	pos := FromBoard(benchmarkBoard())
+	for i := 0; i < b.N; i++ {
+		for _, p := range pos.Empty().Points() {
+			pos.IsLegal(p, game.Black)
+		}
+	}
+}
+
+func BenchmarkLegalMovesBoard(b *testing.B) {
+	board := benchmarkBoard()
+	for i := 0; i < b.N; i++ {
+		for r := int8(0); r < game.BoardSize; r++ {
+			for c := int8(0); c < game.BoardSize; c++ {
+				if board[r][c] == game.Empty {
+					game.IsLegalMove(board, game.Point{Row: r, Col: c}, game.Black, board)
+				}
+			}
+		}
+	}
+}
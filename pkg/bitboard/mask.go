@@ -0,0 +1,115 @@
+// Package bitboard represents 9x9 positions as bit masks, one bit per
+// point in row-major order, so that chains and liberties can be computed
+// with a few shifts instead of map-based flood fills.
+package bitboard
+
+import (
+	"math/bits"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+// Mask is a set of points: bit row*9+col, with bits 0-63 in Lo and the
+// remaining 17 bits in Hi.
+type Mask struct {
+	Lo, Hi uint64
+}
+
+const points = game.BoardSize * game.BoardSize
+
+var (
+	// Full contains every point of the board.
+	Full = Mask{Lo: ^uint64(0), Hi: 1<<(points-64) - 1}
+	// notFirstCol and notLastCol exclude column 0 and column 8; they stop
+	// horizontal shifts from wrapping to the next row.
+	notFirstCol, notLastCol = func() (a, b Mask) {
+		for i := int8(0); i < game.BoardSize; i++ {
+			for j := int8(0); j < game.BoardSize; j++ {
+				p := game.Point{Row: i, Col: j}
+				if j != 0 {
+					a = a.Set(p)
+				}
+				if j != game.BoardSize-1 {
+					b = b.Set(p)
+				}
+			}
+		}
+		return a, b
+	}()
+)
+
+// Bit returns the mask holding only p.
+func Bit(p game.Point) Mask {
+	i := uint(p.Row)*game.BoardSize + uint(p.Col)
+	if i < 64 {
+		return Mask{Lo: 1 << i}
+	}
+	return Mask{Hi: 1 << (i - 64)}
+}
+
+// Set returns m with p added.
+func (m Mask) Set(p game.Point) Mask { return m.Or(Bit(p)) }
+
+// Clear returns m with p removed.
+func (m Mask) Clear(p game.Point) Mask { return m.AndNot(Bit(p)) }
+
+// Has reports whether p is in m.
+func (m Mask) Has(p game.Point) bool { return !m.And(Bit(p)).IsEmpty() }
+
+func (m Mask) And(o Mask) Mask    { return Mask{m.Lo & o.Lo, m.Hi & o.Hi} }
+func (m Mask) Or(o Mask) Mask     { return Mask{m.Lo | o.Lo, m.Hi | o.Hi} }
+func (m Mask) AndNot(o Mask) Mask { return Mask{m.Lo &^ o.Lo, m.Hi &^ o.Hi} }
+
+// IsEmpty reports whether m contains no point.
+func (m Mask) IsEmpty() bool { return m.Lo == 0 && m.Hi == 0 }
+
+// Count returns the number of points in m.
+func (m Mask) Count() int { return bits.OnesCount64(m.Lo) + bits.OnesCount64(m.Hi) }
+
+// shl shifts m towards higher bits by n < 64.
+func (m Mask) shl(n uint) Mask { return Mask{m.Lo << n, m.Hi<<n | m.Lo>>(64-n)} }
+
+// shr shifts m towards lower bits by n < 64.
+func (m Mask) shr(n uint) Mask { return Mask{m.Lo>>n | m.Hi<<(64-n), m.Hi >> n} }
+
+// Neighbors returns the points adjacent to a point of m, which may include
+// points of m itself.
+func (m Mask) Neighbors() Mask {
+	east := m.shl(1).And(notFirstCol)
+	west := m.shr(1).And(notLastCol)
+	south := m.shl(game.BoardSize)
+	north := m.shr(game.BoardSize)
+	return east.Or(west).Or(south).Or(north).And(Full)
+}
+
+// Grow returns the points of within connected to seed, which must be a
+// subset of within.
+func Grow(seed, within Mask) Mask {
+	for {
+		next := seed.Or(seed.Neighbors().And(within))
+		if next == seed {
+			return seed
+		}
+		seed = next
+	}
+}
+
+// First returns the point with the lowest index in m; m must not be empty.
+func (m Mask) First() game.Point {
+	i := bits.TrailingZeros64(m.Lo)
+	if m.Lo == 0 {
+		i = 64 + bits.TrailingZeros64(m.Hi)
+	}
+	return game.Point{Row: int8(i / game.BoardSize), Col: int8(i % game.BoardSize)}
+}
+
+// Points returns the points of m in row-major order.
+func (m Mask) Points() []game.Point {
+	ps := make([]game.Point, 0, m.Count())
+	for !m.IsEmpty() {
+		p := m.First()
+		ps = append(ps, p)
+		m = m.Clear(p)
+	}
+	return ps
+}
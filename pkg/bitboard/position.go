@@ -0,0 +1,115 @@
+package bitboard
+
+import "github.com/RubikNube/GoInGo/pkg/game"
+
+// Position holds the stones of both colours.
+type Position struct {
+	Black, White Mask
+}
+
+// FromBoard converts b into a Position.
+func FromBoard(b game.Board) Position {
+	var pos Position
+	for i := int8(0); i < game.BoardSize; i++ {
+		for j := int8(0); j < game.BoardSize; j++ {
+			switch b[i][j] {
+			case game.Black:
+				pos.Black = pos.Black.Set(game.Point{Row: i, Col: j})
+			case game.White:
+				pos.White = pos.White.Set(game.Point{Row: i, Col: j})
+			}
+		}
+	}
+	return pos
+}
+
+// Board converts pos into a game.Board.
+func (pos Position) Board() game.Board {
+	var b game.Board
+	for _, p := range pos.Black.Points() {
+		b[p.Row][p.Col] = game.Black
+	}
+	for _, p := range pos.White.Points() {
+		b[p.Row][p.Col] = game.White
+	}
+	return b
+}
+
+// Stones returns the stones of color.
+func (pos Position) Stones(color game.FieldState) Mask {
+	switch color {
+	case game.Black:
+		return pos.Black
+	case game.White:
+		return pos.White
+	}
+	return Mask{}
+}
+
+// Empty returns the empty points.
+func (pos Position) Empty() Mask {
+	return Full.AndNot(pos.Black.Or(pos.White))
+}
+
+// At returns the colour at p.
+func (pos Position) At(p game.Point) game.FieldState {
+	switch {
+	case pos.Black.Has(p):
+		return game.Black
+	case pos.White.Has(p):
+		return game.White
+	}
+	return game.Empty
+}
+
+// Chain returns the stones connected to p and their liberties, both empty
+// if p is empty. It is the bitboard counterpart of game.Group.
+func (pos Position) Chain(p game.Point) (stones, liberties Mask) {
+	color := pos.At(p)
+	if color == game.Empty {
+		return Mask{}, Mask{}
+	}
+	stones = Grow(Bit(p), pos.Stones(color))
+	return stones, stones.Neighbors().And(pos.Empty())
+}
+
+// Play places color at p, removes the opponent chains left without
+// liberties and returns the new position and the captured stones. It
+// reports false, with pos unchanged, for an occupied point or suicide; ko
+// is left to the caller.
+func (pos Position) Play(p game.Point, color game.FieldState) (Position, Mask, bool) {
+	before := pos
+	bit := Bit(p)
+	if !pos.Black.Or(pos.White).And(bit).IsEmpty() {
+		return pos, Mask{}, false
+	}
+	own, opp := &pos.Black, &pos.White
+	if color == game.White {
+		own, opp = opp, own
+	}
+	*own = own.Or(bit)
+	empty := pos.Empty()
+	var captured Mask
+	adjacent := bit.Neighbors().And(*opp)
+	for !adjacent.IsEmpty() {
+		chain := Grow(Bit(adjacent.First()), *opp)
+		adjacent = adjacent.AndNot(chain)
+		if chain.Neighbors().And(empty).IsEmpty() {
+			captured = captured.Or(chain)
+		}
+	}
+	*opp = opp.AndNot(captured)
+	if captured.IsEmpty() {
+		chain := Grow(bit, *own)
+		if chain.Neighbors().And(empty).IsEmpty() {
+			return before, Mask{}, false
+		}
+	}
+	return pos, captured, true
+}
+
+// IsLegal reports whether color may play at p, not counting ko.
+func (pos Position) IsLegal(p game.Point, color game.FieldState) bool {
+	_, _, ok := pos.Play(p, color)
+	return ok
+}
@@ -0,0 +1,185 @@
+// Package book implements an opening book: move statistics collected from
+// game records and keyed by a position hash that is the same for all eight
+// rotations and reflections of a position.
+package book
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/sgf"
+)
+
+// Entry holds the statistics of a move in the canonical orientation of its
+// position. Wins and Losses are from the perspective of the player making
+// the move; the remaining games were drawn or had no result.
+type Entry struct {
+	Move   string `json:"move"` // SGF coordinate
+	Count  int    `json:"count"`
+	Wins   int    `json:"wins"`
+	Losses int    `json:"losses"`
+}
+
+// Book maps position hashes to the moves played from them.
+type Book struct {
+	Positions map[uint64][]Entry `json:"positions"`
+}
+
+// Candidate is a book move in the orientation of the queried board.
+type Candidate struct {
+	Move   game.Point
+	Count  int
+	Wins   int
+	Losses int
+}
+
+// New returns an empty book.
+func New() *Book {
+	return &Book{Positions: make(map[uint64][]Entry)}
+}
+
+// Load reads a book written by Save.
+func Load(path string) (*Book, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b := New()
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("book: decode %s: %w", path, err)
+	}
+	if b.Positions == nil {
+		b.Positions = make(map[uint64][]Entry)
+	}
+	return b, nil
+}
+
+// Save writes the book as JSON.
+func (b *Book) Save(path string) error {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Add records that player played move in board and scored result: +1 for
+// a win, -1 for a loss and 0 otherwise.
+func (b *Book) Add(board game.Board, player game.FieldState, move game.Point, result int) {
+	hash, c, sym := canonical(board, player)
+	m := canonicalMove(move, c, sym)
+	code := sgf.EncodePoint(&m)
+	entries := b.Positions[hash]
+	i := 0
+	for i < len(entries) && entries[i].Move != code {
+		i++
+	}
+	if i == len(entries) {
+		entries = append(entries, Entry{Move: code})
+	}
+	entries[i].Count++
+	switch {
+	case result > 0:
+		entries[i].Wins++
+	case result < 0:
+		entries[i].Losses++
+	}
+	b.Positions[hash] = entries
+}
+
+// Lookup returns the book moves for player in board, translated back to
+// the orientation of board.
+func (b *Book) Lookup(board game.Board, player game.FieldState) []Candidate {
+	hash, _, sym := canonical(board, player)
+	entries := b.Positions[hash]
+	candidates := make([]Candidate, 0, len(entries))
+	for _, e := range entries {
+		p, err := sgf.DecodePoint(e.Move)
+		if err != nil || p == nil {
+			continue
+		}
+		candidates = append(candidates, Candidate{
+			Move:   sym.Inverse().Point(*p),
+			Count:  e.Count,
+			Wins:   e.Wins,
+			Losses: e.Losses,
+		})
+	}
+	return candidates
+}
+
+// Prune removes the moves played fewer than minCount times and positions
+// left without moves.
+func (b *Book) Prune(minCount int) {
+	for hash, entries := range b.Positions {
+		kept := entries[:0]
+		for _, e := range entries {
+			if e.Count >= minCount {
+				kept = append(kept, e)
+			}
+		}
+		if len(kept) == 0 {
+			delete(b.Positions, hash)
+		} else {
+			b.Positions[hash] = kept
+		}
+	}
+}
+
+// zobrist holds a random key per point and colour. The keys are fixed so
+// that hashes stay valid across runs and book files.
+var zobrist = func() (t [game.BoardSize][game.BoardSize][3]uint64) {
+	x := uint64(0x9e3779b97f4a7c15)
+	next := func() uint64 {
+		// splitmix64
+		x += 0x9e3779b97f4a7c15
+		z := x
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		return z ^ (z >> 31)
+	}
+	for i := range t {
+		for j := range t[i] {
+			t[i][j][game.Black] = next()
+			t[i][j][game.White] = next()
+		}
+	}
+	return t
+}()
+
+// whiteToMove is mixed into the hash of positions with White to move.
+const whiteToMove = 0x5bd1e9955bd1e995
+
+// canonical returns the hash of the canonical form of board (see
+// game.Canonical) with player to move and the symmetry that maps board onto
+// that form.
+func canonical(board game.Board, player game.FieldState) (uint64, game.Board, game.Symmetry) {
+	c, sym := game.Canonical(board)
+	var hash uint64
+	for i := range c {
+		for j, color := range c[i] {
+			if color != game.Empty {
+				hash ^= zobrist[i][j][color]
+			}
+		}
+	}
+	if player == game.White {
+		hash ^= whiteToMove
+	}
+	return hash, c, sym
+}
+
+// canonicalMove maps move into the canonical orientation. If the canonical
+// board is itself symmetric, equivalent moves are merged into the smallest one.
+func canonicalMove(move game.Point, c game.Board, sym game.Symmetry) game.Point {
+	best := sym.Point(move)
+	for _, s := range game.Symmetries(c)[1:] {
+		p := s.Point(sym.Point(move))
+		if p.Row < best.Row || (p.Row == best.Row && p.Col < best.Col) {
+			best = p
+		}
+	}
+	return best
+}
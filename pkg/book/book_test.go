@@ -0,0 +1,153 @@
+package book
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/RubikNube/GoInGo/pkg/clock"
+	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/sgf"
+)
+
+func TestSymmetricPositionsShareEntries(t *testing.T) {
+	b := New()
+	var board game.Board
+	board[2][2] = game.Black
+	b.Add(board, game.White, game.Point{Row: 2, Col: 6}, 1)
+
+	// The same position rotated by 90 degrees.
+	var rotated game.Board
+	rotated[2][6] = game.Black
+	candidates := b.Lookup(rotated, game.White)
+	if len(candidates) != 1 {
+		t.Fatalf("Expected one candidate, got %+v", candidates)
+	}
+	move := candidates[0].Move
+	// The reply must keep its relation to the black stone: on the same
+	// 3-3 line, four columns or rows away.
+	if rotated[move.Row][move.Col] != game.Empty || (move != game.Point{Row: 6, Col: 6} && move != game.Point{Row: 2, Col: 2}) {
+		t.Errorf("Expected the rotated reply, got %+v", move)
+	}
+	if len(b.Lookup(rotated, game.Black)) != 0 {
+		t.Errorf("Expected the side to move to be part of the key")
+	}
+}
+
+func TestSymmetricMovesAreMerged(t *testing.T) {
+	b := New()
+	var empty game.Board
+	b.Add(empty, game.Black, game.Point{Row: 2, Col: 2}, 1)
+	b.Add(empty, game.Black, game.Point{Row: 6, Col: 2}, -1)
+	b.Add(empty, game.Black, game.Point{Row: 4, Col: 4}, 0)
+	candidates := b.Lookup(empty, game.Black)
+	if len(candidates) != 2 {
+		t.Fatalf("Expected two distinct moves, got %+v", candidates)
+	}
+	if c := candidates[0]; c.Count != 2 || c.Wins != 1 || c.Losses != 1 {
+		t.Errorf("Expected the 3-3 points to be counted together, got %+v", c)
+	}
+}
+
+func TestAddGameSaveLoad(t *testing.T) {
+	roots, err := sgf.ParseString("(;SZ[9]RE[B+5];B[ee];W[cc];B[gc])(;SZ[9]RE[W+1];B[ee];W[gg];W[])")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := New()
+	for _, root := range roots {
+		g, err := sgf.MainLine(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.AddGame(g, 2)
+	}
+	b.Prune(1)
+	path := filepath.Join(t.TempDir(), "book.json")
+	if err := b.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var board game.Board
+	first := loaded.Lookup(board, game.Black)
+	if len(first) != 1 || first[0].Count != 2 || first[0].Wins != 1 || first[0].Losses != 1 {
+		t.Errorf("Expected the tengen opening from both games, got %+v", first)
+	}
+	board[4][4] = game.Black
+	// W[cc] and W[gg] are equivalent after B[ee].
+	if replies := loaded.Lookup(board, game.White); len(replies) != 1 || replies[0].Count != 2 {
+		t.Errorf("Expected both replies merged, got %+v", replies)
+	}
+	board[2][2] = game.White
+	if moves := loaded.Lookup(board, game.Black); len(moves) != 0 {
+		t.Errorf("Expected moves beyond the depth to be left out, got %+v", moves)
+	}
+}
+
+type passEngine struct{}
+
+func (passEngine) Move(game.Board, game.FieldState, *game.Point) *game.Point { return nil }
+
+func TestEngine(t *testing.T) {
+	b := New()
+	var board game.Board
+	b.Add(board, game.Black, game.Point{Row: 4, Col: 4}, 1)
+	e := NewEngineWithSeed(b, passEngine{}, 1)
+	if move := e.Move(board, game.Black, nil); move == nil || *move != (game.Point{Row: 4, Col: 4}) {
+		t.Errorf("Expected the book move, got %+v", move)
+	}
+	if move := e.Move(board, game.Black, &game.Point{Row: 4, Col: 4}); move != nil {
+		t.Errorf("Expected the fallback for a book move on the ko point, got %+v", move)
+	}
+	board[0][0] = game.White
+	if move := e.Move(board, game.Black, nil); move != nil {
+		t.Errorf("Expected the fallback out of book, got %+v", move)
+	}
+}
+
+func TestEngineWeightsWinsAboveUnknownResults(t *testing.T) {
+	won := Candidate{Count: 10, Wins: 10}
+	unknown := Candidate{Count: 10}
+	lost := Candidate{Count: 10, Losses: 10}
+	if weight(won) <= weight(unknown) || weight(unknown) <= weight(lost) {
+		t.Errorf("Expected wins above unknown results above losses, got %v, %v and %v", weight(won), weight(unknown), weight(lost))
+	}
+	if w := weight(unknown); w != 5 {
+		t.Errorf("Expected games without a result to count as draws, got %v", w)
+	}
+}
+
+// clockEngine records the time and the time control it was told.
+type clockEngine struct {
+	passEngine
+	remaining time.Duration
+	control   clock.TimeControl
+}
+
+func (e *clockEngine) TimeSettings(tc clock.TimeControl) {
+	e.control = tc
+}
+
+func (e *clockEngine) TimeLeft(_ game.FieldState, remaining time.Duration, _ int) {
+	e.remaining = remaining
+}
+
+func TestEngineForwardsTimeLeft(t *testing.T) {
+	fallback := &clockEngine{}
+	NewEngine(New(), fallback).TimeLeft(game.Black, time.Minute, 0)
+	if fallback.remaining != time.Minute {
+		t.Errorf("Expected the fallback to be told the time left, got %v", fallback.remaining)
+	}
+}
+
+func TestEngineForwardsTimeSettings(t *testing.T) {
+	fallback := &clockEngine{}
+	tc := clock.TimeControl{System: clock.ByoYomi, Main: time.Minute, Period: 30 * time.Second, Periods: 5}
+	NewEngine(New(), fallback).TimeSettings(tc)
+	if fallback.control != tc {
+		t.Errorf("Expected the fallback to be told the time control, got %+v", fallback.control)
+	}
+}
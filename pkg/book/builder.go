@@ -0,0 +1,64 @@
+package book
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/sgf"
+)
+
+// AddGame adds the first maxMoves moves of g to the book, counting the
+// result for the player of each move. Games with setup stones, such as
+// handicap games, are skipped, as is everything after the first pass.
+func (b *Book) AddGame(g *sgf.Game, maxMoves int) {
+	if len(g.SetupBlack) > 0 || len(g.SetupWhite) > 0 {
+		return
+	}
+	winner := g.Winner()
+	var board game.Board
+	for i, m := range g.Moves {
+		if i >= maxMoves || m.Point == nil || board[m.Point.Row][m.Point.Col] != game.Empty {
+			return
+		}
+		result := 0
+		switch winner {
+		case m.Color:
+			result = 1
+		case game.Opponent(m.Color):
+			result = -1
+		}
+		b.Add(board, m.Color, *m.Point, result)
+		next, _, err := game.Play(game.NewState(board, m.Color), m.Point)
+		if err != nil {
+			return
+		}
+		board = next.Board
+	}
+}
+
+// Import adds the main line of every game in the SGF files to the book and
+// returns the number of games read.
+func (b *Book) Import(paths []string, maxMoves int) (int, error) {
+	games := 0
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return games, err
+		}
+		roots, err := sgf.Parse(f)
+		f.Close()
+		if err != nil {
+			return games, fmt.Errorf("%s: %w", path, err)
+		}
+		for _, root := range roots {
+			g, err := sgf.MainLine(root)
+			if err != nil {
+				return games, fmt.Errorf("%s: %w", path, err)
+			}
+			b.AddGame(g, maxMoves)
+			games++
+		}
+	}
+	return games, nil
+}
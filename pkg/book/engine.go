@@ -0,0 +1,87 @@
+package book
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/RubikNube/GoInGo/pkg/clock"
+	"github.com/RubikNube/GoInGo/pkg/engine"
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+// Engine plays book moves while the position is in the book and asks
+// Fallback otherwise. It wraps any engine.Engine.
+type Engine struct {
+	Book     *Book
+	Fallback engine.Engine
+	// MinCount ignores book moves played fewer times.
+	MinCount int
+	rng      *rand.Rand
+}
+
+// NewEngine returns an Engine seeded from the clock.
+func NewEngine(b *Book, fallback engine.Engine) *Engine {
+	return NewEngineWithSeed(b, fallback, time.Now().UnixNano())
+}
+
+// NewEngineWithSeed returns an Engine with a fixed random seed.
+func NewEngineWithSeed(b *Book, fallback engine.Engine, seed int64) *Engine {
+	return &Engine{Book: b, Fallback: fallback, MinCount: 1, rng: rand.New(rand.NewSource(seed))}
+}
+
+// Move implements engine.Engine. Legal book moves are chosen at random
+// with weight proportional to how often they were played, scaled by their
+// score (wins plus half the other games, with one virtual draw) so that
+// popular but losing moves are played less.
+func (e *Engine) Move(board game.Board, player game.FieldState, ko *game.Point) *game.Point {
+	var moves []game.Point
+	var weights []float64
+	total := 0.0
+	s := game.State{Board: board, ToMove: player, Ko: ko}
+	for _, c := range e.Book.Lookup(board, player) {
+		if c.Count < e.MinCount {
+			continue
+		}
+		if _, _, err := game.Play(s, &c.Move); err != nil {
+			continue
+		}
+		w := weight(c)
+		moves = append(moves, c.Move)
+		weights = append(weights, w)
+		total += w
+	}
+	if len(moves) == 0 {
+		return e.Fallback.Move(board, player, ko)
+	}
+	x := e.rng.Float64() * total
+	for i, w := range weights {
+		x -= w
+		if x < 0 {
+			return &moves[i]
+		}
+	}
+	return &moves[len(moves)-1]
+}
+
+// weight returns the weight of c in the choice of a book move: its count
+// scaled by its score, the wins plus half the other games with one
+// virtual draw.
+func weight(c Candidate) float64 {
+	score := (float64(c.Wins) + 0.5*float64(c.Count-c.Wins-c.Losses) + 0.5) / float64(c.Count+1)
+	return float64(c.Count) * score
+}
+
+// TimeLeft implements engine.TimeAware by passing the time on to Fallback.
+func (e *Engine) TimeLeft(player game.FieldState, remaining time.Duration, stones int) {
+	if t, ok := e.Fallback.(engine.TimeAware); ok {
+		t.TimeLeft(player, remaining, stones)
+	}
+}
+
+// TimeSettings implements engine.TimeSettingsAware by passing the time
+// control on to Fallback.
+func (e *Engine) TimeSettings(tc clock.TimeControl) {
+	if t, ok := e.Fallback.(engine.TimeSettingsAware); ok {
+		t.TimeSettings(tc)
+	}
+}
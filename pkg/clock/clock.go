@@ -0,0 +1,386 @@
+// Package clock implements game clocks for absolute time, Fischer
+// increment, Japanese byo-yomi and Canadian overtime.
+//
+// A Clock reads the time from a function passed to New, so that tests and
+// replays can drive it without sleeping.
+package clock
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+// System is a kind of time control.
+type System int
+
+const (
+	// Absolute gives each player Main for the whole game.
+	Absolute System = iota
+	// Fischer adds Increment to the main time after every move.
+	Fischer
+	// ByoYomi follows the main time with Periods periods of length Period.
+	// A move within a period keeps it; a period used up is lost.
+	ByoYomi
+	// Canadian follows the main time with blocks of length Period in which
+	// Stones moves must be played.
+	Canadian
+)
+
+// TimeControl describes the time each player has.
+type TimeControl struct {
+	System    System
+	Main      time.Duration
+	Increment time.Duration // Fischer
+	Period    time.Duration // ByoYomi and Canadian
+	Periods   int           // ByoYomi
+	Stones    int           // Canadian
+}
+
+// Parse parses a time control written as one of
+//
+//	absolute:10m        (or just 10m)
+//	fischer:5m+10s
+//	byoyomi:10m+5x30s
+//	canadian:10m+25/5m
+//
+// with durations in the format of time.ParseDuration.
+func Parse(s string) (TimeControl, error) {
+	system, spec, ok := strings.Cut(s, ":")
+	if !ok {
+		system, spec = "absolute", s
+	}
+	main, overtime, _ := strings.Cut(spec, "+")
+	var tc TimeControl
+	var err error
+	if tc.Main, err = time.ParseDuration(main); err != nil {
+		return tc, fmt.Errorf("clock: invalid main time in %q: %w", s, err)
+	}
+	switch system {
+	case "absolute":
+		tc.System = Absolute
+		if overtime != "" {
+			return tc, fmt.Errorf("clock: absolute time has no overtime: %q", s)
+		}
+	case "fischer":
+		tc.System = Fischer
+		tc.Increment, err = time.ParseDuration(overtime)
+	case "byoyomi":
+		tc.System = ByoYomi
+		periods, period, ok := strings.Cut(overtime, "x")
+		if !ok {
+			return tc, fmt.Errorf("clock: expected periods x period in %q", s)
+		}
+		if tc.Periods, err = strconv.Atoi(periods); err == nil {
+			tc.Period, err = time.ParseDuration(period)
+		}
+	case "canadian":
+		tc.System = Canadian
+		stones, period, ok := strings.Cut(overtime, "/")
+		if !ok {
+			return tc, fmt.Errorf("clock: expected stones / period in %q", s)
+		}
+		if tc.Stones, err = strconv.Atoi(stones); err == nil {
+			tc.Period, err = time.ParseDuration(period)
+		}
+	default:
+		return tc, fmt.Errorf("clock: unknown time system %q", system)
+	}
+	if err != nil {
+		return tc, fmt.Errorf("clock: invalid overtime in %q: %w", s, err)
+	}
+	return tc, tc.Validate()
+}
+
+// Validate reports whether tc gives the players any time.
+func (tc TimeControl) Validate() error {
+	switch {
+	case tc.Main < 0 || tc.Increment < 0 || tc.Period < 0:
+		return errors.New("clock: negative time")
+	case tc.System == ByoYomi && (tc.Periods < 1 || tc.Period == 0):
+		return errors.New("clock: byo-yomi needs at least one period")
+	case tc.System == Canadian && (tc.Stones < 1 || tc.Period == 0):
+		return errors.New("clock: Canadian overtime needs stones and a period")
+	case (tc.System == Absolute || tc.System == Fischer) && tc.Main == 0:
+		return errors.New("clock: no main time")
+	}
+	return nil
+}
+
+// String formats tc as accepted by Parse.
+func (tc TimeControl) String() string {
+	switch tc.System {
+	case Fischer:
+		return fmt.Sprintf("fischer:%v+%v", tc.Main, tc.Increment)
+	case ByoYomi:
+		return fmt.Sprintf("byoyomi:%v+%dx%v", tc.Main, tc.Periods, tc.Period)
+	case Canadian:
+		return fmt.Sprintf("canadian:%v+%d/%v", tc.Main, tc.Stones, tc.Period)
+	}
+	return fmt.Sprintf("absolute:%v", tc.Main)
+}
+
+// Overtime describes the overtime for the OT property of SGF, e.g.
+// "5x30 byo-yomi"; it is empty for absolute time.
+func (tc TimeControl) Overtime() string {
+	seconds := func(d time.Duration) string { return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) }
+	switch tc.System {
+	case Fischer:
+		return seconds(tc.Increment) + " fischer"
+	case ByoYomi:
+		return fmt.Sprintf("%dx%s byo-yomi", tc.Periods, seconds(tc.Period))
+	case Canadian:
+		return fmt.Sprintf("%d/%s Canadian", tc.Stones, seconds(tc.Period))
+	}
+	return ""
+}
+
+// State is the time a player has left.
+type State struct {
+	Main time.Duration
+	// Periods is the number of byo-yomi periods left, including the
+	// current one.
+	Periods int
+	// Period is the time left in the current byo-yomi period or Canadian
+	// block.
+	Period time.Duration
+	// Stones is the number of moves left to play in the Canadian block.
+	Stones  int
+	Flagged bool
+}
+
+// Overtime reports whether the main time is used up and the player is in
+// byo-yomi or Canadian overtime.
+func (s State) Overtime() bool {
+	return s.Main == 0 && !s.Flagged && (s.Periods > 0 || s.Stones > 0)
+}
+
+// TimeLeft returns the time and the number of moves to play in it as GTP's
+// time_left reports them: the main time with 0 stones, and in overtime the
+// time of the current period with 1 stone for byo-yomi or the stones left
+// for Canadian overtime.
+func (s State) TimeLeft() (time.Duration, int) {
+	switch {
+	case !s.Overtime():
+		return s.Main, 0
+	case s.Stones > 0:
+		return s.Period, s.Stones
+	}
+	return s.Period, 1
+}
+
+// String formats s for display: "4:59" in main time, "0:25 (3)" with three
+// byo-yomi periods, "2:10/4" with four stones to play in a Canadian block.
+func (s State) String() string {
+	switch {
+	case s.Flagged:
+		return "0:00"
+	case !s.Overtime():
+		return formatDuration(s.Main)
+	case s.Stones > 0:
+		return fmt.Sprintf("%s/%d", formatDuration(s.Period), s.Stones)
+	}
+	return fmt.Sprintf("%s (%d)", formatDuration(s.Period), s.Periods)
+}
+
+// formatDuration formats d as h:mm:ss or m:ss, rounding up to the second.
+func formatDuration(d time.Duration) string {
+	secs := int((d + time.Second - 1) / time.Second)
+	if secs >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+	}
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
+
+// initial returns the state at the start of a game.
+func (tc TimeControl) initial() State {
+	s := State{Main: tc.Main}
+	switch tc.System {
+	case ByoYomi:
+		s.Periods, s.Period = tc.Periods, tc.Period
+	case Canadian:
+		s.Stones, s.Period = tc.Stones, tc.Period
+	}
+	if s.Main == 0 && !s.Overtime() {
+		s.Flagged = true
+	}
+	return s
+}
+
+// spend returns s after thinking for d in one turn. Main time is used
+// first; the flag falls when no time is left.
+func (tc TimeControl) spend(s State, d time.Duration) State {
+	if s.Flagged {
+		return s
+	}
+	if d < s.Main {
+		s.Main -= d
+		return s
+	}
+	d -= s.Main
+	s.Main = 0
+	switch tc.System {
+	case ByoYomi:
+		for s.Periods > 0 && d >= s.Period {
+			d -= s.Period
+			s.Periods--
+			s.Period = tc.Period
+		}
+		if s.Periods > 0 {
+			s.Period -= d
+			return s
+		}
+	case Canadian:
+		if d < s.Period {
+			s.Period -= d
+			return s
+		}
+	}
+	s.Main, s.Period, s.Periods = 0, 0, 0
+	s.Flagged = true
+	return s
+}
+
+// moved returns s after a move played in time.
+func (tc TimeControl) moved(s State) State {
+	switch tc.System {
+	case Fischer:
+		s.Main += tc.Increment
+	case ByoYomi:
+		if s.Main == 0 {
+			s.Period = tc.Period
+		}
+	case Canadian:
+		if s.Main == 0 {
+			if s.Stones--; s.Stones == 0 {
+				s.Stones, s.Period = tc.Stones, tc.Period
+			}
+		}
+	}
+	return s
+}
+
+// left returns the time until the flag of a player in state s falls.
+func (tc TimeControl) left(s State) time.Duration {
+	switch {
+	case s.Flagged:
+		return 0
+	case tc.System == ByoYomi && s.Periods > 0:
+		return s.Main + s.Period + time.Duration(s.Periods-1)*tc.Period
+	case tc.System == Canadian:
+		return s.Main + s.Period
+	}
+	return s.Main
+}
+
+// Clock is the pair of clocks of a game. At most one of them runs. A Clock
+// is not safe for concurrent use.
+type Clock struct {
+	tc      TimeControl
+	now     func() time.Time
+	players [2]State
+	running game.FieldState
+	since   time.Time
+}
+
+// New returns a stopped clock giving both players tc. now returns the
+// current time; nil means time.Now.
+func New(tc TimeControl, now func() time.Time) *Clock {
+	if now == nil {
+		now = time.Now
+	}
+	return &Clock{tc: tc, now: now, players: [2]State{tc.initial(), tc.initial()}}
+}
+
+// Resume returns a stopped clock on which the players have black and white
+// left, e.g. for a game taken up again after its clock was put away.
+func Resume(tc TimeControl, black, white State, now func() time.Time) *Clock {
+	c := New(tc, now)
+	c.players = [2]State{black, white}
+	return c
+}
+
+// TimeControl returns the time control of c.
+func (c *Clock) TimeControl() TimeControl {
+	return c.tc
+}
+
+// Running returns the player whose clock runs, game.Empty if none does.
+func (c *Clock) Running() game.FieldState {
+	return c.running
+}
+
+// State returns the time color has left, counting the current turn.
+func (c *Clock) State(color game.FieldState) State {
+	s := c.players[index(color)]
+	if color == c.running {
+		s = c.tc.spend(s, c.now().Sub(c.since))
+	}
+	return s
+}
+
+// Flagged returns the player who ran out of time, game.Empty if neither did.
+func (c *Clock) Flagged() game.FieldState {
+	for _, color := range []game.FieldState{game.Black, game.White} {
+		if c.State(color).Flagged {
+			return color
+		}
+	}
+	return game.Empty
+}
+
+// UntilFlag returns the time until the flag of the running player falls,
+// or false if no clock runs.
+func (c *Clock) UntilFlag() (time.Duration, bool) {
+	if c.running == game.Empty {
+		return 0, false
+	}
+	return c.tc.left(c.State(c.running)), true
+}
+
+// Start stops the running clock, if any, and starts color's. The time of
+// the stopped player's turn is charged without the bonus of a move, e.g.
+// when the turn ends by an undo. A clock with a fallen flag stays stopped.
+func (c *Clock) Start(color game.FieldState) {
+	c.Stop()
+	if c.Flagged() == game.Empty {
+		c.running, c.since = color, c.now()
+	}
+}
+
+// Press ends the turn of the running player with a move played: the time
+// is charged, the increment or overtime of the move applied and the
+// opponent's clock started.
+func (c *Clock) Press() {
+	player := c.running
+	if player == game.Empty {
+		return
+	}
+	s := c.State(player)
+	c.running = game.Empty
+	if s.Flagged {
+		c.players[index(player)] = s
+		return
+	}
+	c.players[index(player)] = c.tc.moved(s)
+	c.running, c.since = game.Opponent(player), c.now()
+}
+
+// Stop charges the running player's time and stops the clock.
+func (c *Clock) Stop() {
+	if c.running != game.Empty {
+		c.players[index(c.running)] = c.State(c.running)
+		c.running = game.Empty
+	}
+}
+
+func index(color game.FieldState) int {
+	if color == game.White {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,156 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+// fakeTime is a time source that only moves when told to.
+type fakeTime struct {
+	t time.Time
+}
+
+func (f *fakeTime) now() time.Time { return f.t }
+
+func (f *fakeTime) advance(d time.Duration) { f.t = f.t.Add(d) }
+
+func newClock(t *testing.T, spec string) (*Clock, *fakeTime) {
+	t.Helper()
+	tc, err := Parse(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeTime{t: time.Unix(0, 0)}
+	return New(tc, f.now), f
+}
+
+func TestParse(t *testing.T) {
+	cases := map[string]TimeControl{
+		"10m":                {System: Absolute, Main: 10 * time.Minute},
+		"absolute:90s":       {System: Absolute, Main: 90 * time.Second},
+		"fischer:5m+10s":     {System: Fischer, Main: 5 * time.Minute, Increment: 10 * time.Second},
+		"byoyomi:10m+5x30s":  {System: ByoYomi, Main: 10 * time.Minute, Periods: 5, Period: 30 * time.Second},
+		"byoyomi:0s+3x10s":   {System: ByoYomi, Periods: 3, Period: 10 * time.Second},
+		"canadian:10m+25/5m": {System: Canadian, Main: 10 * time.Minute, Stones: 25, Period: 5 * time.Minute},
+	}
+	for spec, want := range cases {
+		got, err := Parse(spec)
+		if err != nil || got != want {
+			t.Errorf("Expected %s to parse as %+v, got %+v, %v", spec, want, got, err)
+		}
+		if again, err := Parse(got.String()); err != nil || again != got {
+			t.Errorf("Expected %q to round-trip, got %+v, %v", got.String(), again, err)
+		}
+	}
+	for _, spec := range []string{"", "0s", "10", "hourglass:1m", "absolute:1m+5s", "fischer:1m", "byoyomi:1m+5", "byoyomi:1m+0x30s", "canadian:1m+/5m", "-1m"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+}
+
+func TestAbsolute(t *testing.T) {
+	c, f := newClock(t, "1m")
+	c.Start(game.Black)
+	f.advance(20 * time.Second)
+	if s := c.State(game.Black); s.Main != 40*time.Second || c.State(game.White).Main != time.Minute {
+		t.Errorf("Expected Black's clock to run, got %v", s)
+	}
+	c.Press()
+	f.advance(10 * time.Second)
+	if c.State(game.Black).Main != 40*time.Second || c.State(game.White).Main != 50*time.Second || c.Running() != game.White {
+		t.Errorf("Expected White's clock to run after Black's move")
+	}
+	if d, ok := c.UntilFlag(); !ok || d != 50*time.Second {
+		t.Errorf("Expected White's flag to fall in 50s, got %v", d)
+	}
+	f.advance(50 * time.Second)
+	if c.Flagged() != game.White {
+		t.Errorf("Expected White to lose on time")
+	}
+	c.Press()
+	if c.Running() != game.Empty || c.State(game.White).String() != "0:00" {
+		t.Errorf("Expected the clock to stop after a flag")
+	}
+}
+
+func TestFischer(t *testing.T) {
+	c, f := newClock(t, "fischer:1m+10s")
+	c.Start(game.Black)
+	f.advance(15 * time.Second)
+	c.Press()
+	if s := c.State(game.Black); s.Main != 55*time.Second || s.String() != "0:55" {
+		t.Errorf("Expected 45s plus the increment, got %v", s)
+	}
+}
+
+func TestByoYomi(t *testing.T) {
+	c, f := newClock(t, "byoyomi:1m+3x30s")
+	c.Start(game.Black)
+	f.advance(70 * time.Second)
+	s := c.State(game.Black)
+	if !s.Overtime() || s.Periods != 3 || s.Period != 20*time.Second || s.String() != "0:20 (3)" {
+		t.Errorf("Expected 20s left in the first period, got %+v", s)
+	}
+	if d, n := s.TimeLeft(); d != 20*time.Second || n != 1 {
+		t.Errorf("Expected time_left 20s for 1 stone, got %v %d", d, n)
+	}
+	c.Press()
+	if s := c.State(game.Black); s.Period != 30*time.Second || s.Periods != 3 {
+		t.Errorf("Expected the period to restart after a move in time, got %+v", s)
+	}
+	c.Start(game.Black)
+	f.advance(65 * time.Second)
+	if s := c.State(game.Black); s.Periods != 1 || s.Period != 25*time.Second {
+		t.Errorf("Expected two periods to be used up, got %+v", s)
+	}
+	if d, _ := c.UntilFlag(); d != 25*time.Second {
+		t.Errorf("Expected the flag to fall with the last period, got %v", d)
+	}
+	f.advance(25 * time.Second)
+	if c.Flagged() != game.Black {
+		t.Errorf("Expected Black to lose on time")
+	}
+}
+
+func TestCanadian(t *testing.T) {
+	c, f := newClock(t, "canadian:10s+2/1m")
+	c.Start(game.White)
+	f.advance(30 * time.Second)
+	c.Press()
+	s := c.State(game.White)
+	if s.Period != 40*time.Second || s.Stones != 1 || s.String() != "0:40/1" {
+		t.Errorf("Expected one more stone in 40s, got %+v", s)
+	}
+	if d, n := s.TimeLeft(); d != 40*time.Second || n != 1 {
+		t.Errorf("Expected time_left 40s for 1 stone, got %v %d", d, n)
+	}
+	c.Press() // Black
+	f.advance(30 * time.Second)
+	c.Press()
+	if s := c.State(game.White); s.Period != time.Minute || s.Stones != 2 {
+		t.Errorf("Expected a new block after the stones were played, got %+v", s)
+	}
+	c.Press() // Black
+	f.advance(time.Minute)
+	if c.Flagged() != game.White {
+		t.Errorf("Expected White to lose on time")
+	}
+}
+
+func TestStartWithoutMove(t *testing.T) {
+	c, f := newClock(t, "fischer:1m+10s")
+	c.Start(game.Black)
+	f.advance(10 * time.Second)
+	c.Start(game.White)
+	if s := c.State(game.Black); s.Main != 50*time.Second {
+		t.Errorf("Expected no increment without a move, got %v", s)
+	}
+	c.Stop()
+	f.advance(time.Hour)
+	if c.Flagged() != game.Empty || c.Running() != game.Empty {
+		t.Errorf("Expected a stopped clock not to run")
+	}
+}
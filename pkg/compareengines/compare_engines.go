@@ -9,34 +9,74 @@ import (
 // CompareEngines lets two engines play against each other and returns the winner.
 // Returns: 1 if engineA wins, -1 if engineB wins, 0 for draw.
 func CompareEngines(engineA, engineB engine.Engine, board game.Board, firstPlayer game.FieldState, maxMoves int) int {
-	player := firstPlayer
-	var ko *game.Point
+	return PlayGame(EngineMoveFunc(engineA), EngineMoveFunc(engineB), board, firstPlayer, maxMoves).Result
+}
+
+// MoveFunc chooses the move for player. It returns nil to pass and may attach
+// a comment to the move, e.g. the engine's evaluation.
+type MoveFunc func(board game.Board, player game.FieldState, ko *game.Point, moveNumber int) (move *game.Point, comment string)
+
+// EngineMoveFunc returns a MoveFunc that asks e for its move.
+func EngineMoveFunc(e engine.Engine) MoveFunc {
+	return func(board game.Board, player game.FieldState, ko *game.Point, _ int) (*game.Point, string) {
+		return e.Move(board, player, ko), ""
+	}
+}
+
+// PlayedMove is a move of a finished game. Point is nil for a pass.
+type PlayedMove struct {
+	Player  game.FieldState
+	Point   *game.Point
+	Comment string
+}
+
+// GameRecord describes a finished game between two players.
+type GameRecord struct {
+	FirstPlayer game.FieldState
+	Moves       []PlayedMove
+	Board       game.Board // final position
+	// Result is 1 if the first player wins, -1 if the second player wins, 0 for draw.
+	Result int
+}
+
+// PlayGame plays a game between moveA, who moves first as firstPlayer, and
+// moveB. The game ends after two consecutive passes or maxMoves moves. An
+// illegal move is played as a pass.
+func PlayGame(moveA, moveB MoveFunc, board game.Board, firstPlayer game.FieldState, maxMoves int) GameRecord {
+	record := GameRecord{FirstPlayer: firstPlayer}
+	state := game.NewState(board, firstPlayer)
 	moveCount := 0
 	passCount := 0
 	for moveCount < maxMoves && passCount < 2 {
 		var move *game.Point
-		if player == firstPlayer {
-			move = engineA.Move(board, player, ko)
+		var comment string
+		if state.ToMove == firstPlayer {
+			move, comment = moveA(state.Board, state.ToMove, state.Ko, moveCount)
 		} else {
-			move = engineB.Move(board, player, ko)
+			move, comment = moveB(state.Board, state.ToMove, state.Ko, moveCount)
+		}
+		next, _, err := game.Play(state, move)
+		if err != nil {
+			move = nil
+			next, _, _ = game.Play(state, nil)
 		}
+		record.Moves = append(record.Moves, PlayedMove{Player: state.ToMove, Point: move, Comment: comment})
 		if move == nil {
 			passCount++
 		} else {
 			passCount = 0
-			board[move.Row][move.Col] = player
-			ko = nil // Optionally update ko if needed
 		}
-		player = opponent(player)
+		state = next
 		moveCount++
 	}
-	score := evaluate(board, firstPlayer, opponent(firstPlayer))
+	record.Board = state.Board
+	score := evaluate(state.Board, firstPlayer, opponent(firstPlayer))
 	if score > 0 {
-		return 1
+		record.Result = 1
 	} else if score < 0 {
-		return -1
+		record.Result = -1
 	}
-	return 0
+	return record
 }
 
 // opponent returns the opposite FieldState (Black <-> White).
@@ -0,0 +1,43 @@
+package compareengines
+
+import (
+	"testing"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+// scripted returns a MoveFunc playing the given moves and passing afterwards.
+func scripted(moves ...game.Point) MoveFunc {
+	return func(game.Board, game.FieldState, *game.Point, int) (*game.Point, string) {
+		if len(moves) == 0 {
+			return nil, ""
+		}
+		m := moves[0]
+		moves = moves[1:]
+		return &m, ""
+	}
+}
+
+func TestPlayGameRemovesCapturedStones(t *testing.T) {
+	black := scripted(game.Point{Row: 0, Col: 1}, game.Point{Row: 1, Col: 0})
+	white := scripted(game.Point{Row: 0, Col: 0})
+	record := PlayGame(black, white, game.NewBoard(), game.Black, 10)
+	if record.Board[0][0] != game.Empty {
+		t.Errorf("Expected white stone at (0,0) to be captured")
+	}
+	if len(record.Moves) != 5 || record.Moves[4].Point != nil {
+		t.Errorf("Expected three moves followed by two passes, got %+v", record.Moves)
+	}
+	if record.Result != 1 {
+		t.Errorf("Expected first player to win, got %d", record.Result)
+	}
+}
+
+func TestPlayGamePassesForIllegalMoves(t *testing.T) {
+	black := scripted(game.Point{Row: 4, Col: 4})
+	white := scripted(game.Point{Row: 4, Col: 4}, game.Point{Row: 3, Col: 3})
+	record := PlayGame(black, white, game.NewBoard(), game.Black, 3)
+	if record.Moves[1].Point != nil || record.Board[4][4] != game.Black {
+		t.Errorf("Expected White's move on the occupied point to be a pass, got %+v", record.Moves)
+	}
+}
@@ -2,8 +2,12 @@ package engine
 
 import (
 	"sort"
+	"time"
 
+	"github.com/RubikNube/GoInGo/pkg/clock"
 	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/pattern"
+	"github.com/RubikNube/GoInGo/pkg/position"
 )
 
 // AlphaBetaEngine implements Engine using alpha-beta pruning with killer move heuristic, transposition table, and history heuristic.
@@ -11,6 +15,13 @@ type AlphaBetaEngine struct {
 	killerMoves        map[int]*game.Point // depth -> killer move
 	transpositionTable map[uint64]int      // board hash -> score
 	historyHeuristic   map[game.Point]int  // move -> score for ordering
+	weights            *Weights            // evaluation weights, nil for DefaultWeights
+	evaluator          Evaluator           // replaces the weighted evaluation if set
+	patterns           *pattern.Table      // pattern priors for move ordering, nil for none
+	time               *TimeManager        // time allocation, nil to search at a fixed depth
+	deadline           time.Time           // when a timed search must stop, zero for none
+	nodes              int                 // nodes searched, for checking the deadline
+	aborted            bool                // whether the search ran past the deadline
 }
 
 func NewAlphaBetaEngine() *AlphaBetaEngine {
@@ -21,12 +32,83 @@ func NewAlphaBetaEngine() *AlphaBetaEngine {
 	}
 }
 
+// NewAlphaBetaEngineWithWeights creates an AlphaBetaEngine that evaluates positions with w.
+func NewAlphaBetaEngineWithWeights(w Weights) *AlphaBetaEngine {
+	e := NewAlphaBetaEngine()
+	e.weights = &w
+	return e
+}
+
+// NewAlphaBetaEngineWithEvaluator creates an AlphaBetaEngine that evaluates positions with eval,
+// e.g. TerritoryEvaluator(DefaultTerritoryWeights()).
+func NewAlphaBetaEngineWithEvaluator(eval Evaluator) *AlphaBetaEngine {
+	e := NewAlphaBetaEngine()
+	e.evaluator = eval
+	return e
+}
+
+// SetPatterns makes the engine order moves by the pattern priors of t in
+// addition to its other heuristics.
+func (e *AlphaBetaEngine) SetPatterns(t *pattern.Table) {
+	e.patterns = t
+}
+
+// SetTimeManager makes the engine share its time by m. Without one, the
+// engine creates a TimeManager with the default settings when it is first
+// told its time.
+func (e *AlphaBetaEngine) SetTimeManager(m *TimeManager) {
+	e.time = m
+}
+
+// TimeSettings implements TimeSettingsAware.
+func (e *AlphaBetaEngine) TimeSettings(tc clock.TimeControl) {
+	if e.time == nil {
+		e.time = NewTimeManager()
+	}
+	e.time.TimeSettings(tc)
+}
+
+// TimeLeft implements TimeAware. Once told its time, the engine deepens
+// its search for as long as the TimeManager allows instead of searching
+// at a fixed depth.
+func (e *AlphaBetaEngine) TimeLeft(_ game.FieldState, remaining time.Duration, stones int) {
+	if e.time == nil {
+		e.time = NewTimeManager()
+	}
+	e.time.TimeLeft(remaining, stones)
+}
+
+// Weights returns the evaluation weights used by the engine.
+func (e *AlphaBetaEngine) Weights() Weights {
+	if e.weights == nil {
+		return DefaultWeights()
+	}
+	return *e.weights
+}
+
 // Move in AlphaBetaEngine uses alpha-beta pruning to select the best move or pass if no beneficial move exists.
 func (e *AlphaBetaEngine) Move(board game.Board, player game.FieldState, ko *game.Point) *game.Point {
+	scores := e.Analyze(board, player, ko)
+	// The pass score is always last.
+	passScore := scores[len(scores)-1].Score
 	bestScore := -1 << 30
 	var bestMove *game.Point
+	for _, ms := range scores[:len(scores)-1] {
+		if ms.Score > bestScore {
+			bestScore = ms.Score
+			bestMove = ms.Move
+		}
+	}
+	// Pass if no move found or if passing is as good or better than any move
+	if bestMove == nil || passScore >= bestScore {
+		return nil // pass
+	}
+	return bestMove
+}
+
+// Analyze returns the search score of every legal move followed by the score for passing.
+func (e *AlphaBetaEngine) Analyze(board game.Board, player game.FieldState, ko *game.Point) []MoveScore {
 	depth := 4 // Shallow for performance; increase for stronger player
-	moveFound := false
 
 	// Ensure killerMoves map is initialized
 	if e.killerMoves == nil {
@@ -39,51 +121,114 @@ func (e *AlphaBetaEngine) Move(board game.Board, player game.FieldState, ko *gam
 		e.historyHeuristic = make(map[game.Point]int)
 	}
 
+	// The search plays and takes back moves on a single position.
+	pos := position.FromBoard(board)
+	pos.SetKo(ko)
+	if e.time.Timed() {
+		return e.deepen(pos, player)
+	}
+	return e.searchRoot(pos, player, depth)
+}
+
+// maxSearchDepth bounds the iterative deepening of a timed search.
+const maxSearchDepth = 32
+
+// deepen searches pos at increasing depths until the time for the move is
+// used up, and returns the scores of the deepest search that finished.
+// The time is extended while the best move changes from one depth to the
+// next, and the search stops early once the best move stays the same and
+// leads the second best by the Dominance margin.
+func (e *AlphaBetaEngine) deepen(pos *position.Position, player game.FieldState) []MoveScore {
+	start := e.time.time()
+	target, limit := e.time.Allocate(emptyPoints(pos.Board()))
+	// The first depth always finishes, so that there is a move to play.
+	scores := e.searchRoot(pos, player, 1)
+	best, _ := bestTwo(scores)
+
+	e.deadline = start.Add(limit)
+	defer func() {
+		e.deadline, e.aborted = time.Time{}, false
+		e.time.Spent(e.time.time().Sub(start))
+	}()
+	for depth := 2; depth <= maxSearchDepth; depth++ {
+		// The next depth takes longer than all before it together.
+		if e.time.time().Sub(start) >= target/2 {
+			break
+		}
+		// Scores of shallower searches would cut the deeper one short.
+		e.transpositionTable = make(map[uint64]int)
+		next := e.searchRoot(pos, player, depth)
+		if e.aborted {
+			break
+		}
+		scores = next
+		nextBest, second := bestTwo(scores)
+		if !sameMove(scores[nextBest].Move, scores[best].Move) {
+			target = min(target+target/2, limit)
+		} else if second < 0 || scores[nextBest].Score-scores[second].Score >= e.time.Dominance {
+			break
+		}
+		best = nextBest
+	}
+	return scores
+}
+
+// bestTwo returns the indices of the best and the second best of scores,
+// -1 for the second if there is only one.
+func bestTwo(scores []MoveScore) (best, second int) {
+	best, second = 0, -1
+	for i := 1; i < len(scores); i++ {
+		switch {
+		case scores[i].Score > scores[best].Score:
+			best, second = i, best
+		case second < 0 || scores[i].Score > scores[second].Score:
+			second = i
+		}
+	}
+	return best, second
+}
+
+// emptyPoints returns the number of empty points on board.
+func emptyPoints(board game.Board) int {
+	n := 0
+	for i := range board {
+		for j := range board[i] {
+			if board[i][j] == game.Empty {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// sameMove reports whether a and b are the same move, nil being a pass.
+func sameMove(a, b *game.Point) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// searchRoot returns the score of every legal move searched to depth,
+// followed by the score for passing.
+func (e *AlphaBetaEngine) searchRoot(pos *position.Position, player game.FieldState, depth int) []MoveScore {
+	var scores []MoveScore
+	opp := opponent(player)
 	for i := int8(0); i < 9; i++ {
 		for j := int8(0); j < 9; j++ {
-			if board[i][j] != game.Empty {
-				continue
-			}
 			pt := game.Point{Row: i, Col: j}
-			if ko != nil && pt.Row == ko.Row && pt.Col == ko.Col {
+			if _, err := pos.Play(pt, player); err != nil {
 				continue
 			}
-			var nextBoard game.Board
-			copy(nextBoard[:], board[:])
-			nextBoard[pt.Row][pt.Col] = player
-			opp := game.Black
-			if player == game.Black {
-				opp = game.White
-			}
-			for _, n := range game.Neighbors(pt) {
-				if nextBoard[n.Row][n.Col] == opp {
-					group, libs := game.Group(nextBoard, n)
-					if len(libs) == 0 {
-						for stonePt := range group {
-							nextBoard[stonePt.Row][stonePt.Col] = game.Empty
-						}
-					}
-				}
-			}
-			_, libs := game.Group(nextBoard, pt)
-			if len(libs) == 0 {
-				continue
-			}
-			score := -e.alphaBeta(nextBoard, opp, player, ko, depth-1, -1<<30, 1<<30)
-			moveFound = true
-			if score > bestScore {
-				bestScore = score
-				move := pt
-				bestMove = &move
-			}
+			score := -e.alphaBeta(pos, opp, player, depth-1, -1<<30, 1<<30)
+			pos.Undo()
+			scores = append(scores, MoveScore{Move: &pt, Score: score})
 		}
 	}
-	// Pass if no move found or if passing is as good or better than any move
-	passScore := -e.alphaBeta(board, opponent(player), player, ko, depth-1, -1<<30, 1<<30)
-	if !moveFound || passScore >= bestScore {
-		return nil // pass
-	}
-	return bestMove
+	pos.Pass()
+	passScore := -e.alphaBeta(pos, opp, player, depth-1, -1<<30, 1<<30)
+	pos.Undo()
+	return append(scores, MoveScore{Move: nil, Score: passScore})
 }
 
 // opponent returns the opposite FieldState (Black <-> White).
@@ -95,21 +240,31 @@ func opponent(player game.FieldState) game.FieldState {
 }
 
 // alphaBeta is a minimax search with alpha-beta pruning, killer move heuristic, transposition table, and history heuristic.
-func (e *AlphaBetaEngine) alphaBeta(board game.Board, player, opp game.FieldState, ko *game.Point, depth, alpha, beta int) int {
+// Moves are played on pos and taken back before returning; pos tracks the ko point.
+func (e *AlphaBetaEngine) alphaBeta(pos *position.Position, player, opp game.FieldState, depth, alpha, beta int) int {
+	if e.expired() {
+		return 0
+	}
+	board := pos.Board()
 	if depth == 0 {
-		return evaluate(board, player, opp)
+		return e.evaluate(board, player, opp)
 	}
 	foundMove := false
 
 	// Transposition table lookup
-	boardHash := boardHash(board, player)
+	boardHash := positionHash(pos, player)
 	if val, ok := e.transpositionTable[boardHash]; ok {
 		return val
 	}
 
 	// Null Move Pruning: try skipping a move (pass) if depth is sufficient
 	if depth >= 2 {
-		passScore := -e.alphaBeta(board, opp, player, ko, depth-2, -beta, -beta+1)
+		pos.Pass()
+		passScore := -e.alphaBeta(pos, opp, player, depth-2, -beta, -beta+1)
+		pos.Undo()
+		if e.aborted {
+			return 0
+		}
 		if passScore >= beta {
 			e.transpositionTable[boardHash] = passScore
 			return passScore
@@ -117,71 +272,43 @@ func (e *AlphaBetaEngine) alphaBeta(board game.Board, player, opp game.FieldStat
 	}
 
 	// Try killer move first if available
-	if killer, ok := e.killerMoves[depth]; ok && killer != nil && board[killer.Row][killer.Col] == game.Empty {
+	if killer, ok := e.killerMoves[depth]; ok && killer != nil {
 		pt := *killer
-		if ko == nil || pt.Row != ko.Row || pt.Col != ko.Col {
-			var nextBoard game.Board
-			copy(nextBoard[:], board[:])
-			nextBoard[pt.Row][pt.Col] = player
-			for _, n := range game.Neighbors(pt) {
-				if nextBoard[n.Row][n.Col] == opp {
-					group, libs := game.Group(nextBoard, n)
-					if len(libs) == 0 {
-						for stonePt := range group {
-							nextBoard[stonePt.Row][stonePt.Col] = game.Empty
-						}
-					}
-				}
+		if _, err := pos.Play(pt, player); err == nil {
+			foundMove = true
+			score := -e.alphaBeta(pos, opp, player, depth-1, -beta, -alpha)
+			pos.Undo()
+			if e.aborted {
+				return 0
 			}
-			_, libs := game.Group(nextBoard, pt)
-			if len(libs) != 0 {
-				foundMove = true
-				score := -e.alphaBeta(nextBoard, opp, player, ko, depth-1, -beta, -alpha)
-				// History heuristic update
-				e.historyHeuristic[pt] += 1 << uint(depth)
-				if score > alpha {
-					alpha = score
-					// Update killer move if this move caused a beta cutoff
-					if alpha >= beta {
-						e.killerMoves[depth] = &pt
-						e.transpositionTable[boardHash] = alpha
-						return alpha
-					}
+			// History heuristic update
+			e.historyHeuristic[pt] += 1 << uint(depth)
+			if score > alpha {
+				alpha = score
+				// Update killer move if this move caused a beta cutoff
+				if alpha >= beta {
+					e.killerMoves[depth] = &pt
+					e.transpositionTable[boardHash] = alpha
+					return alpha
 				}
 			}
 		}
 	}
 
-	for _, pt := range e.orderedMoves(board, player, depth) {
-		if board[pt.Row][pt.Col] != game.Empty {
-			continue
-		}
-		if ko != nil && pt.Row == ko.Row && pt.Col == ko.Col {
-			continue
-		}
+	for _, pt := range e.orderedMoves(pos, player, depth) {
 		// Skip killer move (already tried)
 		if killer, ok := e.killerMoves[depth]; ok && killer != nil && pt.Row == killer.Row && pt.Col == killer.Col {
 			continue
 		}
-		var nextBoard game.Board
-		copy(nextBoard[:], board[:])
-		nextBoard[pt.Row][pt.Col] = player
-		for _, n := range game.Neighbors(pt) {
-			if nextBoard[n.Row][n.Col] == opp {
-				group, libs := game.Group(nextBoard, n)
-				if len(libs) == 0 {
-					for stonePt := range group {
-						nextBoard[stonePt.Row][stonePt.Col] = game.Empty
-					}
-				}
-			}
-		}
-		_, libs := game.Group(nextBoard, pt)
-		if len(libs) == 0 {
+		if _, err := pos.Play(pt, player); err != nil {
 			continue
 		}
 		foundMove = true
-		score := -e.alphaBeta(nextBoard, opp, player, ko, depth-1, -beta, -alpha)
+		score := -e.alphaBeta(pos, opp, player, depth-1, -beta, -alpha)
+		pos.Undo()
+		if e.aborted {
+			return 0
+		}
 		// History heuristic update
 		e.historyHeuristic[pt] += 1 << uint(depth)
 		if score > alpha {
@@ -196,7 +323,12 @@ func (e *AlphaBetaEngine) alphaBeta(board game.Board, player, opp game.FieldStat
 		}
 	}
 	// Consider passing if no move found or passing is better
-	passScore := -e.alphaBeta(board, opp, player, ko, depth-1, -beta, -alpha)
+	pos.Pass()
+	passScore := -e.alphaBeta(pos, opp, player, depth-1, -beta, -alpha)
+	pos.Undo()
+	if e.aborted {
+		return 0
+	}
 	if !foundMove || passScore > alpha {
 		alpha = passScore
 	}
@@ -204,14 +336,36 @@ func (e *AlphaBetaEngine) alphaBeta(board game.Board, player, opp game.FieldStat
 	return alpha
 }
 
+// expired reports whether a timed search has run past its deadline. The
+// clock is only read every deadlineCheckNodes nodes.
+func (e *AlphaBetaEngine) expired() bool {
+	if e.aborted || e.deadline.IsZero() {
+		return e.aborted
+	}
+	e.nodes++
+	if e.nodes%deadlineCheckNodes == 0 && !e.time.time().Before(e.deadline) {
+		e.aborted = true
+	}
+	return e.aborted
+}
+
+// deadlineCheckNodes is the number of nodes between two readings of the clock.
+const deadlineCheckNodes = 256
+
 // orderedMoves returns a list of all empty points, ordered by killer move, history heuristic, proximity, and capture potential.
-func (e *AlphaBetaEngine) orderedMoves(board game.Board, player game.FieldState, depth int) []game.Point {
+func (e *AlphaBetaEngine) orderedMoves(pos *position.Position, player game.FieldState, depth int) []game.Point {
+	board := pos.Board()
 	type moveScore struct {
 		pt    game.Point
 		score int
 	}
 	var moves []moveScore
 	killer, hasKiller := e.killerMoves[depth]
+	// Ladder reading is too expensive near the leaves.
+	var ladders map[game.Point]bool
+	if depth >= ladderOrderingDepth {
+		ladders = ladderCaptures(board, player)
+	}
 	for i := int8(0); i < 9; i++ {
 		for j := int8(0); j < 9; j++ {
 			if board[i][j] != game.Empty {
@@ -237,13 +391,18 @@ func (e *AlphaBetaEngine) orderedMoves(board game.Board, player game.FieldState,
 				opp = game.White
 			}
 			for _, n := range game.Neighbors(pt) {
-				if board[n.Row][n.Col] == opp {
-					_, libs := game.Group(board, n)
-					if len(libs) == 1 {
-						score += 5
-					}
+				if board[n.Row][n.Col] == opp && pos.Liberties(n) == 1 {
+					score += 5
 				}
 			}
+			// Ladder: +8 for an atari that captures in a ladder
+			if ladders[pt] {
+				score += 8
+			}
+			// Patterns: +2 per doubling of the prior
+			if e.patterns != nil {
+				score += pattern.Bonus(e.patterns.Prior(board, pt, player))
+			}
 			moves = append(moves, moveScore{pt, score})
 		}
 	}
@@ -258,57 +417,45 @@ func (e *AlphaBetaEngine) orderedMoves(board game.Board, player game.FieldState,
 	return result
 }
 
-// evaluate is a sophisticated evaluation function considering liberties, groups, and captures.
-func evaluate(board game.Board, player, opp game.FieldState) int {
-	playerStones, oppStones := 0, 0
-	playerLibs, oppLibs := 0, 0
-	playerGroups, oppGroups := 0, 0
-	playerCapturable, oppCapturable := 0, 0
+// ladderOrderingDepth is the minimum remaining depth at which orderedMoves reads ladders.
+const ladderOrderingDepth = 3
 
+// ladderCaptures returns the ataris with which player captures an opponent
+// chain with two liberties in a ladder.
+func ladderCaptures(board game.Board, player game.FieldState) map[game.Point]bool {
+	moves := make(map[game.Point]bool)
 	visited := make(map[game.Point]bool)
-	for i := 0; i < 9; i++ {
-		for j := 0; j < 9; j++ {
-			pt := game.Point{Row: int8(i), Col: int8(j)}
-			if visited[pt] || board[i][j] == game.Empty {
+	for i := int8(0); i < 9; i++ {
+		for j := int8(0); j < 9; j++ {
+			pt := game.Point{Row: i, Col: j}
+			if visited[pt] || board[i][j] == game.Empty || board[i][j] == player {
 				continue
 			}
 			group, libs := game.Group(board, pt)
 			for stone := range group {
 				visited[stone] = true
 			}
-			if board[i][j] == player {
-				playerStones += len(group)
-				playerLibs += len(libs)
-				playerGroups++
-				if len(libs) == 1 {
-					playerCapturable += len(group)
-				}
-			} else if board[i][j] == opp {
-				oppStones += len(group)
-				oppLibs += len(libs)
-				oppGroups++
-				if len(libs) == 1 {
-					oppCapturable += len(group)
+			if len(libs) == 2 {
+				if move := game.LadderAttack(board, pt); move != nil {
+					moves[*move] = true
 				}
 			}
 		}
 	}
-	// Weighted sum: stones, liberties, groups, capturability
-	return (playerStones-oppStones)*10 +
-		(playerLibs-oppLibs)*2 +
-		(oppCapturable-playerCapturable)*8 +
-		(playerGroups - oppGroups)
+	return moves
 }
 
-// boardHash returns a simple hash for the board and player.
-// You may want to replace this with Zobrist hashing for better collision resistance.
-func boardHash(board game.Board, player game.FieldState) uint64 {
-	var h uint64
-	for i := 0; i < 9; i++ {
-		for j := 0; j < 9; j++ {
-			h = h*3 + uint64(board[i][j])
-		}
+// evaluate is a sophisticated evaluation function considering liberties, groups, and captures.
+func (e *AlphaBetaEngine) evaluate(board game.Board, player, opp game.FieldState) int {
+	if e.evaluator != nil {
+		return e.evaluator(board, player, opp)
 	}
-	h = h*3 + uint64(player)
-	return h
+	// Weighted sum: stones, liberties, groups, capturability
+	return e.Weights().Evaluate(ExtractFeatures(board, player, opp))
+}
+
+// positionHash returns the key of pos with player to move in the
+// transposition table. Rotated and mirrored positions share their entry.
+func positionHash(pos *position.Position, player game.FieldState) uint64 {
+	return pos.Hash() ^ uint64(player)*0x9e3779b97f4a7c15
 }
@@ -2,6 +2,9 @@
 package engine
 
 import (
+	"time"
+
+	"github.com/RubikNube/GoInGo/pkg/clock"
 	"github.com/RubikNube/GoInGo/pkg/game"
 )
 
@@ -10,3 +13,31 @@ type Engine interface {
 	// Move returns the next move as a Point, or nil if passing.
 	Move(board game.Board, player game.FieldState, ko *game.Point) *game.Point
 }
+
+// MoveScore is a candidate move together with the score an engine assigns to
+// it. Move is nil for a pass.
+type MoveScore struct {
+	Move  *game.Point
+	Score int
+}
+
+// Analyzer is implemented by engines that can score every candidate move.
+// Scores are from the perspective of the player to move; higher is better.
+type Analyzer interface {
+	Analyze(board game.Board, player game.FieldState, ko *game.Point) []MoveScore
+}
+
+// TimeAware is implemented by engines that take the time left on their
+// clock into account. TimeLeft is called before Move, like GTP's time_left
+// command: stones is the number of moves to play within remaining in
+// overtime, and 0 while in main time.
+type TimeAware interface {
+	TimeLeft(player game.FieldState, remaining time.Duration, stones int)
+}
+
+// TimeSettingsAware is implemented by TimeAware engines that also take the
+// time control of the game into account. TimeSettings is called before
+// TimeLeft, like GTP's time_settings, and may be called before every move.
+type TimeSettingsAware interface {
+	TimeSettings(tc clock.TimeControl)
+}
@@ -0,0 +1,37 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/position"
+)
+
+func TestOrderedMovesPrefersLadderCapture(t *testing.T) {
+	board := EmptyBoard()
+	board[4][4] = game.White
+	board[3][4], board[4][3], board[5][5] = game.Black, game.Black, game.Black
+	ladder := game.LadderAttack(board, game.Point{Row: 4, Col: 4})
+	if ladder == nil {
+		t.Fatal("Expected a working ladder in the test position")
+	}
+	e := NewAlphaBetaEngine()
+	moves := e.orderedMoves(position.FromBoard(board), game.Black, ladderOrderingDepth)
+	if moves[0] != *ladder {
+		t.Errorf("Expected ladder atari %+v first, got %+v", *ladder, moves[0])
+	}
+}
+
+func TestTerritoryEvaluatorPenalisesLadder(t *testing.T) {
+	// White to move with a stone in atari that a ladder captures.
+	board := EmptyBoard()
+	board[4][4] = game.White
+	board[3][4], board[4][3], board[4][5], board[5][5] = game.Black, game.Black, game.Black, game.Black
+	w := DefaultTerritoryWeights()
+	withLadder := TerritoryEvaluator(w)(board, game.White, game.Black)
+	w.Ladder = 0
+	withoutLadder := TerritoryEvaluator(w)(board, game.White, game.Black)
+	if withLadder >= withoutLadder {
+		t.Errorf("Expected the ladder to lower White's evaluation, got %d and %d", withLadder, withoutLadder)
+	}
+}
@@ -5,59 +5,37 @@ import (
 	"time"
 
 	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/pattern"
 )
 
 // RandomEngine implements Engine by picking a random legal move.
-type RandomEngine struct{}
+type RandomEngine struct {
+	patterns *pattern.Table // weights the moves if set
+	rng      *rand.Rand
+}
 
 // NewRandomEngine creates a new instance of RandomEngine.
 func NewRandomEngine() *RandomEngine {
 	return &RandomEngine{}
 }
 
+// NewSmartRandomEngine creates a RandomEngine that picks moves with
+// probability proportional to their pattern priors and does not fill its
+// own eyes, like the playout policy of a Monte Carlo search.
+func NewSmartRandomEngine(t *pattern.Table) *RandomEngine {
+	return &RandomEngine{patterns: t, rng: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
 func (e *RandomEngine) Move(board game.Board, player game.FieldState, ko *game.Point) *game.Point {
-	empty := []game.Point{}
-	for i := int8(0); i < 9; i++ {
-		for j := int8(0); j < 9; j++ {
-			if board[i][j] == game.Empty {
-				empty = append(empty, game.Point{Row: int8(i), Col: int8(j)})
-			}
-		}
+	if e.patterns != nil {
+		return e.patterns.PlayoutMove(board, player, ko, e.rng)
 	}
-	rand.Seed(time.Now().UnixNano())
-	perm := rand.Perm(len(empty))
-	for _, idx := range perm {
-		pt := empty[idx]
-		// Ko rule
-		if ko != nil && pt.Row == ko.Row && pt.Col == ko.Col {
-			continue
-		}
-		var nextBoard game.Board
-		copy(nextBoard[:], board[:])
-		nextBoard[pt.Row][pt.Col] = player
-		opp := game.Black
-		if player == game.Black {
-			opp = game.White
-		}
-		captured := []game.Point{}
-		for _, n := range game.Neighbors(pt) {
-			if nextBoard[n.Row][n.Col] == opp {
-				group, libs := game.Group(nextBoard, n)
-				if len(libs) == 0 {
-					for stonePt := range group {
-						nextBoard[stonePt.Row][stonePt.Col] = game.Empty
-						captured = append(captured, stonePt)
-					}
-				}
-			}
-		}
-		_, libs := game.Group(nextBoard, pt)
-		if len(libs) == 0 {
-			continue
-		}
-		// Legal move found
-		return &pt
+	legal := game.LegalMoves(game.State{Board: board, ToMove: player, Ko: ko})
+	if len(legal) == 0 {
+		// No legal move, pass
+		return nil
 	}
-	// No legal move, pass
-	return nil
+	rand.Seed(time.Now().UnixNano())
+	pt := legal[rand.Intn(len(legal))]
+	return &pt
 }
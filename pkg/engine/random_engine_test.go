@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/pattern"
 )
 
 func TestRandomEngine_MoveReturnsLegalMove(t *testing.T) {
@@ -38,3 +39,21 @@ func TestRandomEngine_MoveReturnsNilWhenNoMoves(t *testing.T) {
 		t.Errorf("Expected nil (pass), got %+v", move)
 	}
 }
+
+func TestSmartRandomEngine_PrefersHighPriors(t *testing.T) {
+	board := game.Board{}
+	board[4][4] = game.White
+	// Only the pattern of (4,5) is worth playing.
+	table := &pattern.Table{Weights: map[pattern.Code]float64{}}
+	for i := int8(0); i < 9; i++ {
+		for j := int8(0); j < 9; j++ {
+			table.Weights[pattern.At(board, game.Point{Row: i, Col: j}, game.Black)] = 1e-9
+		}
+	}
+	table.Weights[pattern.At(board, game.Point{Row: 4, Col: 5}, game.Black)] = 1
+	engine := NewSmartRandomEngine(table)
+	move := engine.Move(board, game.Black, nil)
+	if move == nil || board[move.Row][move.Col] != game.Empty || (move.Row != 4 && move.Col != 4) {
+		t.Errorf("Expected a move next to the white stone, got %+v", move)
+	}
+}
@@ -0,0 +1,271 @@
+package engine
+
+import (
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+// Evaluator scores a board from the perspective of player; positive values
+// favour player.
+type Evaluator func(board game.Board, player, opp game.FieldState) int
+
+// Evaluator returns the classic evaluation with weights w.
+func (w Weights) Evaluator() Evaluator {
+	return func(board game.Board, player, opp game.FieldState) int {
+		return w.Evaluate(ExtractFeatures(board, player, opp))
+	}
+}
+
+// TerritoryWeights are the coefficients of the territory evaluation.
+type TerritoryWeights struct {
+	Stones    int `json:"stones"`    // per stone on the board
+	Territory int `json:"territory"` // per empty point controlled
+	Eyes      int `json:"eyes"`      // per true eye, at most two per group
+	Atari     int `json:"atari"`     // penalty per own stone in atari
+	Capture   int `json:"capture"`   // bonus per opponent stone in atari
+	Ladder    int `json:"ladder"`    // extra penalty per own stone in atari that a ladder captures
+}
+
+// DefaultTerritoryWeights returns the weights of the territory evaluation.
+// Stones and territory count equally as in area scoring.
+func DefaultTerritoryWeights() TerritoryWeights {
+	return TerritoryWeights{Stones: 10, Territory: 10, Eyes: 6, Atari: 6, Capture: 8, Ladder: 10}
+}
+
+// TerritoryEvaluator returns an evaluation that estimates the area score
+// with Bouzy's 5/21 dilation-erosion algorithm and adds terms for eyes and
+// groups in atari. The player to move can capture opponent stones in atari,
+// so those weigh more than the own stones in atari, unless a ladder
+// captures them anyway.
+func TerritoryEvaluator(w TerritoryWeights) Evaluator {
+	return func(board game.Board, player, opp game.FieldState) int {
+		influence := BouzyInfluence(board)
+		var stones, territory [3]int
+		for i := 0; i < game.BoardSize; i++ {
+			for j := 0; j < game.BoardSize; j++ {
+				switch {
+				case board[i][j] != game.Empty:
+					stones[board[i][j]]++
+				case influence[i][j] > 0:
+					territory[game.Black]++
+				case influence[i][j] < 0:
+					territory[game.White]++
+				}
+			}
+		}
+		eyes, atari, doomed := chainStats(board, player)
+		return (stones[player]-stones[opp])*w.Stones +
+			(territory[player]-territory[opp])*w.Territory +
+			(eyes[player]-eyes[opp])*w.Eyes -
+			atari[player]*w.Atari -
+			doomed*w.Ladder +
+			atari[opp]*w.Capture
+	}
+}
+
+// BouzyInfluence runs Bouzy's 5/21 algorithm: black stones start at +128
+// and white stones at -128, followed by 5 dilations and 21 erosions.
+// Positive values mark black territory, negative values white territory.
+func BouzyInfluence(board game.Board) [game.BoardSize][game.BoardSize]int {
+	var v influenceMap
+	for i := 0; i < game.BoardSize; i++ {
+		for j := 0; j < game.BoardSize; j++ {
+			switch board[i][j] {
+			case game.Black:
+				v[influenceIndex(i, j)] = 128
+			case game.White:
+				v[influenceIndex(i, j)] = -128
+			}
+		}
+	}
+	var next influenceMap
+	for n := 0; n < 5; n++ {
+		dilate(&v, &next)
+		v, next = next, v
+	}
+	for n := 0; n < 21; n++ {
+		erode(&v, &next)
+		if next == v {
+			// Erosion has reached a fixed point.
+			break
+		}
+		v, next = next, v
+	}
+	var result [game.BoardSize][game.BoardSize]int
+	for i := 0; i < game.BoardSize; i++ {
+		for j := 0; j < game.BoardSize; j++ {
+			result[i][j] = int(v[influenceIndex(i, j)])
+		}
+	}
+	return result
+}
+
+// influenceStride is the row length of an influenceMap, which has a border
+// of always-zero points around the board so that neighbours need no bounds
+// checks. A zero neighbour counts as neither black nor white.
+const influenceStride = game.BoardSize + 2
+
+// influenceMap holds one influence value per point including the border.
+type influenceMap [influenceStride * influenceStride]int32
+
+func influenceIndex(row, col int) int {
+	return (row+1)*influenceStride + col + 1
+}
+
+// onBoardNeighbors holds the number of neighbours of every point of an influenceMap.
+var onBoardNeighbors = func() (t influenceMap) {
+	for i := int8(0); i < game.BoardSize; i++ {
+		for j := int8(0); j < game.BoardSize; j++ {
+			t[influenceIndex(int(i), int(j))] = int32(len(game.Neighbors(game.Point{Row: i, Col: j})))
+		}
+	}
+	return t
+}()
+
+// signCounts returns the number of positive and negative neighbours of index i.
+func signCounts(v *influenceMap, i int) (pos, neg int32) {
+	countSign(v[i-1], &pos, &neg)
+	countSign(v[i+1], &pos, &neg)
+	countSign(v[i-influenceStride], &pos, &neg)
+	countSign(v[i+influenceStride], &pos, &neg)
+	return pos, neg
+}
+
+func countSign(y int32, pos, neg *int32) {
+	if y > 0 {
+		*pos++
+	} else if y < 0 {
+		*neg++
+	}
+}
+
+// dilate adds to every non-negative point not touching a negative point the
+// number of positive neighbours, and symmetrically for negative points.
+func dilate(v, next *influenceMap) {
+	for row := 0; row < game.BoardSize; row++ {
+		for i := influenceIndex(row, 0); i <= influenceIndex(row, game.BoardSize-1); i++ {
+			x := v[i]
+			pos, neg := signCounts(v, i)
+			if x >= 0 && neg == 0 {
+				x += pos
+			}
+			if x <= 0 && pos == 0 {
+				x -= neg
+			}
+			next[i] = x
+		}
+	}
+}
+
+// erode moves every point towards zero by the number of neighbours that do
+// not share its sign, without crossing zero.
+func erode(v, next *influenceMap) {
+	for row := 0; row < game.BoardSize; row++ {
+		for i := influenceIndex(row, 0); i <= influenceIndex(row, game.BoardSize-1); i++ {
+			x := v[i]
+			pos, neg := signCounts(v, i)
+			switch {
+			case x > 0:
+				x = max(x-(onBoardNeighbors[i]-pos), 0)
+			case x < 0:
+				x = min(x+(onBoardNeighbors[i]-neg), 0)
+			}
+			next[i] = x
+		}
+	}
+}
+
+// neighborIndex lists the neighbours of every point as row-major indices.
+var neighborIndex = func() (t [game.BoardSize * game.BoardSize][]int) {
+	for i := int8(0); i < game.BoardSize; i++ {
+		for j := int8(0); j < game.BoardSize; j++ {
+			for _, n := range game.Neighbors(game.Point{Row: i, Col: j}) {
+				t[int(i)*game.BoardSize+int(j)] = append(t[int(i)*game.BoardSize+int(j)], int(n.Row)*game.BoardSize+int(n.Col))
+			}
+		}
+	}
+	return t
+}()
+
+// chainStats returns, per colour, the number of true eyes (at most two per
+// chain) and the number of stones in atari, and the number of stones of
+// toMove in atari that cannot escape a ladder. It works on flat arrays
+// rather than game.Group because it runs at every leaf of the search.
+func chainStats(board game.Board, toMove game.FieldState) (eyes, atari [3]int, doomed int) {
+	const n = game.BoardSize * game.BoardSize
+	var chain [n]int   // chain number + 1 of every stone
+	var libSeen [n]int // chain number + 1 that last counted this liberty
+	var stack [n]int
+	id := 0
+	for start := 0; start < n; start++ {
+		color := board[start/game.BoardSize][start%game.BoardSize]
+		if color == game.Empty || chain[start] != 0 {
+			continue
+		}
+		id++
+		chain[start] = id
+		stack[0] = start
+		top, size, libs, chainEyes := 1, 0, 0, 0
+		for top > 0 {
+			top--
+			p := stack[top]
+			size++
+			for _, q := range neighborIndex[p] {
+				switch board[q/game.BoardSize][q%game.BoardSize] {
+				case game.Empty:
+					if libSeen[q] != id {
+						libSeen[q] = id
+						libs++
+						if IsTrueEye(board, game.Point{Row: int8(q / game.BoardSize), Col: int8(q % game.BoardSize)}, color) {
+							chainEyes++
+						}
+					}
+				case color:
+					if chain[q] == 0 {
+						chain[q] = id
+						stack[top] = q
+						top++
+					}
+				}
+			}
+		}
+		eyes[color] += min(chainEyes, 2)
+		if libs == 1 {
+			atari[color] += size
+			if color == toMove && game.LadderCaptured(board, game.Point{Row: int8(start / game.BoardSize), Col: int8(start % game.BoardSize)}) {
+				doomed += size
+			}
+		}
+	}
+	return eyes, atari, doomed
+}
+
+// IsTrueEye reports whether the empty point p is a true eye of color.
+func IsTrueEye(board game.Board, p game.Point, color game.FieldState) bool {
+	if board[p.Row][p.Col] != game.Empty || color == game.Empty {
+		return false
+	}
+	for _, q := range neighborIndex[int(p.Row)*game.BoardSize+int(p.Col)] {
+		if board[q/game.BoardSize][q%game.BoardSize] != color {
+			return false
+		}
+	}
+	opp := game.Black
+	if color == game.Black {
+		opp = game.White
+	}
+	onBoard, hostile := 0, 0
+	for _, d := range [4][2]int8{{-1, -1}, {-1, 1}, {1, -1}, {1, 1}} {
+		r, c := p.Row+d[0], p.Col+d[1]
+		if r < 0 || r >= game.BoardSize || c < 0 || c >= game.BoardSize {
+			continue
+		}
+		onBoard++
+		if board[r][c] == opp {
+			hostile++
+		}
+	}
+	if onBoard < 4 {
+		return hostile == 0
+	}
+	return hostile < 2
+}
@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+func TestBouzyInfluenceEmptyBoard(t *testing.T) {
+	v := BouzyInfluence(EmptyBoard())
+	for i := range v {
+		for j := range v[i] {
+			if v[i][j] != 0 {
+				t.Fatalf("Expected no influence on empty board, got %d at (%d,%d)", v[i][j], i, j)
+			}
+		}
+	}
+}
+
+func TestBouzyInfluenceWalls(t *testing.T) {
+	b := EmptyBoard()
+	for i := 0; i < game.BoardSize; i++ {
+		b[i][2] = game.Black
+		b[i][6] = game.White
+	}
+	v := BouzyInfluence(b)
+	for i := 0; i < game.BoardSize; i++ {
+		if v[i][0] <= 0 || v[i][1] <= 0 {
+			t.Errorf("Expected black territory left of the black wall in row %d, got %v", i, v[i])
+		}
+		if v[i][7] >= 0 || v[i][8] >= 0 {
+			t.Errorf("Expected white territory right of the white wall in row %d, got %v", i, v[i])
+		}
+		if v[i][4] != 0 {
+			t.Errorf("Expected neutral centre in row %d, got %d", i, v[i][4])
+		}
+	}
+}
+
+func TestIsTrueEye(t *testing.T) {
+	b := EmptyBoard()
+	// Corner eye at (0,0)
+	b[0][1], b[1][0], b[1][1] = game.Black, game.Black, game.Black
+	if !IsTrueEye(b, game.Point{Row: 0, Col: 0}, game.Black) {
+		t.Error("Expected corner eye to be true")
+	}
+	b[1][1] = game.White
+	if IsTrueEye(b, game.Point{Row: 0, Col: 0}, game.Black) {
+		t.Error("Expected corner eye with hostile diagonal to be false")
+	}
+	// Centre eye at (4,4) with one hostile diagonal is still true.
+	for _, n := range game.Neighbors(game.Point{Row: 4, Col: 4}) {
+		b[n.Row][n.Col] = game.White
+	}
+	b[3][3] = game.Black
+	if !IsTrueEye(b, game.Point{Row: 4, Col: 4}, game.White) {
+		t.Error("Expected centre eye with one hostile diagonal to be true")
+	}
+	b[5][5] = game.Black
+	if IsTrueEye(b, game.Point{Row: 4, Col: 4}, game.White) {
+		t.Error("Expected centre eye with two hostile diagonals to be false")
+	}
+}
+
+func TestTerritoryEvaluatorCountsTerritory(t *testing.T) {
+	eval := TerritoryEvaluator(DefaultTerritoryWeights())
+	b := EmptyBoard()
+	for i := 0; i < game.BoardSize; i++ {
+		b[i][2] = game.Black
+		b[i][6] = game.White
+	}
+	if got := eval(b, game.Black, game.White); got != 0 {
+		t.Errorf("Expected even position, got %d", got)
+	}
+	b[4][5] = game.Black
+	black, white := eval(b, game.Black, game.White), eval(b, game.White, game.Black)
+	if black <= 0 || black != -white {
+		t.Errorf("Expected symmetric advantage for Black, got %d and %d", black, white)
+	}
+}
+
+func TestTerritoryEvaluatorEngineDoesNotPassEarly(t *testing.T) {
+	// Both sides have a wall; the centre is still open.
+	b := EmptyBoard()
+	for i := 0; i < game.BoardSize; i++ {
+		b[i][2] = game.Black
+		b[i][6] = game.White
+	}
+	e := NewAlphaBetaEngineWithEvaluator(TerritoryEvaluator(DefaultTerritoryWeights()))
+	move := e.Move(b, game.Black, nil)
+	if move == nil {
+		t.Fatal("Expected a move, got pass")
+	}
+	if b[move.Row][move.Col] != game.Empty {
+		t.Errorf("Expected a move on an empty point, got %+v", move)
+	}
+}
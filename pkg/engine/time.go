@@ -0,0 +1,153 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/RubikNube/GoInGo/pkg/clock"
+)
+
+// TimeManager decides how long an engine thinks about a move. It is told
+// the time control and the time left like a GTP engine, and gives every
+// move a target time, which a search may stretch up to a maximum while it
+// is unsure about the best move. Since time_left reports byo-yomi as one
+// stone in the current period, the manager counts the periods left itself
+// from the time control and the time its moves took.
+//
+// The zero value has no time and leaves the engine to search at its fixed
+// depth.
+type TimeManager struct {
+	// Overhead is kept in reserve on every move for the time the move
+	// takes to reach the clock.
+	Overhead time.Duration
+	// Dominance is the score by which the best move must lead the second
+	// best, after it was best at the depth before, for the search to stop
+	// early.
+	Dominance int
+
+	control   *clock.TimeControl
+	remaining time.Duration
+	stones    int
+	known     bool // whether remaining and stones were set
+	moves     int  // moves allocated since the time control was set
+	periods   int  // byo-yomi periods left, the current one included
+	now       func() time.Time
+}
+
+// Default TimeManager settings.
+const (
+	DefaultOverhead  = 50 * time.Millisecond
+	DefaultDominance = 30 // three stones
+)
+
+// minMovesToGo is the least number of own moves the main time is shared by.
+const minMovesToGo = 10
+
+// openingMoves is the number of moves that get half their time, since
+// reading deep in an empty position gains little.
+const openingMoves = 4
+
+// NewTimeManager returns a TimeManager with the default settings.
+func NewTimeManager() *TimeManager {
+	return &TimeManager{Overhead: DefaultOverhead, Dominance: DefaultDominance}
+}
+
+// TimeSettings sets the time control. The move and period counts start
+// over when it changes.
+func (m *TimeManager) TimeSettings(tc clock.TimeControl) {
+	if m.control == nil || *m.control != tc {
+		m.moves, m.periods = 0, tc.Periods
+	}
+	m.control = &tc
+}
+
+// TimeLeft sets the time left for the next move, with stones as for
+// TimeAware.
+func (m *TimeManager) TimeLeft(remaining time.Duration, stones int) {
+	m.remaining, m.stones, m.known = remaining, stones, true
+}
+
+// Spent records that the move after the last TimeLeft took d, and counts
+// the byo-yomi periods it used up.
+func (m *TimeManager) Spent(d time.Duration) {
+	if m.control == nil || m.control.System != clock.ByoYomi || m.control.Period <= 0 || !m.known {
+		return
+	}
+	over := d - m.remaining
+	if over < 0 {
+		return
+	}
+	lost := int(over / m.control.Period)
+	if m.stones > 0 {
+		// The current period is lost once it runs out.
+		lost++
+	}
+	m.periods = max(m.periods-lost, 1)
+}
+
+// Timed reports whether the manager was told the time left.
+func (m *TimeManager) Timed() bool {
+	return m != nil && m.known
+}
+
+// Allocate returns the target and the time limit for the next move on a
+// board with empty empty points, and counts the move.
+func (m *TimeManager) Allocate(empty int) (target, limit time.Duration) {
+	target, limit = m.allocate(empty)
+	if m.moves < openingMoves {
+		target /= 2
+	}
+	m.moves++
+	return min(target, limit), limit
+}
+
+func (m *TimeManager) allocate(empty int) (target, limit time.Duration) {
+	var tc clock.TimeControl
+	if m.control != nil {
+		tc = *m.control
+	}
+	remaining := m.remaining - m.Overhead
+	if remaining <= 0 {
+		return 0, 0
+	}
+
+	switch {
+	case m.stones > 0 && tc.System == clock.ByoYomi:
+		// A period is only lost when it runs out, so most of it may be
+		// used. With periods to spare, a hard move may use up one.
+		if m.periods >= 3 {
+			return remaining * 7 / 10, remaining + tc.Period/2
+		}
+		return remaining / 2, remaining * 9 / 10
+	case m.stones > 0:
+		// Canadian overtime, or byo-yomi without the time control.
+		target = remaining / time.Duration(m.stones)
+		return target, min(2*target, remaining*9/10)
+	}
+
+	// In main time each player fills about a third of the empty points
+	// before the game ends. Time gained on every move is spent as it
+	// comes; the overtime after the main time may be used right away.
+	movesToGo := max(empty/3, minMovesToGo)
+	var gain, overtime time.Duration
+	switch tc.System {
+	case clock.Fischer:
+		gain = tc.Increment
+	case clock.ByoYomi:
+		gain, overtime = tc.Period, tc.Period
+	case clock.Canadian:
+		if tc.Stones > 0 {
+			gain = tc.Period / time.Duration(tc.Stones)
+			overtime = gain
+		}
+	}
+	target = remaining/time.Duration(movesToGo) + gain*3/4
+	return target, min(3*target, remaining/4+overtime*3/4)
+}
+
+// time returns the current time.
+func (m *TimeManager) time() time.Time {
+	if m.now == nil {
+		return time.Now()
+	}
+	return m.now()
+}
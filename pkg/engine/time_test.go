@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RubikNube/GoInGo/pkg/clock"
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+// allocate returns the allocation for a move after the opening.
+func allocate(tc clock.TimeControl, remaining time.Duration, stones, empty int) (target, limit time.Duration) {
+	m := NewTimeManager()
+	m.TimeSettings(tc)
+	m.TimeLeft(remaining, stones)
+	m.moves = openingMoves
+	return m.Allocate(empty)
+}
+
+func TestTimeManagerAllocate(t *testing.T) {
+	absolute := clock.TimeControl{System: clock.Absolute, Main: 10 * time.Minute}
+	target, limit := allocate(absolute, time.Minute, 0, 81)
+	if target <= 0 || target > limit || limit >= time.Minute/2 {
+		t.Errorf("Expected a share of the main time, got %v up to %v", target, limit)
+	}
+	if late, _ := allocate(absolute, time.Minute, 0, 45); late <= target {
+		t.Errorf("Expected more time with fewer empty points, got %v after %v", late, target)
+	}
+	fischer := clock.TimeControl{System: clock.Fischer, Main: 10 * time.Minute, Increment: 10 * time.Second}
+	if withIncrement, _ := allocate(fischer, time.Minute, 0, 81); withIncrement <= target {
+		t.Errorf("Expected the increment to add time, got %v after %v", withIncrement, target)
+	}
+	if target, limit := allocate(fischer, time.Second, 0, 81); limit >= time.Second || target > limit {
+		t.Errorf("Expected the increment not to be spent before it is gained, got %v up to %v", target, limit)
+	}
+
+	byoyomi := clock.TimeControl{System: clock.ByoYomi, Main: 10 * time.Minute, Period: 30 * time.Second, Periods: 1}
+	if target, limit := allocate(byoyomi, 20*time.Second, 1, 81); target >= limit || limit >= 20*time.Second {
+		t.Errorf("Expected to stay within the last period, got %v up to %v", target, limit)
+	}
+	byoyomi.Periods = 5
+	if _, limit := allocate(byoyomi, 20*time.Second, 1, 81); limit <= 20*time.Second {
+		t.Errorf("Expected a spare period to be usable, got up to %v", limit)
+	}
+	canadian := clock.TimeControl{System: clock.Canadian, Main: 10 * time.Minute, Period: 5 * time.Minute, Stones: 25}
+	if target, _ := allocate(canadian, time.Minute, 10, 81); target != (time.Minute-DefaultOverhead)/10 {
+		t.Errorf("Expected the block shared by its stones, got %v", target)
+	}
+	if target, limit := allocate(absolute, DefaultOverhead, 0, 81); target != 0 || limit != 0 {
+		t.Errorf("Expected no time when only the overhead is left, got %v up to %v", target, limit)
+	}
+}
+
+func TestTimeManagerOpening(t *testing.T) {
+	m := NewTimeManager()
+	m.TimeSettings(clock.TimeControl{System: clock.Absolute, Main: time.Minute})
+	m.TimeLeft(time.Minute, 0)
+	first, _ := m.Allocate(81)
+	for i := 1; i < openingMoves; i++ {
+		m.Allocate(81)
+	}
+	if later, _ := m.Allocate(81); later != 2*first {
+		t.Errorf("Expected opening moves to get half the time, got %v and later %v", first, later)
+	}
+	m.TimeSettings(clock.TimeControl{System: clock.Absolute, Main: 2 * time.Minute})
+	if again, _ := m.Allocate(81); again != first {
+		t.Errorf("Expected a new time control to start over, got %v", again)
+	}
+}
+
+func TestTimeManagerCountsPeriods(t *testing.T) {
+	m := NewTimeManager()
+	m.TimeSettings(clock.TimeControl{System: clock.ByoYomi, Main: time.Minute, Period: 30 * time.Second, Periods: 3})
+	m.TimeLeft(10*time.Second, 0)
+	m.Spent(15 * time.Second)
+	m.TimeLeft(25*time.Second, 1)
+	if _, limit := m.Allocate(81); limit <= 25*time.Second {
+		t.Errorf("Expected a spare period after the main time ran out, got up to %v", limit)
+	}
+	m.Spent(40 * time.Second)
+	if m.periods != 2 {
+		t.Errorf("Expected one period to be used up, got %d left", m.periods)
+	}
+	m.TimeLeft(30*time.Second, 1)
+	if _, limit := m.Allocate(81); limit >= 30*time.Second {
+		t.Errorf("Expected to stay within the period with one to spare, got up to %v", limit)
+	}
+}
+
+// fakeClock advances by a millisecond every time it is read.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	c.t = c.t.Add(time.Millisecond)
+	return c.t
+}
+
+// timedEngine returns an engine with remaining absolute time on a fake clock.
+func timedEngine(remaining time.Duration) (*AlphaBetaEngine, *fakeClock) {
+	c := &fakeClock{t: time.Unix(0, 0)}
+	e := NewAlphaBetaEngine()
+	e.SetTimeManager(NewTimeManager())
+	e.time.now = c.now
+	e.TimeSettings(clock.TimeControl{System: clock.Absolute, Main: remaining})
+	e.TimeLeft(game.Black, remaining, 0)
+	return e, c
+}
+
+func TestAlphaBetaEngineStaysWithinTime(t *testing.T) {
+	e, c := timedEngine(3 * time.Second)
+	start := c.t
+	move := e.Move(MidGameBoard(), game.Black, nil)
+	if move == nil || MidGameBoard()[move.Row][move.Col] != game.Empty {
+		t.Errorf("Expected a legal move, got %+v", move)
+	}
+	_, limit := allocate(clock.TimeControl{Main: 3 * time.Second}, 3*time.Second, 0, 73)
+	if used := c.t.Sub(start); used > limit+time.Second/10 {
+		t.Errorf("Expected at most %v to be used, got %v", limit, used)
+	}
+}
+
+func TestAlphaBetaEngineStopsWhenOneMoveDominates(t *testing.T) {
+	// A white group of six stones in atari at its last liberty, e5.
+	var board game.Board
+	for _, p := range []game.Point{{Row: 3, Col: 3}, {Row: 3, Col: 4}, {Row: 3, Col: 5}, {Row: 4, Col: 3}, {Row: 4, Col: 5}, {Row: 5, Col: 4}} {
+		board[p.Row][p.Col] = game.White
+	}
+	for _, p := range []game.Point{{Row: 2, Col: 3}, {Row: 2, Col: 4}, {Row: 2, Col: 5}, {Row: 3, Col: 2}, {Row: 3, Col: 6}, {Row: 4, Col: 2}, {Row: 4, Col: 6}, {Row: 5, Col: 3}, {Row: 5, Col: 5}, {Row: 6, Col: 4}} {
+		board[p.Row][p.Col] = game.Black
+	}
+	e, c := timedEngine(10 * time.Minute)
+	start := c.t
+	move := e.Move(board, game.Black, nil)
+	if move == nil || *move != (game.Point{Row: 4, Col: 4}) {
+		t.Errorf("Expected the capture, got %+v", move)
+	}
+	target, _ := allocate(clock.TimeControl{Main: 10 * time.Minute}, 10*time.Minute, 0, emptyPoints(board))
+	if used := c.t.Sub(start); used >= target/4 {
+		t.Errorf("Expected the search to stop early, used %v of %v", used, target)
+	}
+}
@@ -0,0 +1,37 @@
+package engine
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+func TestWeightsSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "weights.json")
+	w := Weights{Stones: 12, Liberties: 3, Capturable: 7, Groups: -1}
+	if err := w.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadWeights(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != w {
+		t.Errorf("Expected %+v, got %+v", w, got)
+	}
+}
+
+func TestDefaultWeightsMatchZeroValueEngine(t *testing.T) {
+	board := MidGameBoard()
+	board[0][0] = game.Black
+	e := &AlphaBetaEngine{}
+	tuned := NewAlphaBetaEngineWithWeights(DefaultWeights())
+	if e.evaluate(board, game.Black, game.White) != tuned.evaluate(board, game.Black, game.White) {
+		t.Error("Expected the zero value engine to use the default weights")
+	}
+	features := ExtractFeatures(board, game.Black, game.White)
+	if features.Stones != 1 || features.Groups != 1 {
+		t.Errorf("Expected one extra black stone and group, got %+v", features)
+	}
+}
@@ -16,8 +16,17 @@ const (
 	White                   // White stone
 )
 
+// Opponent returns the colour playing against color.
+func Opponent(color FieldState) FieldState {
+	if color == Black {
+		return White
+	}
+	return Black
+}
+
 type Gui struct {
-	Grid [9][9]FieldState // 9x9 grid for the game
+	Grid   [9][9]FieldState   // 9x9 grid for the game
+	Marked map[Point]struct{} // stones drawn in parentheses, e.g. chains caught in a ladder
 }
 
 var FieldStateName = map[FieldState]string{
@@ -106,6 +115,9 @@ func (g *Gui) DrawGridToWriter(w io.Writer, cursorRow, cursorCol int8) {
 				// Use a box-drawing character for the stone
 				cell = fmt.Sprintf("─%s─", stone)
 			}
+			if _, ok := g.Marked[Point{Row: i, Col: j}]; ok {
+				cell = fmt.Sprintf("(%s)", stone)
+			}
 			if i == cursorRow && j == cursorCol {
 				cell = fmt.Sprintf("[%s]", stone)
 			}
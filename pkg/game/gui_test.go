@@ -132,3 +132,15 @@ func TestDrawGridToWriterWithWhiteStone(t *testing.T) {
 		t.Errorf("Expected board to contain a white stone, got: %q", output)
 	}
 }
+
+func TestDrawGridToWriterMarkedStone(t *testing.T) {
+	var b Board
+	b[4][4] = White
+	var buf bytes.Buffer
+	gui := Gui{Grid: b, Marked: map[Point]struct{}{{Row: 4, Col: 4}: {}}}
+	gui.DrawGridToWriter(&buf, 0, 0)
+	output := buf.String()
+	if !strings.Contains(output, "(\x1b[1m⚪\x1b[0m)") {
+		t.Errorf("Expected marked white stone in parentheses, got: %q", output)
+	}
+}
@@ -0,0 +1,181 @@
+package game
+
+import "sort"
+
+// maxLadderNodes bounds the number of positions read for a single ladder.
+// Lines that are not resolved within the budget count as escaped.
+const maxLadderNodes = 10000
+
+// maxLadderDepth bounds the length of a line; longer lines count as
+// escaped too. No ladder on the board comes close, but since ko is ignored
+// a ko next to the chain can be retaken back and forth until the node
+// budget runs out, thousands of calls deep. That overflows the fixed host
+// stack under WebAssembly.
+const maxLadderDepth = 2 * BoardSize * BoardSize
+
+// ladderReader counts the positions read by a ladder search and the
+// length of the line being read.
+type ladderReader struct {
+	nodes int
+	depth int
+}
+
+// LadderCaptured reports whether the chain at p, which has a single liberty
+// and whose owner is to move, is captured in a ladder. The defender may
+// extend at its liberty or capture an adjacent attacking chain in atari;
+// ladder breakers of either colour are taken into account by reading the
+// ladder out. Ko is ignored.
+func LadderCaptured(b Board, p Point) bool {
+	if b[p.Row][p.Col] == Empty {
+		return false
+	}
+	_, libs := Group(b, p)
+	if len(libs) != 1 {
+		return false
+	}
+	r := &ladderReader{}
+	return !r.defend(b, p)
+}
+
+// LadderAttack returns the atari that captures the chain at p in a ladder
+// when the chain's opponent is to move, or nil if there is none. The chain
+// must have exactly two liberties.
+func LadderAttack(b Board, p Point) *Point {
+	if b[p.Row][p.Col] == Empty {
+		return nil
+	}
+	_, libs := Group(b, p)
+	if len(libs) != 2 {
+		return nil
+	}
+	r := &ladderReader{}
+	return r.attack(b, p)
+}
+
+// Ladders returns the stones of every chain that a working ladder captures
+// with toMove to play: chains of toMove in atari that cannot escape and
+// chains of either colour with two liberties that the opponent can chase
+// in a ladder.
+func Ladders(b Board, toMove FieldState) map[Point]struct{} {
+	marked := make(map[Point]struct{})
+	visited := make(map[Point]struct{})
+	for i := int8(0); i < BoardSize; i++ {
+		for j := int8(0); j < BoardSize; j++ {
+			p := Point{Row: i, Col: j}
+			if _, ok := visited[p]; ok || b[i][j] == Empty {
+				continue
+			}
+			stones, libs := Group(b, p)
+			for s := range stones {
+				visited[s] = struct{}{}
+			}
+			captured := false
+			switch len(libs) {
+			case 1:
+				captured = b[i][j] == toMove && LadderCaptured(b, p)
+			case 2:
+				captured = LadderAttack(b, p) != nil
+			}
+			if captured {
+				for s := range stones {
+					marked[s] = struct{}{}
+				}
+			}
+		}
+	}
+	return marked
+}
+
+// defend reports whether the chain at p in atari escapes with its owner to move.
+func (r *ladderReader) defend(b Board, p Point) bool {
+	r.nodes++
+	r.depth++
+	defer func() { r.depth-- }()
+	if r.nodes > maxLadderNodes || r.depth > maxLadderDepth {
+		return true
+	}
+	defender := b[p.Row][p.Col]
+	attacker := Opponent(defender)
+	stones, libs := Group(b, p)
+
+	var candidates []Point
+	// Capturing an adjacent attacker chain in atari gains liberties.
+	seen := make(map[Point]struct{})
+	for s := range stones {
+		for _, n := range Neighbors(s) {
+			if b[n.Row][n.Col] != attacker {
+				continue
+			}
+			if _, ok := seen[n]; ok {
+				continue
+			}
+			group, groupLibs := Group(b, n)
+			for g := range group {
+				seen[g] = struct{}{}
+			}
+			if len(groupLibs) == 1 {
+				for l := range groupLibs {
+					candidates = append(candidates, l)
+				}
+			}
+		}
+	}
+	candidates = append(candidates, sortedPoints(libs)...)
+
+	for _, move := range candidates {
+		next, _, err := play(b, move, defender)
+		if err != nil {
+			continue
+		}
+		_, nextLibs := Group(next, p)
+		switch {
+		case len(nextLibs) >= 3:
+			return true
+		case len(nextLibs) == 2:
+			if r.attack(next, p) == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// attack returns an atari on the two-liberty chain at p after which the
+// chain cannot escape, or nil.
+func (r *ladderReader) attack(b Board, p Point) *Point {
+	r.nodes++
+	if r.nodes > maxLadderNodes {
+		return nil
+	}
+	attacker := Opponent(b[p.Row][p.Col])
+	_, libs := Group(b, p)
+	for _, l := range sortedPoints(libs) {
+		next, _, err := play(b, l, attacker)
+		if err != nil {
+			continue
+		}
+		// If the atari leaves the attacking stones in atari themselves, the
+		// defender's capture is read by defend.
+		if !r.defend(next, p) {
+			move := l
+			return &move
+		}
+	}
+	return nil
+}
+
+// sortedPoints returns the points of set in row-major order so that ladder
+// reading is deterministic.
+func sortedPoints(set map[Point]struct{}) []Point {
+	points := make([]Point, 0, len(set))
+	for p := range set {
+		points = append(points, p)
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].Row != points[j].Row {
+			return points[i].Row < points[j].Row
+		}
+		return points[i].Col < points[j].Col
+	})
+	return points
+}
@@ -0,0 +1,116 @@
+package game
+
+import "errors"
+
+// Errors returned by Play for illegal moves.
+var (
+	ErrOccupied = errors.New("game: point is occupied")
+	ErrSuicide  = errors.New("game: suicide")
+	ErrKo       = errors.New("game: ko")
+	ErrSuperko  = errors.New("game: superko")
+)
+
+// State is a position together with what the rules need to judge the
+// next move.
+type State struct {
+	Board  Board
+	ToMove FieldState
+	// Ko is the point the player to move may not play because it would
+	// retake a ko at once, nil if there is none.
+	Ko *Point
+	// Superko forbids any move that recreates an earlier position of
+	// History, which Play extends with every position it leaves.
+	Superko bool
+	History []Board
+}
+
+// NewState returns the state of board with toMove to play and no ko.
+func NewState(board Board, toMove FieldState) State {
+	return State{Board: board, ToMove: toMove}
+}
+
+// Play plays move for the player to move, nil for a pass, and returns the
+// resulting state and the captured stones. An illegal move returns s
+// unchanged and ErrOccupied, ErrSuicide, ErrKo or ErrSuperko.
+func Play(s State, move *Point) (State, []Point, error) {
+	next := s
+	next.ToMove = Opponent(s.ToMove)
+	next.Ko = nil
+	if s.Superko {
+		// Copy on append so that states sharing a history stay independent.
+		next.History = append(s.History[:len(s.History):len(s.History)], s.Board)
+	}
+	if move == nil {
+		return next, nil, nil
+	}
+	p := *move
+	if s.Ko != nil && p == *s.Ko && s.Board[p.Row][p.Col] == Empty {
+		return s, nil, ErrKo
+	}
+	board, captured, err := play(s.Board, p, s.ToMove)
+	if err != nil {
+		return s, nil, err
+	}
+	if s.Superko {
+		for _, h := range s.History {
+			if h == board {
+				return s, nil, ErrSuperko
+			}
+		}
+	}
+	next.Board = board
+	if len(captured) == 1 {
+		// Retaking is forbidden if it would capture just the stone played.
+		if stones, libs := Group(board, p); len(stones) == 1 && len(libs) == 1 {
+			next.Ko = &captured[0]
+		}
+	}
+	return next, captured, nil
+}
+
+// LegalMoves returns the points the player to move may play in s, in
+// row-major order. Passing is always legal and not included.
+func LegalMoves(s State) []Point {
+	var moves []Point
+	for i := int8(0); i < BoardSize; i++ {
+		for j := int8(0); j < BoardSize; j++ {
+			p := Point{Row: i, Col: j}
+			if s.Board[i][j] != Empty || (s.Ko != nil && p == *s.Ko) {
+				continue
+			}
+			if _, _, err := Play(s, &p); err == nil {
+				moves = append(moves, p)
+			}
+		}
+	}
+	return moves
+}
+
+// play places color at p, removes the opponent chains left without
+// liberties and returns the new board and the captured stones. Ko is left
+// to the caller.
+func play(b Board, p Point, color FieldState) (Board, []Point, error) {
+	if b[p.Row][p.Col] != Empty {
+		return b, nil, ErrOccupied
+	}
+	b[p.Row][p.Col] = color
+	opp := Opponent(color)
+	var captured []Point
+	for _, n := range Neighbors(p) {
+		if b[n.Row][n.Col] != opp {
+			continue
+		}
+		group, libs := Group(b, n)
+		if len(libs) > 0 {
+			continue
+		}
+		for s := range group {
+			b[s.Row][s.Col] = Empty
+			captured = append(captured, s)
+		}
+	}
+	if _, libs := Group(b, p); len(libs) == 0 {
+		return b, nil, ErrSuicide
+	}
+	return b, captured, nil
+}
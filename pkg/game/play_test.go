@@ -0,0 +1,82 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+// koState returns a ko on the top edge with White to capture at (0,1).
+func koState() State {
+	var b Board
+	b[0][0], b[1][1], b[0][2] = Black, Black, Black
+	b[0][3], b[1][2] = White, White
+	return NewState(b, White)
+}
+
+func TestPlayCapturesAndSetsKo(t *testing.T) {
+	next, captured, err := Play(koState(), &Point{Row: 0, Col: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(captured) != 1 || captured[0] != (Point{Row: 0, Col: 2}) {
+		t.Fatalf("Expected the stone at (0,2) to be captured, got %v", captured)
+	}
+	if next.Board[0][2] != Empty || next.ToMove != Black {
+		t.Fatal("Expected the capture to be removed and Black to move")
+	}
+	if next.Ko == nil || *next.Ko != (Point{Row: 0, Col: 2}) {
+		t.Fatalf("Expected the ko at (0,2), got %v", next.Ko)
+	}
+	if _, _, err := Play(next, next.Ko); !errors.Is(err, ErrKo) {
+		t.Errorf("Expected the immediate retake to be ErrKo, got %v", err)
+	}
+	for _, p := range LegalMoves(next) {
+		if p == *next.Ko {
+			t.Error("Expected the ko point not to be a legal move")
+		}
+	}
+
+	// After two passes the ko may be retaken.
+	passed, _, _ := Play(next, nil)
+	if passed.Ko != nil {
+		t.Error("Expected a pass to lift the ko")
+	}
+	passed, _, _ = Play(passed, nil)
+	if _, _, err := Play(passed, &Point{Row: 0, Col: 2}); err != nil {
+		t.Errorf("Expected the retake to be legal after passes, got %v", err)
+	}
+}
+
+func TestPlayErrors(t *testing.T) {
+	if _, _, err := Play(koState(), &Point{Row: 0, Col: 0}); !errors.Is(err, ErrOccupied) {
+		t.Errorf("Expected ErrOccupied, got %v", err)
+	}
+	var b Board
+	b[0][1], b[1][0], b[1][1] = White, White, White
+	s := NewState(b, Black)
+	if next, _, err := Play(s, &Point{Row: 0, Col: 0}); !errors.Is(err, ErrSuicide) || next.Board != b {
+		t.Errorf("Expected ErrSuicide and an unchanged state, got %v", err)
+	}
+}
+
+func TestPlaySuperko(t *testing.T) {
+	var earlier Board
+	earlier[4][4] = Black
+	s := State{ToMove: Black, Superko: true, History: []Board{earlier}}
+	if _, _, err := Play(s, &Point{Row: 4, Col: 4}); !errors.Is(err, ErrSuperko) {
+		t.Errorf("Expected ErrSuperko, got %v", err)
+	}
+	next, _, err := Play(s, &Point{Row: 3, Col: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(next.History) != 2 || len(s.History) != 1 {
+		t.Errorf("Expected the history to grow in the new state only, got %d and %d", len(next.History), len(s.History))
+	}
+}
+
+func TestLegalMovesEmptyBoard(t *testing.T) {
+	if n := len(LegalMoves(NewState(NewBoard(), Black))); n != BoardSize*BoardSize {
+		t.Errorf("Expected %d legal moves on the empty board, got %d", BoardSize*BoardSize, n)
+	}
+}
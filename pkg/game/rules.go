@@ -65,49 +65,9 @@ func Group(b Board, start Point) (stones map[Point]struct{}, liberties map[Point
 
 // IsLegalMove checks if placing a stone at p for color is legal (no suicide, no ko).
 func IsLegalMove(b Board, p Point, color FieldState, prev Board) bool {
-	if b[p.Row][p.Col] != Empty {
-		return false
-	}
-	// Copy board and play move
-	var next Board = b
-	next[p.Row][p.Col] = color
-	// Remove opponent groups with no liberties
-	opp := Black
-	if color == Black {
-		opp = White
-	}
-	for _, n := range Neighbors(p) {
-		if next[n.Row][n.Col] == opp {
-			group, libs := Group(next, n)
-			if len(libs) == 0 {
-				for stone := range group {
-					next[stone.Row][stone.Col] = Empty
-				}
-			}
-		}
-	}
-	// Check if own group has liberties
-	_, libs := Group(next, p)
-	if len(libs) == 0 {
-		return false // suicide
-	}
+	next, _, err := play(b, p, color)
 	// Ko: board must not repeat previous position
-	same := true
-	for i := 0; i < BoardSize; i++ {
-		for j := 0; j < BoardSize; j++ {
-			if next[i][j] != prev[i][j] {
-				same = false
-				break
-			}
-		}
-		if !same {
-			break
-		}
-	}
-	if same {
-		return false // Ko: position repeats previous
-	}
-	return true
+	return err == nil && next != prev
 }
 
 // CalculateScore returns the territory score for Black and White.
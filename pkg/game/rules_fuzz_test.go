@@ -0,0 +1,106 @@
+package game
+
+import "testing"
+
+// fuzzPoint maps a fuzz byte to a point, or nil for a pass.
+func fuzzPoint(c byte) *Point {
+	i := int(c) % (BoardSize*BoardSize + 1)
+	if i == BoardSize*BoardSize {
+		return nil
+	}
+	return &Point{Row: int8(i / BoardSize), Col: int8(i % BoardSize)}
+}
+
+func countColor(b Board, color FieldState) int {
+	n := 0
+	for i := range b {
+		for _, c := range b[i] {
+			if c == color {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// FuzzRules plays the moves encoded by the input, one byte per move,
+// skipping illegal ones, and checks the invariants of the rules after
+// every move.
+func FuzzRules(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{40, 41, 31, 32, 49, 50, 39, 42, 30, 33})
+	// A ko on the edge: White captures at 1, Black's immediate retake at 2
+	// is skipped, Black plays elsewhere and retakes after White's reply.
+	f.Add([]byte{0, 3, 10, 11, 2, 1, 2, 40, 30, 2, 81, 81})
+	f.Add([]byte("the quick brown fox jumps over the lazy dog, twice and again"))
+	f.Fuzz(func(t *testing.T, moves []byte) {
+		var b, prev Board
+		color := Black
+		for _, c := range moves {
+			p := fuzzPoint(c)
+			if p == nil {
+				prev = b
+				color = Opponent(color)
+				continue
+			}
+			next, _, err := play(b, *p, color)
+			ok := err == nil
+			legal := IsLegalMove(b, *p, color, prev)
+			if legal && !ok {
+				t.Fatalf("Expected %v at %v to be illegal as it is occupied or suicide on\n%v", color, *p, b)
+			}
+			if ok && !legal && next != prev {
+				t.Fatalf("Expected %v at %v to be legal as it does not repeat the previous position on\n%v", color, *p, b)
+			}
+			if !legal {
+				continue
+			}
+			checkMove(t, b, next, *p, color)
+			prev, b = b, next
+			color = Opponent(color)
+		}
+	})
+}
+
+// checkMove checks that color playing p turns b into next: the stone is
+// added, only opponent stones are captured, every chain keeps a liberty
+// and a single-stone ko capture cannot be answered by the recapture.
+func checkMove(t *testing.T, b, next Board, p Point, color FieldState) {
+	t.Helper()
+	opp := Opponent(color)
+	if next[p.Row][p.Col] != color {
+		t.Fatalf("Expected %v at %v after playing there", color, p)
+	}
+	if got, want := countColor(next, color), countColor(b, color)+1; got != want {
+		t.Fatalf("Expected %d %v stones after %v, got %d", want, color, p, got)
+	}
+	captured := countColor(b, opp) - countColor(next, opp)
+	if captured < 0 {
+		t.Fatalf("Expected no %v stones to appear after %v", opp, p)
+	}
+	for i := int8(0); i < BoardSize; i++ {
+		for j := int8(0); j < BoardSize; j++ {
+			if b[i][j] != Empty && next[i][j] != b[i][j] && !(b[i][j] == opp && next[i][j] == Empty) {
+				t.Fatalf("Expected the stone at %v to stay after %v", Point{Row: i, Col: j}, p)
+			}
+			if next[i][j] == Empty {
+				continue
+			}
+			if _, libs := Group(next, Point{Row: i, Col: j}); len(libs) == 0 {
+				t.Fatalf("Expected the chain at %v to have a liberty after %v on\n%v", Point{Row: i, Col: j}, p, next)
+			}
+		}
+	}
+	if captured != 1 {
+		return
+	}
+	stones, libs := Group(next, p)
+	if len(stones) != 1 || len(libs) != 1 {
+		return
+	}
+	for ko := range libs {
+		if b[ko.Row][ko.Col] == opp && IsLegalMove(next, ko, opp, b) {
+			t.Fatalf("Expected the recapture at %v to be forbidden by ko on\n%v", ko, next)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package game
+
+// Symmetry is one of the eight rotations and reflections of the board.
+// Rotations are clockwise.
+type Symmetry int8
+
+const (
+	Identity Symmetry = iota
+	Transpose
+	FlipVertical // mirror the rows
+	Rotate270
+	FlipHorizontal // mirror the columns
+	Rotate90
+	Rotate180
+	AntiTranspose
+)
+
+// NumSymmetries is the number of symmetries of the board.
+const NumSymmetries = 8
+
+// The bits of a Symmetry are applied in order: bit 0 transposes, bit 1
+// mirrors the rows and bit 2 mirrors the columns.
+
+// Point returns the image of p under s.
+func (s Symmetry) Point(p Point) Point {
+	if s&1 != 0 {
+		p.Row, p.Col = p.Col, p.Row
+	}
+	if s&2 != 0 {
+		p.Row = BoardSize - 1 - p.Row
+	}
+	if s&4 != 0 {
+		p.Col = BoardSize - 1 - p.Col
+	}
+	return p
+}
+
+// Board returns the image of b under s.
+func (s Symmetry) Board(b Board) Board {
+	var t Board
+	for i := int8(0); i < BoardSize; i++ {
+		for j := int8(0); j < BoardSize; j++ {
+			p := s.Point(Point{Row: i, Col: j})
+			t[p.Row][p.Col] = b[i][j]
+		}
+	}
+	return t
+}
+
+// Inverse returns the symmetry that undoes s.
+func (s Symmetry) Inverse() Symmetry {
+	switch s {
+	case Rotate90:
+		return Rotate270
+	case Rotate270:
+		return Rotate90
+	}
+	return s
+}
+
+// Canonical returns the smallest of the eight images of b, comparing
+// points in row-major order with Empty < Black < White, and the first
+// symmetry that produces it. Boards that are rotations or reflections of
+// each other have the same canonical form.
+func Canonical(b Board) (Board, Symmetry) {
+	best, bestSym := b, Identity
+	for s := Symmetry(1); s < NumSymmetries; s++ {
+		if t := s.Board(b); less(t, best) {
+			best, bestSym = t, s
+		}
+	}
+	return best, bestSym
+}
+
+// Symmetries returns the symmetries that map b onto itself, always
+// including Identity.
+func Symmetries(b Board) []Symmetry {
+	syms := []Symmetry{Identity}
+	for s := Symmetry(1); s < NumSymmetries; s++ {
+		if s.Board(b) == b {
+			syms = append(syms, s)
+		}
+	}
+	return syms
+}
+
+// less reports whether a precedes b in row-major order.
+func less(a, b Board) bool {
+	for i := 0; i < BoardSize; i++ {
+		for j := 0; j < BoardSize; j++ {
+			if a[i][j] != b[i][j] {
+				return a[i][j] < b[i][j]
+			}
+		}
+	}
+	return false
+}
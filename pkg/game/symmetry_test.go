@@ -0,0 +1,76 @@
+package game
+
+import "testing"
+
+func TestSymmetryPoint(t *testing.T) {
+	p := Point{Row: 1, Col: 2}
+	expected := map[Symmetry]Point{
+		Identity:       {1, 2},
+		Transpose:      {2, 1},
+		FlipVertical:   {7, 2},
+		Rotate270:      {6, 1},
+		FlipHorizontal: {1, 6},
+		Rotate90:       {2, 7},
+		Rotate180:      {7, 6},
+		AntiTranspose:  {6, 7},
+	}
+	for s, want := range expected {
+		if got := s.Point(p); got != want {
+			t.Errorf("Expected %v to map %v to %v, got %v", s, p, want, got)
+		}
+	}
+	if got := Rotate90.Point(Rotate90.Point(p)); got != Rotate180.Point(p) {
+		t.Errorf("Expected two quarter turns to be a half turn, got %v", got)
+	}
+}
+
+func TestSymmetryInverse(t *testing.T) {
+	var b Board
+	b[0][1] = Black
+	b[3][7] = White
+	for s := Symmetry(0); s < NumSymmetries; s++ {
+		for i := int8(0); i < BoardSize; i++ {
+			for j := int8(0); j < BoardSize; j++ {
+				p := Point{Row: i, Col: j}
+				if got := s.Inverse().Point(s.Point(p)); got != p {
+					t.Fatalf("Expected inverse of %v to restore %v, got %v", s, p, got)
+				}
+			}
+		}
+		if got := s.Inverse().Board(s.Board(b)); got != b {
+			t.Errorf("Expected inverse of %v to restore the board", s)
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	var b Board
+	b[2][2] = Black
+	b[2][6] = White
+	canonical, sym := Canonical(b)
+	if sym.Board(b) != canonical {
+		t.Errorf("Expected the returned symmetry to produce the canonical board")
+	}
+	for s := Symmetry(0); s < NumSymmetries; s++ {
+		if c, _ := Canonical(s.Board(b)); c != canonical {
+			t.Errorf("Expected image under %v to have the same canonical form", s)
+		}
+	}
+	other := b
+	other[2][6] = Black
+	if c, _ := Canonical(other); c == canonical {
+		t.Errorf("Expected a different position to have a different canonical form")
+	}
+}
+
+func TestSymmetries(t *testing.T) {
+	if n := len(Symmetries(Board{})); n != NumSymmetries {
+		t.Errorf("Expected the empty board to have %d symmetries, got %d", NumSymmetries, n)
+	}
+	var b Board
+	b[2][2] = Black
+	syms := Symmetries(b)
+	if len(syms) != 2 || syms[0] != Identity || syms[1] != Transpose {
+		t.Errorf("Expected identity and transpose, got %v", syms)
+	}
+}
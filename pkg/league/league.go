@@ -0,0 +1,228 @@
+// Package league runs round-robin and gauntlet tournaments between engine
+// versions and maintains a persistent rating list for them.
+package league
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/RubikNube/GoInGo/pkg/compareengines"
+	"github.com/RubikNube/GoInGo/pkg/engine"
+	"github.com/RubikNube/GoInGo/pkg/engine/old"
+	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/tsumego"
+)
+
+// Entrant is an engine version taking part in the league.
+type Entrant struct {
+	Name string
+	// New returns a fresh engine instance. A new instance is created for
+	// every game so search state does not leak between games.
+	New func() engine.Engine
+}
+
+// Pairing holds the accumulated results between two league members.
+// Wins counts the games won by A, Losses the games won by B.
+type Pairing struct {
+	A      string `json:"a"`
+	B      string `json:"b"`
+	Wins   int    `json:"wins"`
+	Losses int    `json:"losses"`
+	Draws  int    `json:"draws"`
+}
+
+// Games returns the number of games played in the pairing.
+func (p Pairing) Games() int {
+	return p.Wins + p.Losses + p.Draws
+}
+
+// League is the persistent state of a league: the members and all results.
+type League struct {
+	Players  []string  `json:"players"`
+	Pairings []Pairing `json:"pairings"`
+}
+
+// Load reads a league file. A missing file yields an empty league.
+func Load(path string) (*League, error) {
+	l := &League{}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(l); err != nil {
+		return nil, fmt.Errorf("league: decode %s: %w", path, err)
+	}
+	return l, nil
+}
+
+// Save writes the league file.
+func (l *League) Save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// AddPlayer adds name to the league if it is not a member yet.
+func (l *League) AddPlayer(name string) {
+	for _, p := range l.Players {
+		if p == name {
+			return
+		}
+	}
+	l.Players = append(l.Players, name)
+}
+
+// Record adds a game result between a and b. result is 1 if a won, -1 if b
+// won and 0 for a draw, matching compareengines.CompareEngines.
+func (l *League) Record(a, b string, result int) {
+	l.AddPlayer(a)
+	l.AddPlayer(b)
+	// Pairings are stored once per unordered pair.
+	if a > b {
+		a, b = b, a
+		result = -result
+	}
+	var p *Pairing
+	for i := range l.Pairings {
+		if l.Pairings[i].A == a && l.Pairings[i].B == b {
+			p = &l.Pairings[i]
+			break
+		}
+	}
+	if p == nil {
+		l.Pairings = append(l.Pairings, Pairing{A: a, B: b})
+		p = &l.Pairings[len(l.Pairings)-1]
+	}
+	switch {
+	case result > 0:
+		p.Wins++
+	case result < 0:
+		p.Losses++
+	default:
+		p.Draws++
+	}
+}
+
+// Score returns the points a scored against b (win 1, draw 0.5) and the
+// number of games they played.
+func (l *League) Score(a, b string) (points float64, games int) {
+	for _, p := range l.Pairings {
+		switch {
+		case p.A == a && p.B == b:
+			return float64(p.Wins) + float64(p.Draws)/2, p.Games()
+		case p.A == b && p.B == a:
+			return float64(p.Losses) + float64(p.Draws)/2, p.Games()
+		}
+	}
+	return 0, 0
+}
+
+// Options configures how league games are played.
+type Options struct {
+	// Games is the number of games per pairing. Colours alternate, so an
+	// even number gives both engines the same number of games as Black.
+	Games int
+	// MaxMoves limits the length of each game.
+	MaxMoves int
+	// Progress, if set, is called after every game.
+	Progress func(black, white string, result int)
+}
+
+// PlayMatch plays opts.Games games between a and b and records them.
+func (l *League) PlayMatch(a, b Entrant, opts Options) {
+	for i := 0; i < opts.Games; i++ {
+		black, white := a, b
+		if i%2 == 1 {
+			black, white = b, a
+		}
+		result := compareengines.CompareEngines(black.New(), white.New(), game.NewBoard(), game.Black, opts.MaxMoves)
+		l.Record(black.Name, white.Name, result)
+		if opts.Progress != nil {
+			opts.Progress(black.Name, white.Name, result)
+		}
+	}
+}
+
+// RoundRobin lets every entrant play every other entrant.
+func (l *League) RoundRobin(entrants []Entrant, opts Options) {
+	for i := range entrants {
+		l.AddPlayer(entrants[i].Name)
+		for j := i + 1; j < len(entrants); j++ {
+			l.PlayMatch(entrants[i], entrants[j], opts)
+		}
+	}
+}
+
+// Gauntlet lets candidate play every other entrant without playing the
+// remaining league games among themselves.
+func (l *League) Gauntlet(candidate Entrant, opponents []Entrant, opts Options) {
+	l.AddPlayer(candidate.Name)
+	for _, opp := range opponents {
+		if opp.Name == candidate.Name {
+			continue
+		}
+		l.PlayMatch(candidate, opp, opts)
+	}
+}
+
+// Standing is a player's row in the rating list.
+type Standing struct {
+	Name   string  `json:"name"`
+	Elo    float64 `json:"elo"`
+	Games  int     `json:"games"`
+	Points float64 `json:"points"`
+}
+
+// Standings returns the rating list ordered by descending rating.
+func (l *League) Standings() []Standing {
+	ratings := l.Ratings()
+	standings := make([]Standing, 0, len(l.Players))
+	for _, name := range l.Players {
+		s := Standing{Name: name, Elo: ratings[name]}
+		for _, other := range l.Players {
+			if other == name {
+				continue
+			}
+			points, games := l.Score(name, other)
+			s.Points += points
+			s.Games += games
+		}
+		standings = append(standings, s)
+	}
+	sort.SliceStable(standings, func(i, j int) bool {
+		return standings[i].Elo > standings[j].Elo
+	})
+	return standings
+}
+
+// Builtin returns the engine versions shipped with this repository.
+func Builtin() []Entrant {
+	return []Entrant{
+		{Name: "alphabeta", New: func() engine.Engine { return engine.NewAlphaBetaEngine() }},
+		{Name: "alphabeta-territory", New: func() engine.Engine {
+			return engine.NewAlphaBetaEngineWithEvaluator(engine.TerritoryEvaluator(engine.DefaultTerritoryWeights()))
+		}},
+		{Name: "alphabeta-tsumego", New: func() engine.Engine { return tsumego.NewEngine(engine.NewAlphaBetaEngine()) }},
+		{Name: "alphabeta-old", New: func() engine.Engine { return old.NewAlphaBetaEngine() }},
+		{Name: "random", New: func() engine.Engine { return engine.NewRandomEngine() }},
+	}
+}
+
+// Lookup returns the entrant called name from entrants.
+func Lookup(entrants []Entrant, name string) (Entrant, bool) {
+	for _, e := range entrants {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return Entrant{}, false
+}
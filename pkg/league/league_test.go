@@ -0,0 +1,121 @@
+package league
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/RubikNube/GoInGo/pkg/engine"
+)
+
+func TestRecordStoresUnorderedPairOnce(t *testing.T) {
+	var l League
+	l.Record("b", "a", 1)
+	l.Record("a", "b", 1)
+	l.Record("a", "b", 0)
+	if len(l.Pairings) != 1 {
+		t.Fatalf("Expected 1 pairing, got %d", len(l.Pairings))
+	}
+	points, games := l.Score("a", "b")
+	if games != 3 || points != 1.5 {
+		t.Errorf("Expected a to score 1.5 in 3 games, got %.1f in %d", points, games)
+	}
+	points, _ = l.Score("b", "a")
+	if points != 1.5 {
+		t.Errorf("Expected b to score 1.5, got %.1f", points)
+	}
+}
+
+func TestRatingsOrderAndCentre(t *testing.T) {
+	var l League
+	for i := 0; i < 8; i++ {
+		l.Record("strong", "weak", 1)
+		l.Record("strong", "mid", 1)
+		l.Record("mid", "weak", 1)
+	}
+	l.Record("weak", "strong", 1)
+	r := l.Ratings()
+	if !(r["strong"] > r["mid"] && r["mid"] > r["weak"]) {
+		t.Errorf("Expected strong > mid > weak, got %v", r)
+	}
+	sum := r["strong"] + r["mid"] + r["weak"]
+	if math.Abs(sum) > 1e-6 {
+		t.Errorf("Expected ratings to be centred on 0, sum is %f", sum)
+	}
+}
+
+func TestRatingsEvenScoreIsEqual(t *testing.T) {
+	var l League
+	l.Record("a", "b", 1)
+	l.Record("a", "b", -1)
+	r := l.Ratings()
+	if math.Abs(r["a"]-r["b"]) > 1e-6 {
+		t.Errorf("Expected equal ratings, got %v", r)
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "league.json")
+	missing, err := Load(path)
+	if err != nil || len(missing.Players) != 0 {
+		t.Fatalf("Expected empty league for missing file, got %+v, %v", missing, err)
+	}
+	var l League
+	l.Record("a", "b", -1)
+	if err := l.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if points, games := loaded.Score("b", "a"); points != 1 || games != 1 {
+		t.Errorf("Expected b to score 1 in 1 game after reload, got %.1f in %d", points, games)
+	}
+}
+
+func TestGauntletPlaysOnlyCandidateGames(t *testing.T) {
+	random := func() engine.Engine { return engine.NewRandomEngine() }
+	entrants := []Entrant{{"x", random}, {"y", random}, {"z", random}}
+	var l League
+	l.Gauntlet(entrants[0], entrants, Options{Games: 2, MaxMoves: 10})
+	if _, games := l.Score("y", "z"); games != 0 {
+		t.Errorf("Expected no games between y and z, got %d", games)
+	}
+	if _, games := l.Score("x", "y"); games != 2 {
+		t.Errorf("Expected 2 games between x and y, got %d", games)
+	}
+	if _, games := l.Score("x", "z"); games != 2 {
+		t.Errorf("Expected 2 games between x and z, got %d", games)
+	}
+}
+
+func TestWriteTableAndJSON(t *testing.T) {
+	var l League
+	l.Record("alpha", "beta", 1)
+	l.Record("alpha", "beta", 0)
+	var buf bytes.Buffer
+	if err := l.WriteTable(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "1-0-1") || !strings.Contains(buf.String(), "0-1-1") {
+		t.Errorf("Expected crosstable cells in output, got:\n%s", buf.String())
+	}
+	buf.Reset()
+	if err := l.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var s Summary
+	if err := json.Unmarshal(buf.Bytes(), &s); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Standings) != 2 || s.Standings[0].Name != "alpha" {
+		t.Errorf("Expected alpha to lead the standings, got %+v", s.Standings)
+	}
+	if s.Crosstable["beta"]["alpha"].Losses != 1 {
+		t.Errorf("Expected beta to have 1 loss against alpha, got %+v", s.Crosstable["beta"]["alpha"])
+	}
+}
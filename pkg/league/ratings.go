@@ -0,0 +1,80 @@
+package league
+
+import "math"
+
+// priorDraws is the number of virtual draws every player has against an
+// average opponent. Like the prior in BayesElo it keeps ratings finite for
+// players with perfect or zero scores.
+const priorDraws = 2.0
+
+// Ratings fits a Bradley-Terry model to all recorded games and returns the
+// Elo rating of every player. Draws count as half a win for each side.
+// Ratings are centred so that their mean is zero.
+func (l *League) Ratings() map[string]float64 {
+	n := len(l.Players)
+	index := make(map[string]int, n)
+	for i, name := range l.Players {
+		index[name] = i
+	}
+	wins := make([]float64, n)
+	games := make([][]float64, n)
+	for i := range games {
+		games[i] = make([]float64, n)
+		wins[i] = priorDraws / 2
+	}
+	for _, p := range l.Pairings {
+		a, okA := index[p.A]
+		b, okB := index[p.B]
+		if !okA || !okB {
+			continue
+		}
+		draws := float64(p.Draws) / 2
+		wins[a] += float64(p.Wins) + draws
+		wins[b] += float64(p.Losses) + draws
+		games[a][b] += float64(p.Games())
+		games[b][a] += float64(p.Games())
+	}
+
+	// Minorization-maximization (Hunter 2004). The virtual opponent of the
+	// prior has strength 1.
+	gamma := make([]float64, n)
+	for i := range gamma {
+		gamma[i] = 1
+	}
+	for iter := 0; iter < 1000; iter++ {
+		maxDelta := 0.0
+		next := make([]float64, n)
+		for i := 0; i < n; i++ {
+			denom := priorDraws / (gamma[i] + 1)
+			for j := 0; j < n; j++ {
+				if games[i][j] > 0 {
+					denom += games[i][j] / (gamma[i] + gamma[j])
+				}
+			}
+			next[i] = wins[i] / denom
+			maxDelta = math.Max(maxDelta, math.Abs(math.Log(next[i]/gamma[i])))
+		}
+		gamma = next
+		if maxDelta < 1e-9 {
+			break
+		}
+	}
+
+	ratings := make(map[string]float64, n)
+	mean := 0.0
+	for i := range gamma {
+		mean += eloFromGamma(gamma[i])
+	}
+	if n > 0 {
+		mean /= float64(n)
+	}
+	for i, name := range l.Players {
+		ratings[name] = eloFromGamma(gamma[i]) - mean
+	}
+	return ratings
+}
+
+// eloFromGamma converts a Bradley-Terry strength to the Elo scale.
+func eloFromGamma(gamma float64) float64 {
+	return 400 * math.Log10(gamma)
+}
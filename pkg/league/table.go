@@ -0,0 +1,87 @@
+package league
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Summary is the JSON form of the league table.
+type Summary struct {
+	Standings  []Standing                    `json:"standings"`
+	Crosstable map[string]map[string]Pairing `json:"crosstable"`
+}
+
+// Summary returns the standings together with the crosstable. Crosstable
+// entries are seen from the row player: Wins are the row player's wins.
+func (l *League) Summary() Summary {
+	s := Summary{
+		Standings:  l.Standings(),
+		Crosstable: make(map[string]map[string]Pairing),
+	}
+	for _, p := range l.Pairings {
+		if s.Crosstable[p.A] == nil {
+			s.Crosstable[p.A] = make(map[string]Pairing)
+		}
+		if s.Crosstable[p.B] == nil {
+			s.Crosstable[p.B] = make(map[string]Pairing)
+		}
+		s.Crosstable[p.A][p.B] = p
+		s.Crosstable[p.B][p.A] = Pairing{A: p.B, B: p.A, Wins: p.Losses, Losses: p.Wins, Draws: p.Draws}
+	}
+	return s
+}
+
+// WriteJSON writes the summary as indented JSON.
+func (l *League) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(l.Summary())
+}
+
+// WriteTable writes the standings and a crosstable as plain text. Each
+// crosstable cell shows the row player's wins-losses-draws against the
+// column player.
+func (l *League) WriteTable(w io.Writer) error {
+	summary := l.Summary()
+	standings := summary.Standings
+	nameWidth := len("Engine")
+	for _, s := range standings {
+		nameWidth = max(nameWidth, len(s.Name))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%3s  %-*s  %6s  %5s  %6s  %5s\n", "#", nameWidth, "Engine", "Elo", "Games", "Points", "Score")
+	for i, s := range standings {
+		score := 0.0
+		if s.Games > 0 {
+			score = 100 * s.Points / float64(s.Games)
+		}
+		fmt.Fprintf(&b, "%3d  %-*s  %6.0f  %5d  %6.1f  %4.0f%%\n", i+1, nameWidth, s.Name, s.Elo, s.Games, s.Points, score)
+	}
+
+	b.WriteString("\n")
+	const cellWidth = 9
+	fmt.Fprintf(&b, "%-*s", nameWidth+2, "")
+	for i := range standings {
+		fmt.Fprintf(&b, "%*d", cellWidth, i+1)
+	}
+	b.WriteString("\n")
+	for i, row := range standings {
+		fmt.Fprintf(&b, "%-*s", nameWidth+2, row.Name)
+		for j, col := range standings {
+			cell := "-"
+			if i != j {
+				cell = "."
+				if p, ok := summary.Crosstable[row.Name][col.Name]; ok {
+					cell = fmt.Sprintf("%d-%d-%d", p.Wins, p.Losses, p.Draws)
+				}
+			}
+			fmt.Fprintf(&b, "%*s", cellWidth, cell)
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
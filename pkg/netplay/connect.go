@@ -0,0 +1,302 @@
+package netplay
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+// handshakeTimeout bounds the exchange of hello and welcome.
+const handshakeTimeout = 10 * time.Second
+
+// Host waits for a guest to join.
+type Host struct {
+	ln net.Listener
+}
+
+// Listen listens for a guest on addr, e.g. ":4242".
+func Listen(addr string) (*Host, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Host{ln: ln}, nil
+}
+
+// Addr returns the address the host listens on.
+func (h *Host) Addr() net.Addr {
+	return h.ln.Addr()
+}
+
+// Close stops listening. It must not be called once Accept returned a
+// session, which then owns the listener.
+func (h *Host) Close() error {
+	return h.ln.Close()
+}
+
+// Accept waits for a guest, assigns the colours and starts the game. The
+// session keeps listening so that the guest can reconnect.
+func (h *Host) Accept(opts Options) (*Session, error) {
+	for {
+		nc, err := h.ln.Accept()
+		if err != nil {
+			return nil, err
+		}
+		c := newConn(nc, handshakeTimeout)
+		hello, err := c.receive()
+		if err == nil && hello.Type == MsgHello && hello.Token != "" {
+			err = errors.New("netplay: unknown game")
+		}
+		if err == nil {
+			err = checkHello(hello)
+		}
+		if err != nil {
+			c.send(Message{Type: MsgError, Text: err.Error()})
+			c.close()
+			continue
+		}
+		guestColor, _ := parseColor(hello.Color)
+		local := negotiate(opts.Color, guestColor)
+		s := newSession(local, hello.Name, newToken(), opts)
+		welcome := Message{Type: MsgWelcome, Version: Version, Name: opts.Name, Color: colorName(game.Opponent(local)), Token: s.token, Komi: opts.Komi}
+		if err := c.send(welcome); err != nil {
+			c.close()
+			continue
+		}
+		c.timeout = opts.timeout()
+		s.conn = c
+		go s.hostLoop(h.ln, c)
+		return s, nil
+	}
+}
+
+// checkHello validates the first message of a guest.
+func checkHello(m Message) error {
+	if m.Type != MsgHello {
+		return fmt.Errorf("netplay: expected hello, got %q", m.Type)
+	}
+	if m.Version != Version {
+		return fmt.Errorf("netplay: unsupported protocol version %d", m.Version)
+	}
+	if _, ok := parseColor(m.Color); !ok {
+		return fmt.Errorf("netplay: unknown colour %q", m.Color)
+	}
+	return nil
+}
+
+// negotiate returns the host's colour given both preferences.
+func negotiate(host, guest game.FieldState) game.FieldState {
+	switch {
+	case host != game.Empty:
+		return host
+	case guest != game.Empty:
+		return game.Opponent(guest)
+	}
+	var b [1]byte
+	rand.Read(b[:])
+	if b[0]&1 == 0 {
+		return game.Black
+	}
+	return game.White
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// hostLoop serves the first connection and accepts reconnections of the
+// guest until the session is closed.
+func (s *Session) hostLoop(ln net.Listener, first *conn) {
+	go func() {
+		<-s.done
+		ln.Close()
+	}()
+	go s.readLoop(first)
+	for {
+		nc, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.rejoin(newConn(nc, handshakeTimeout))
+	}
+}
+
+// rejoin resumes the game with a reconnecting guest.
+func (s *Session) rejoin(c *conn) {
+	hello, err := c.receive()
+	if err == nil {
+		err = checkHello(hello)
+	}
+	if err == nil && hello.Token != s.token {
+		err = errors.New("netplay: a game is in progress")
+	}
+	var moves []*game.Point
+	if err == nil {
+		moves, err = decodeMoves(hello.Moves)
+	}
+	if err != nil {
+		c.send(Message{Type: MsgError, Text: err.Error()})
+		c.close()
+		return
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		c.close()
+		return
+	}
+	// The guest's game wins if it continues ours, e.g. with a move we
+	// never received. Its result is not taken on trust: passes are counted
+	// again and only the guest's own resignation, or ours, is kept.
+	var replayErr error
+	if isPrefix(s.moves, moves) && (len(moves) > len(s.moves) || s.result == "") {
+		resigned := game.Empty
+		switch remote := game.Opponent(s.Local); {
+		case s.result == resignation(s.Local):
+			resigned = s.Local
+		case resigner(hello.Result) == remote:
+			resigned = remote
+		}
+		replayErr = s.replay(moves, resigned)
+	}
+	old := s.conn
+	c.timeout = s.opts.timeout()
+	s.conn = c
+	s.pendingUndo, s.incomingUndo = -1, -1
+	welcome := Message{Type: MsgWelcome, Version: Version, Name: s.opts.Name, Color: colorName(game.Opponent(s.Local)), Token: s.token,
+		Komi: s.Komi, Moves: encodeMoves(s.moves), Result: s.result}
+	err = c.send(welcome)
+	s.mu.Unlock()
+	if old != nil {
+		old.close()
+	}
+	if err != nil {
+		c.close()
+		return
+	}
+	if replayErr != nil {
+		s.emit(Event{Type: EventError, Err: fmt.Errorf("netplay: resync: %w", replayErr)})
+	}
+	s.emit(Event{Type: EventReconnected})
+	s.readLoop(c)
+}
+
+// Dial joins the game hosted at addr.
+func Dial(addr string, opts Options) (*Session, error) {
+	c, welcome, err := handshake(addr, Message{Type: MsgHello, Version: Version, Name: opts.Name, Color: colorName(opts.Color)})
+	if err != nil {
+		return nil, err
+	}
+	local, ok := parseColor(welcome.Color)
+	if !ok || local == game.Empty {
+		c.close()
+		return nil, fmt.Errorf("netplay: invalid colour %q", welcome.Color)
+	}
+	opts.Komi = welcome.Komi
+	s := newSession(local, welcome.Name, welcome.Token, opts)
+	c.timeout = opts.timeout()
+	s.conn = c
+	go s.guestLoop(addr, c)
+	return s, nil
+}
+
+// handshake connects to addr, sends hello and returns the welcome.
+func handshake(addr string, hello Message) (*conn, Message, error) {
+	nc, err := net.DialTimeout("tcp", addr, handshakeTimeout)
+	if err != nil {
+		return nil, Message{}, err
+	}
+	c := newConn(nc, handshakeTimeout)
+	welcome, err := func() (Message, error) {
+		if err := c.send(hello); err != nil {
+			return Message{}, err
+		}
+		m, err := c.receive()
+		switch {
+		case err != nil:
+			return m, err
+		case m.Type == MsgError:
+			return m, fmt.Errorf("netplay: host: %s", m.Text)
+		case m.Type != MsgWelcome:
+			return m, fmt.Errorf("netplay: expected welcome, got %q", m.Type)
+		}
+		return m, nil
+	}()
+	if err != nil {
+		c.close()
+		return nil, Message{}, err
+	}
+	return c, welcome, nil
+}
+
+// guestLoop serves the connection and reconnects whenever it is lost,
+// until the session is closed or the host leaves.
+func (s *Session) guestLoop(addr string, c *conn) {
+	for s.readLoop(c) {
+		c = s.reconnect(addr)
+		if c == nil {
+			return
+		}
+		s.emit(Event{Type: EventReconnected})
+	}
+}
+
+// reconnect redials addr until the host takes the guest back, and adopts
+// the host's game. It returns nil if the session is closed meanwhile.
+func (s *Session) reconnect(addr string) *conn {
+	for {
+		select {
+		case <-s.done:
+			return nil
+		case <-time.After(s.opts.retryInterval()):
+		}
+		s.mu.Lock()
+		hello := Message{Type: MsgHello, Version: Version, Name: s.opts.Name, Color: colorName(s.Local), Token: s.token,
+			Moves: encodeMoves(s.moves), Result: s.result}
+		s.mu.Unlock()
+		c, welcome, err := handshake(addr, hello)
+		if err != nil {
+			continue
+		}
+		moves, err := decodeMoves(welcome.Moves)
+		s.mu.Lock()
+		if err == nil {
+			err = s.replay(moves, resigner(welcome.Result))
+		}
+		if err != nil || s.closed {
+			s.mu.Unlock()
+			c.close()
+			if err != nil {
+				s.emit(Event{Type: EventError, Err: fmt.Errorf("netplay: resync: %w", err)})
+			}
+			continue
+		}
+		c.timeout = s.opts.timeout()
+		s.conn = c
+		s.pendingUndo, s.incomingUndo = -1, -1
+		s.mu.Unlock()
+		return c
+	}
+}
+
+// isPrefix reports whether a is a prefix of b.
+func isPrefix(a, b []*game.Point) bool {
+	if len(a) > len(b) {
+		return false
+	}
+	for i, p := range a {
+		q := b[i]
+		if (p == nil) != (q == nil) || p != nil && *p != *q {
+			return false
+		}
+	}
+	return true
+}
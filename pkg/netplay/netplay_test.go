@@ -0,0 +1,317 @@
+package netplay
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+var testOptions = Options{Keepalive: 50 * time.Millisecond, RetryInterval: 10 * time.Millisecond}
+
+// connect returns a hosted and a joined session on localhost.
+func connect(t *testing.T, host, guest Options) (*Session, *Session) {
+	t.Helper()
+	h, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accepted := make(chan *Session, 1)
+	go func() {
+		s, err := h.Accept(host)
+		if err != nil {
+			t.Error(err)
+		}
+		accepted <- s
+	}()
+	g, err := Dial(h.Addr().String(), guest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := <-accepted
+	if s == nil {
+		t.FailNow()
+	}
+	t.Cleanup(func() {
+		s.Close()
+		g.Close()
+	})
+	return s, g
+}
+
+func options(name string, color game.FieldState) Options {
+	o := testOptions
+	o.Name, o.Color = name, color
+	return o
+}
+
+// next returns the next event of s that is not of a skipped type.
+func next(t *testing.T, s *Session, skip ...EventType) Event {
+	t.Helper()
+	for {
+		select {
+		case e := <-s.Events():
+			skipped := false
+			for _, typ := range skip {
+				skipped = skipped || e.Type == typ
+			}
+			if !skipped {
+				return e
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected an event")
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	cases := []struct{ host, guest, want game.FieldState }{
+		{game.Black, game.Black, game.Black},
+		{game.White, game.Empty, game.White},
+		{game.Empty, game.Black, game.White},
+		{game.Empty, game.White, game.Black},
+	}
+	for _, c := range cases {
+		if got := negotiate(c.host, c.guest); got != c.want {
+			t.Errorf("Expected host colour %v for preferences %v/%v, got %v", c.want, c.host, c.guest, got)
+		}
+	}
+	if got := negotiate(game.Empty, game.Empty); got != game.Black && got != game.White {
+		t.Errorf("Expected a random colour, got %v", got)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	host, guest := connect(t, options("alice", game.Empty), options("bob", game.White))
+	if host.Local != game.Black || guest.Local != game.White || host.Opponent != "bob" || guest.Opponent != "alice" {
+		t.Fatalf("Expected the guest's preference to decide, got host %v (%q) and guest %v (%q)", host.Local, host.Opponent, guest.Local, guest.Opponent)
+	}
+	if err := guest.Play(&game.Point{Row: 0, Col: 0}); !errors.Is(err, ErrNotYourTurn) {
+		t.Errorf("Expected ErrNotYourTurn, got %v", err)
+	}
+	moves := []struct {
+		s *Session
+		p game.Point
+	}{{host, game.Point{Row: 0, Col: 1}}, {guest, game.Point{Row: 0, Col: 0}}, {host, game.Point{Row: 1, Col: 0}}}
+	for _, m := range moves {
+		if err := m.s.Play(&m.p); err != nil {
+			t.Fatal(err)
+		}
+		receiver := host
+		if m.s == host {
+			receiver = guest
+		}
+		if e := next(t, receiver); e.Type != EventMove || *e.Point != m.p || e.Color != m.s.Local {
+			t.Errorf("Expected the move %v, got %+v", m.p, e)
+		}
+	}
+	if b := guest.State().Board; b[0][0] != game.Empty || b[1][0] != game.Black {
+		t.Errorf("Expected the capture on the guest's board, got %v", b)
+	}
+	if host.State().Board != guest.State().Board {
+		t.Errorf("Expected both sides to have the same position")
+	}
+	if err := guest.Play(&game.Point{Row: 0, Col: 1}); !errors.Is(err, game.ErrOccupied) {
+		t.Errorf("Expected ErrOccupied, got %v", err)
+	}
+
+	guest.Play(nil)
+	next(t, host)
+	host.Play(nil)
+	if e := next(t, guest); e.Type != EventMove || e.Point != nil {
+		t.Errorf("Expected a pass, got %+v", e)
+	}
+	if e := next(t, guest); e.Type != EventGameOver || e.Text != "B+81" {
+		t.Errorf("Expected the game to end with B+81, got %+v", e)
+	}
+	if host.Result() != "B+81" || !errors.Is(host.Play(nil), ErrGameOver) {
+		t.Errorf("Expected the host to see the game over, got %q", host.Result())
+	}
+}
+
+func TestRejectsIllegalMoves(t *testing.T) {
+	host, guest := connect(t, options("", game.Black), options("", game.Empty))
+	host.Play(&game.Point{Row: 4, Col: 4})
+	next(t, guest)
+	// A move the guest's side would never send.
+	guest.mu.Lock()
+	guest.conn.send(Message{Type: MsgMove, Number: 2, Point: "ee"})
+	guest.mu.Unlock()
+	if e := next(t, host); e.Type != EventError || !errors.Is(e.Err, game.ErrOccupied) {
+		t.Errorf("Expected the host to reject the move, got %+v", e)
+	}
+	if e := next(t, guest); e.Type != EventError {
+		t.Errorf("Expected the guest to hear about it, got %+v", e)
+	}
+	if len(host.Moves()) != 1 {
+		t.Errorf("Expected the illegal move not to be played")
+	}
+}
+
+func TestResignUndoAndChat(t *testing.T) {
+	host, guest := connect(t, options("", game.Black), options("", game.Empty))
+	host.Play(&game.Point{Row: 4, Col: 4})
+	next(t, guest)
+	guest.Play(&game.Point{Row: 3, Col: 3})
+	next(t, host)
+
+	if err := host.RequestUndo(); err != nil {
+		t.Fatal(err)
+	}
+	if e := next(t, guest); e.Type != EventUndoRequest {
+		t.Fatalf("Expected an undo request, got %+v", e)
+	}
+	if err := guest.ReplyUndo(true); err != nil {
+		t.Fatal(err)
+	}
+	if e := next(t, host); e.Type != EventUndoReply || !e.Accept {
+		t.Fatalf("Expected the undo to be accepted, got %+v", e)
+	}
+	if len(host.Moves()) != 0 || len(guest.Moves()) != 0 || host.State().ToMove != game.Black {
+		t.Errorf("Expected both moves to be taken back, got %v and %v", host.Moves(), guest.Moves())
+	}
+	if err := guest.ReplyUndo(true); !errors.Is(err, ErrNoUndoRequest) {
+		t.Errorf("Expected ErrNoUndoRequest, got %v", err)
+	}
+
+	guest.Chat("good game")
+	if e := next(t, host); e.Type != EventChat || e.Text != "good game" || e.Color != game.White {
+		t.Errorf("Expected the chat message, got %+v", e)
+	}
+
+	guest.Resign()
+	if e := next(t, host); e.Type != EventResign || e.Color != game.White {
+		t.Errorf("Expected the resignation, got %+v", e)
+	}
+	if host.Result() != "B+R" || guest.Result() != "B+R" {
+		t.Errorf("Expected B+R on both sides, got %q and %q", host.Result(), guest.Result())
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	host, guest := connect(t, options("", game.Black), options("", game.Empty))
+	host.Play(&game.Point{Row: 4, Col: 4})
+	next(t, guest)
+
+	// The guest's move is lost with the connection.
+	guest.mu.Lock()
+	guest.apply(&game.Point{Row: 3, Col: 3})
+	guest.conn.close()
+	guest.mu.Unlock()
+
+	if e := next(t, guest); e.Type != EventDisconnected {
+		t.Fatalf("Expected the guest to notice the lost connection, got %+v", e)
+	}
+	if e := next(t, guest, EventDisconnected); e.Type != EventReconnected {
+		t.Fatalf("Expected the guest to reconnect, got %+v", e)
+	}
+	if e := next(t, host, EventDisconnected); e.Type != EventReconnected {
+		t.Fatalf("Expected the host to take the guest back, got %+v", e)
+	}
+	if len(host.Moves()) != 2 || host.State().Board != guest.State().Board {
+		t.Errorf("Expected the host to adopt the guest's longer game, got %v", host.Moves())
+	}
+	if err := host.Play(&game.Point{Row: 5, Col: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if e := next(t, guest); e.Type != EventMove || e.Point.Row != 5 {
+		t.Errorf("Expected play to continue after reconnecting, got %+v", e)
+	}
+}
+
+func TestRejoinChecksTheGuestsGame(t *testing.T) {
+	host, _ := connect(t, options("", game.Black), options("", game.Empty))
+	addr := host.conn.nc.LocalAddr().String()
+	rejoin := func(moves []*game.Point, result string) Message {
+		t.Helper()
+		c, welcome, err := handshake(addr, Message{Type: MsgHello, Version: Version, Token: host.token, Moves: encodeMoves(moves), Result: result})
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.close()
+		return welcome
+	}
+
+	if welcome := rejoin(nil, "W+R"); welcome.Result != "" || host.Result() != "" {
+		t.Errorf("Expected a resignation of the host to be refused, got %q and %q", welcome.Result, host.Result())
+	}
+	p := &game.Point{Row: 4, Col: 4}
+	if welcome := rejoin([]*game.Point{p, p}, ""); len(welcome.Moves) != 0 || len(host.Moves()) != 0 {
+		t.Errorf("Expected illegal moves to be refused, got %v", welcome.Moves)
+	}
+	if e := next(t, host, EventDisconnected, EventReconnected); e.Type != EventError {
+		t.Errorf("Expected the host to report the illegal moves, got %+v", e)
+	}
+	if welcome := rejoin(nil, "B+R"); welcome.Result != "B+R" || host.Result() != "B+R" {
+		t.Errorf("Expected the guest's resignation to be kept, got %q and %q", welcome.Result, host.Result())
+	}
+}
+
+func TestLeave(t *testing.T) {
+	host, guest := connect(t, options("", game.Black), options("", game.Empty))
+	host.Close()
+	if e := next(t, guest); e.Type != EventLeft {
+		t.Errorf("Expected the guest to see the host leave, got %+v", e)
+	}
+	if err := guest.Play(&game.Point{}); !errors.Is(err, ErrDisconnected) {
+		t.Errorf("Expected ErrDisconnected, got %v", err)
+	}
+}
+
+func TestRejectsStrangers(t *testing.T) {
+	host, _ := connect(t, options("", game.Black), options("", game.Empty))
+	_, _, err := handshake(host.conn.nc.LocalAddr().String(), Message{Type: MsgHello, Version: Version, Token: "nope"})
+	if err == nil {
+		t.Errorf("Expected a stranger to be turned away")
+	}
+	if _, err := Dial(host.conn.nc.LocalAddr().String(), Options{}); err == nil {
+		t.Errorf("Expected a second guest to be turned away")
+	}
+}
+
+func TestRecordKeepsChatAndUndo(t *testing.T) {
+	host, guest := connect(t, options("alice", game.Black), options("bob", game.Empty))
+	guest.Chat("have fun")
+	next(t, host)
+	host.Play(&game.Point{Row: 4, Col: 4})
+	next(t, guest)
+	guest.Play(&game.Point{Row: 3, Col: 3})
+	next(t, host)
+	guest.RequestUndo()
+	next(t, host)
+	host.ReplyUndo(false)
+	next(t, guest)
+	host.Chat("no takebacks")
+	next(t, guest)
+
+	want := []string{"bob: have fun", "bob asked to undo", "alice declined the undo", "alice: no takebacks"}
+	for _, s := range []*Session{host, guest} {
+		log := s.Log()
+		if len(log) != len(want) {
+			t.Fatalf("Expected log %q, got %q", want, log)
+		}
+		for i := range want {
+			if log[i] != want[i] {
+				t.Errorf("Expected log %q, got %q", want, log)
+				break
+			}
+		}
+		record := s.Record()
+		if record.Black != "alice" || record.White != "bob" || record.Comment != "bob: have fun" {
+			t.Errorf("Expected the players and the first chat on the root, got %+v", record)
+		}
+		if len(record.Moves) != 2 || record.Moves[1].Comment != "bob asked to undo\nalice declined the undo\nalice: no takebacks" {
+			t.Errorf("Expected the undo and chat on the second move, got %+v", record.Moves)
+		}
+	}
+
+	host.RequestUndo()
+	next(t, guest)
+	guest.ReplyUndo(true)
+	next(t, host)
+	record := host.Record()
+	if len(record.Moves) != 0 || record.Comment == "" || len(host.Log()) != 6 {
+		t.Errorf("Expected the log of the moves taken back to move to the root, got %+v", record)
+	}
+}
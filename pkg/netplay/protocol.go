@@ -0,0 +1,156 @@
+// Package netplay lets two players play a game over TCP. One player hosts
+// and the other joins; both validate every move with pkg/game, so a
+// misbehaving peer cannot put an illegal position on the board.
+//
+// The protocol is a stream of JSON messages, one per line. The guest opens
+// with hello, stating its colour preference, and the host answers with
+// welcome, assigning colours. Afterwards either side sends move, pass and
+// resign for its own moves, undo-request and undo-reply to take back
+// moves, chat, and ping to keep the connection alive. bye announces that
+// a player left.
+//
+// A guest that loses the connection redials and sends hello with the token
+// of the game and its move list; the host keeps the longer list if the
+// other is a prefix of it and its own otherwise, and returns it with
+// welcome.
+package netplay
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/sgf"
+)
+
+// Version is the protocol version sent with hello.
+const Version = 1
+
+// Message types.
+const (
+	MsgHello       = "hello"
+	MsgWelcome     = "welcome"
+	MsgMove        = "move"
+	MsgPass        = "pass"
+	MsgResign      = "resign"
+	MsgUndoRequest = "undo-request"
+	MsgUndoReply   = "undo-reply"
+	MsgChat        = "chat"
+	MsgPing        = "ping"
+	MsgBye         = "bye"
+	MsgError       = "error"
+)
+
+// Message is a protocol message. Points are SGF coordinates.
+type Message struct {
+	Type    string `json:"type"`
+	Version int    `json:"version,omitempty"`
+	Name    string `json:"name,omitempty"`
+	// Color is the preferred colour in hello and the guest's colour in
+	// welcome: "black", "white" or empty for either.
+	Color string `json:"color,omitempty"`
+	// Token identifies the game when a guest reconnects.
+	Token string  `json:"token,omitempty"`
+	Komi  float64 `json:"komi,omitempty"`
+	// Moves and Result are the game so far in hello and welcome.
+	Moves  []string `json:"moves,omitempty"`
+	Result string   `json:"result,omitempty"`
+	// Number is the number of the move played, or of the moves kept by
+	// an undo.
+	Number int    `json:"number,omitempty"`
+	Point  string `json:"point,omitempty"`
+	Accept bool   `json:"accept,omitempty"`
+	Text   string `json:"text,omitempty"`
+}
+
+// conn sends and receives messages on a connection.
+type conn struct {
+	nc      net.Conn
+	r       *bufio.Reader
+	timeout time.Duration // read timeout, 0 for none
+	mu      sync.Mutex    // serialises writes
+}
+
+func newConn(nc net.Conn, timeout time.Duration) *conn {
+	return &conn{nc: nc, r: bufio.NewReader(nc), timeout: timeout}
+}
+
+func (c *conn) send(m Message) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.timeout > 0 {
+		c.nc.SetWriteDeadline(time.Now().Add(c.timeout))
+	}
+	_, err = c.nc.Write(append(data, '\n'))
+	return err
+}
+
+func (c *conn) receive() (Message, error) {
+	if c.timeout > 0 {
+		c.nc.SetReadDeadline(time.Now().Add(c.timeout))
+	}
+	var m Message
+	line, err := c.r.ReadBytes('\n')
+	if err != nil {
+		return m, err
+	}
+	err = json.Unmarshal(line, &m)
+	return m, err
+}
+
+func (c *conn) close() error {
+	return c.nc.Close()
+}
+
+// colorName returns the protocol name of c.
+func colorName(c game.FieldState) string {
+	switch c {
+	case game.Black:
+		return "black"
+	case game.White:
+		return "white"
+	}
+	return ""
+}
+
+// parseColor parses a protocol colour; the empty string is game.Empty.
+func parseColor(s string) (game.FieldState, bool) {
+	switch s {
+	case "black":
+		return game.Black, true
+	case "white":
+		return game.White, true
+	case "":
+		return game.Empty, true
+	}
+	return game.Empty, false
+}
+
+// encodeMoves returns the SGF coordinates of moves.
+func encodeMoves(moves []*game.Point) []string {
+	out := make([]string, len(moves))
+	for i, p := range moves {
+		out[i] = sgf.EncodePoint(p)
+	}
+	return out
+}
+
+// decodeMoves parses SGF coordinates.
+func decodeMoves(points []string) ([]*game.Point, error) {
+	moves := make([]*game.Point, len(points))
+	for i, s := range points {
+		p, err := sgf.DecodePoint(s)
+		if err != nil {
+			return nil, err
+		}
+		moves[i] = p
+	}
+	return moves, nil
+}
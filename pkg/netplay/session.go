@@ -0,0 +1,565 @@
+package netplay
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/sgf"
+)
+
+var (
+	ErrNotYourTurn   = errors.New("netplay: not your turn")
+	ErrGameOver      = errors.New("netplay: game is over")
+	ErrDisconnected  = errors.New("netplay: not connected to the opponent")
+	ErrNothingToUndo = errors.New("netplay: nothing to undo")
+	ErrNoUndoRequest = errors.New("netplay: no undo request to answer")
+)
+
+// EventType says what an Event reports.
+type EventType int
+
+const (
+	// EventMove is a move or pass of the opponent; Point is nil for a pass.
+	EventMove EventType = iota
+	// EventResign is the opponent's resignation.
+	EventResign
+	// EventGameOver follows the move that ended the game.
+	EventGameOver
+	// EventUndoRequest asks to take back moves; answer with ReplyUndo.
+	EventUndoRequest
+	// EventUndoReply answers our undo request; Accept says whether the
+	// moves were taken back.
+	EventUndoReply
+	// EventChat carries a chat message in Text.
+	EventChat
+	// EventDisconnected and EventReconnected report the state of the
+	// connection; the game continues after a reconnect.
+	EventDisconnected
+	EventReconnected
+	// EventLeft reports that the opponent closed the session.
+	EventLeft
+	// EventError reports a message of the opponent that was rejected, or
+	// an error the opponent reported, in Err.
+	EventError
+)
+
+// Event is something the opponent did or a change of the connection.
+type Event struct {
+	Type   EventType
+	Color  game.FieldState
+	Point  *game.Point
+	Accept bool
+	Text   string
+	Err    error
+}
+
+// Options configure a player's side of a session.
+type Options struct {
+	Name string
+	// Color is the preferred colour, game.Empty for either. The host's
+	// preference wins; if neither player has one, colours are chosen at
+	// random.
+	Color game.FieldState
+	// Komi is set by the host.
+	Komi float64
+	// Keepalive is the interval of pings; a connection silent for three
+	// intervals is considered lost. Defaults to 5 seconds.
+	Keepalive time.Duration
+	// RetryInterval is the delay between the guest's reconnection
+	// attempts. Defaults to 1 second.
+	RetryInterval time.Duration
+}
+
+func (o Options) keepalive() time.Duration {
+	if o.Keepalive <= 0 {
+		return 5 * time.Second
+	}
+	return o.Keepalive
+}
+
+func (o Options) retryInterval() time.Duration {
+	if o.RetryInterval <= 0 {
+		return time.Second
+	}
+	return o.RetryInterval
+}
+
+// Session is one player's side of a game. Its methods are safe for
+// concurrent use; events of the opponent are delivered on Events.
+type Session struct {
+	// Local is the colour of this player.
+	Local game.FieldState
+	// Opponent is the name the opponent gave.
+	Opponent string
+	Komi     float64
+
+	opts   Options
+	token  string
+	events chan Event
+	done   chan struct{}
+
+	mu     sync.Mutex
+	conn   *conn // nil while disconnected
+	states []game.State
+	moves  []*game.Point
+	result string
+	closed bool
+	left   bool
+	// pendingUndo and incomingUndo are the move counts of our open undo
+	// request and of the opponent's, -1 if there is none.
+	pendingUndo  int
+	incomingUndo int
+	notes        []note
+}
+
+// note is a line of the game log written after the first after moves.
+type note struct {
+	after int
+	text  string
+}
+
+func newSession(local game.FieldState, opponent, token string, opts Options) *Session {
+	return &Session{
+		Local:        local,
+		Opponent:     opponent,
+		Komi:         opts.Komi,
+		opts:         opts,
+		token:        token,
+		events:       make(chan Event, 64),
+		done:         make(chan struct{}),
+		states:       []game.State{game.NewState(game.Board{}, game.Black)},
+		pendingUndo:  -1,
+		incomingUndo: -1,
+	}
+}
+
+// Events returns the channel of the opponent's actions and connection
+// changes. No events are delivered after Close.
+func (s *Session) Events() <-chan Event {
+	return s.events
+}
+
+// State returns the current position.
+func (s *Session) State() game.State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.states[len(s.states)-1]
+}
+
+// Moves returns the moves played so far; a nil point is a pass.
+func (s *Session) Moves() []sgf.Move {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	moves := make([]sgf.Move, len(s.moves))
+	for i, p := range s.moves {
+		moves[i] = sgf.Move{Color: s.states[i].ToMove, Point: p}
+	}
+	return moves
+}
+
+// Result returns the SGF result once the game is over, else "".
+func (s *Session) Result() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.result
+}
+
+// Connected reports whether the opponent is connected.
+func (s *Session) Connected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn != nil
+}
+
+// Play plays p for the local player, nil for a pass, and sends it.
+func (s *Session) Play(p *game.Point) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(); err != nil {
+		return err
+	}
+	if s.states[len(s.states)-1].ToMove != s.Local {
+		return ErrNotYourTurn
+	}
+	if err := s.apply(p); err != nil {
+		return err
+	}
+	typ := MsgMove
+	if p == nil {
+		typ = MsgPass
+	}
+	s.send(Message{Type: typ, Number: len(s.moves), Point: sgf.EncodePoint(p)})
+	return nil
+}
+
+// Resign resigns the game for the local player.
+func (s *Session) Resign() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(); err != nil {
+		return err
+	}
+	s.result = resignation(s.Local)
+	s.send(Message{Type: MsgResign})
+	return nil
+}
+
+// RequestUndo asks the opponent to take back the local player's last move
+// and any opponent move after it.
+func (s *Session) RequestUndo() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(); err != nil {
+		return err
+	}
+	keep := -1
+	for i := len(s.moves) - 1; i >= 0; i-- {
+		if s.states[i].ToMove == s.Local {
+			keep = i
+			break
+		}
+	}
+	if keep < 0 {
+		return ErrNothingToUndo
+	}
+	s.pendingUndo = keep
+	s.note(s.name(s.Local) + " asked to undo")
+	s.send(Message{Type: MsgUndoRequest, Number: keep})
+	return nil
+}
+
+// ReplyUndo answers the opponent's undo request, taking back the moves if
+// accept is true.
+func (s *Session) ReplyUndo(accept bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.incomingUndo < 0 {
+		return ErrNoUndoRequest
+	}
+	if s.conn == nil {
+		return ErrDisconnected
+	}
+	keep := s.incomingUndo
+	s.incomingUndo = -1
+	s.note(s.name(s.Local) + undoAnswer(accept))
+	if accept {
+		s.truncate(keep)
+	}
+	s.send(Message{Type: MsgUndoReply, Number: keep, Accept: accept})
+	return nil
+}
+
+// Chat sends a chat message.
+func (s *Session) Chat(text string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return ErrDisconnected
+	}
+	s.note(s.name(s.Local) + ": " + text)
+	s.send(Message{Type: MsgChat, Text: text})
+	return nil
+}
+
+// Close tells the opponent that the player left and ends the session.
+func (s *Session) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	c := s.conn
+	s.conn = nil
+	close(s.done)
+	s.mu.Unlock()
+	if c != nil {
+		c.send(Message{Type: MsgBye})
+		c.close()
+	}
+	return nil
+}
+
+// ready returns the error preventing the local player from acting.
+func (s *Session) ready() error {
+	switch {
+	case s.result != "":
+		return ErrGameOver
+	case s.conn == nil:
+		return ErrDisconnected
+	}
+	return nil
+}
+
+// apply plays p for the side to move; two passes in a row end the game.
+func (s *Session) apply(p *game.Point) error {
+	if s.result != "" {
+		return ErrGameOver
+	}
+	next, _, err := game.Play(s.states[len(s.states)-1], p)
+	if err != nil {
+		return err
+	}
+	s.states = append(s.states, next)
+	s.moves = append(s.moves, p)
+	if n := len(s.moves); n >= 2 && s.moves[n-1] == nil && s.moves[n-2] == nil {
+		black, white := game.CalculateScore(next.Board)
+		s.result = sgf.FormatResult(float64(black), float64(white)+s.Komi)
+	}
+	return nil
+}
+
+// truncate takes back all moves after the first keep and reopens the game.
+// Notes written after the moves taken back move to the last move kept.
+func (s *Session) truncate(keep int) {
+	if keep >= len(s.moves) {
+		return
+	}
+	for i := range s.notes {
+		s.notes[i].after = min(s.notes[i].after, keep)
+	}
+	s.states = s.states[:keep+1]
+	s.moves = s.moves[:keep]
+	s.result = ""
+}
+
+// replay replaces the game with moves if they are legal. The result of
+// two passes is counted again; resigned, unless Empty, is the colour that
+// resigned after the moves.
+func (s *Session) replay(moves []*game.Point, resigned game.FieldState) error {
+	states, played, prev := s.states, s.moves, s.result
+	s.states, s.moves, s.result = s.states[:1:1], nil, ""
+	for _, p := range moves {
+		if err := s.apply(p); err != nil {
+			s.states, s.moves, s.result = states, played, prev
+			return err
+		}
+	}
+	if s.result == "" && resigned != game.Empty {
+		s.result = resignation(resigned)
+	}
+	return nil
+}
+
+// send sends m on the current connection. A failed send is not an error of
+// the caller: the read loop notices the lost connection and the game is
+// resynchronised after reconnecting.
+func (s *Session) send(m Message) {
+	if s.conn != nil {
+		s.conn.send(m)
+	}
+}
+
+// emit delivers e unless the session is closed.
+func (s *Session) emit(e Event) {
+	select {
+	case s.events <- e:
+	case <-s.done:
+	}
+}
+
+// handle applies a message of the opponent.
+func (s *Session) handle(m Message) {
+	s.mu.Lock()
+	remote := game.Opponent(s.Local)
+	var events []Event
+	reject := func(err error) {
+		events = append(events, Event{Type: EventError, Err: err})
+		s.send(Message{Type: MsgError, Text: err.Error()})
+	}
+	switch m.Type {
+	case MsgMove, MsgPass:
+		p, err := sgf.DecodePoint(m.Point)
+		switch {
+		case err != nil:
+			reject(err)
+		case m.Type == MsgMove && p == nil:
+			reject(errors.New("netplay: move without a point"))
+		case s.states[len(s.states)-1].ToMove != remote || m.Number != len(s.moves)+1:
+			reject(fmt.Errorf("netplay: unexpected move %d", m.Number))
+		default:
+			if err := s.apply(p); err != nil {
+				reject(err)
+				break
+			}
+			events = append(events, Event{Type: EventMove, Color: remote, Point: p})
+			if s.result != "" {
+				events = append(events, Event{Type: EventGameOver, Text: s.result})
+			}
+		}
+	case MsgResign:
+		if s.result == "" {
+			s.result = resignation(remote)
+			events = append(events, Event{Type: EventResign, Color: remote}, Event{Type: EventGameOver, Text: s.result})
+		}
+	case MsgUndoRequest:
+		if m.Number < 0 || m.Number >= len(s.moves) {
+			reject(fmt.Errorf("netplay: cannot undo to move %d", m.Number))
+			break
+		}
+		s.incomingUndo = m.Number
+		s.note(s.name(remote) + " asked to undo")
+		events = append(events, Event{Type: EventUndoRequest, Color: remote})
+	case MsgUndoReply:
+		if m.Number != s.pendingUndo {
+			break
+		}
+		s.pendingUndo = -1
+		s.note(s.name(remote) + undoAnswer(m.Accept))
+		if m.Accept {
+			s.truncate(m.Number)
+		}
+		events = append(events, Event{Type: EventUndoReply, Color: remote, Accept: m.Accept})
+	case MsgChat:
+		s.note(s.name(remote) + ": " + m.Text)
+		events = append(events, Event{Type: EventChat, Color: remote, Text: m.Text})
+	case MsgError:
+		events = append(events, Event{Type: EventError, Err: fmt.Errorf("netplay: opponent: %s", m.Text)})
+	case MsgBye:
+		s.left = true
+		events = append(events, Event{Type: EventLeft, Color: remote})
+	case MsgPing:
+	default:
+		reject(fmt.Errorf("netplay: unexpected message %q", m.Type))
+	}
+	s.mu.Unlock()
+	for _, e := range events {
+		s.emit(e)
+	}
+}
+
+// readLoop handles the messages of c until it fails or the opponent leaves,
+// sending pings meanwhile. It reports whether c was the current connection
+// when it was lost, that is whether the caller should reconnect.
+func (s *Session) readLoop(c *conn) bool {
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		t := time.NewTicker(s.opts.keepalive())
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				c.send(Message{Type: MsgPing})
+			case <-stop:
+				return
+			}
+		}
+	}()
+	for {
+		m, err := c.receive()
+		if err != nil {
+			break
+		}
+		s.handle(m)
+		if m.Type == MsgBye {
+			break
+		}
+	}
+	c.close()
+	s.mu.Lock()
+	lost := s.conn == c && !s.closed
+	if lost {
+		s.conn = nil
+	}
+	reconnect := lost && !s.left
+	s.mu.Unlock()
+	if reconnect {
+		s.emit(Event{Type: EventDisconnected})
+	}
+	return reconnect
+}
+
+// Log returns the chat messages and undo requests of the game in order.
+func (s *Session) Log() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lines := make([]string, len(s.notes))
+	for i, n := range s.notes {
+		lines[i] = n.text
+	}
+	return lines
+}
+
+// Record returns the game record. The log is kept as comments on the move
+// after which it was written, or on the root before the first move.
+func (s *Session) Record() *sgf.Game {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g := &sgf.Game{Komi: s.Komi, Result: s.result}
+	if s.Local == game.Black {
+		g.Black, g.White = s.opts.Name, s.Opponent
+	} else {
+		g.Black, g.White = s.Opponent, s.opts.Name
+	}
+	for i, p := range s.moves {
+		g.Moves = append(g.Moves, sgf.Move{Color: s.states[i].ToMove, Point: p})
+	}
+	for _, n := range s.notes {
+		comment := &g.Comment
+		if n.after > 0 {
+			comment = &g.Moves[n.after-1].Comment
+		}
+		if *comment != "" {
+			*comment += "\n"
+		}
+		*comment += n.text
+	}
+	return g
+}
+
+// note adds a line to the log.
+func (s *Session) note(text string) {
+	s.notes = append(s.notes, note{after: len(s.moves), text: text})
+}
+
+// name returns the name of the player of color, or the colour if the
+// player gave none.
+func (s *Session) name(color game.FieldState) string {
+	name := s.Opponent
+	if color == s.Local {
+		name = s.opts.Name
+	}
+	if name != "" {
+		return name
+	}
+	if color == game.Black {
+		return "Black"
+	}
+	return "White"
+}
+
+func undoAnswer(accept bool) string {
+	if accept {
+		return " accepted the undo"
+	}
+	return " declined the undo"
+}
+
+// resignation returns the result of a resignation by color.
+func resignation(color game.FieldState) string {
+	if color == game.Black {
+		return "W+R"
+	}
+	return "B+R"
+}
+
+// resigner returns the colour whose resignation gives result, Empty if
+// result is not a resignation.
+func resigner(result string) game.FieldState {
+	switch result {
+	case resignation(game.Black):
+		return game.Black
+	case resignation(game.White):
+		return game.White
+	}
+	return game.Empty
+}
+
+// timeout returns the read timeout of connections.
+func (o Options) timeout() time.Duration {
+	return 3 * o.keepalive()
+}
@@ -0,0 +1,75 @@
+// Package pattern provides move priors from the 3x3 neighbourhood of a
+// point. Patterns are seen from the player to move and merged under the
+// eight symmetries of the board, and their weights are learned from game
+// records.
+package pattern
+
+import (
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+// Code identifies a 3x3 pattern: two bits for each of the eight neighbours
+// of the centre point, holding one of the values below.
+type Code uint16
+
+const (
+	empty   = 0
+	own     = 1
+	opp     = 2
+	offEdge = 3
+)
+
+// offsets lists the neighbours in the order of their bits in a Code.
+var offsets = [8][2]int8{{-1, -1}, {-1, 0}, {-1, 1}, {0, -1}, {0, 1}, {1, -1}, {1, 0}, {1, 1}}
+
+// canonicalCodes maps every raw code to the smallest code of its eight
+// symmetric images.
+var canonicalCodes = func() (t [1 << 16]Code) {
+	// perm[s][i] is the position of neighbour i after applying symmetry s,
+	// found by transforming the neighbours of the board centre.
+	var perm [game.NumSymmetries][8]int
+	centre := int8(game.BoardSize / 2)
+	for s := game.Symmetry(0); s < game.NumSymmetries; s++ {
+		for i, o := range offsets {
+			p := s.Point(game.Point{Row: centre + o[0], Col: centre + o[1]})
+			for k, q := range offsets {
+				if p.Row-centre == q[0] && p.Col-centre == q[1] {
+					perm[s][i] = k
+				}
+			}
+		}
+	}
+	for raw := 0; raw < 1<<16; raw++ {
+		best := Code(raw)
+		for s := 1; s < game.NumSymmetries; s++ {
+			var c Code
+			for i := 0; i < 8; i++ {
+				c |= Code(raw>>(2*i)&3) << (2 * perm[s][i])
+			}
+			best = min(best, c)
+		}
+		t[raw] = best
+	}
+	return t
+}()
+
+// At returns the canonical pattern around p for player to move.
+func At(board game.Board, p game.Point, player game.FieldState) Code {
+	var raw Code
+	for i, o := range offsets {
+		r, c := p.Row+o[0], p.Col+o[1]
+		v := Code(offEdge)
+		if r >= 0 && r < game.BoardSize && c >= 0 && c < game.BoardSize {
+			switch board[r][c] {
+			case game.Empty:
+				v = empty
+			case player:
+				v = own
+			default:
+				v = opp
+			}
+		}
+		raw |= v << (2 * i)
+	}
+	return canonicalCodes[raw]
+}
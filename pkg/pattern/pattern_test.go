@@ -0,0 +1,89 @@
+package pattern
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/sgf"
+)
+
+func TestAtIsSymmetric(t *testing.T) {
+	var b game.Board
+	b[3][3] = game.Black
+	b[3][4] = game.White
+	b[5][5] = game.Black
+	p := game.Point{Row: 4, Col: 4}
+	code := At(b, p, game.Black)
+	for s := game.Symmetry(0); s < game.NumSymmetries; s++ {
+		if got := At(s.Board(b), s.Point(p), game.Black); got != code {
+			t.Errorf("Expected the same pattern under %v, got %v want %v", s, got, code)
+		}
+	}
+}
+
+func TestAtIsRelativeToPlayer(t *testing.T) {
+	var b, swapped game.Board
+	b[0][1], swapped[0][1] = game.Black, game.White
+	p := game.Point{Row: 0, Col: 0}
+	if At(b, p, game.Black) != At(swapped, p, game.White) {
+		t.Errorf("Expected colour-swapped positions to match for the other player")
+	}
+	if At(b, p, game.Black) == At(b, p, game.White) {
+		t.Errorf("Expected the player to move to matter")
+	}
+	var centre game.Board
+	if At(centre, p, game.Black) == At(centre, game.Point{Row: 4, Col: 4}, game.Black) {
+		t.Errorf("Expected the edge to be part of the pattern")
+	}
+}
+
+func TestLearner(t *testing.T) {
+	// Black always answers a white stone by playing below it.
+	roots, err := sgf.ParseString("(;SZ[9]AW[ee];B[ef])(;SZ[9]AW[cc];B[cd])")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewLearner()
+	for _, root := range roots {
+		g, err := sgf.MainLine(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.AddGame(g)
+	}
+	table := l.Table()
+	var b game.Board
+	b[6][6] = game.White
+	contact := table.Prior(b, game.Point{Row: 6, Col: 7}, game.Black)
+	away := table.Prior(b, game.Point{Row: 2, Col: 2}, game.Black)
+	if contact <= 1 || away >= 1 {
+		t.Errorf("Expected the played pattern above and the others below 1, got %v and %v", contact, away)
+	}
+	if Bonus(contact) <= 0 || Bonus(away) > 0 || Bonus(1) != 0 {
+		t.Errorf("Expected bonuses to follow the priors, got %d and %d", Bonus(contact), Bonus(away))
+	}
+}
+
+func TestPlayoutMoveKeepsEyes(t *testing.T) {
+	// Black owns the corner with an eye at (0,0); all other points are
+	// filled except (8,8), which would be White's suicide.
+	var b game.Board
+	for i := range b {
+		for j := range b[i] {
+			b[i][j] = game.White
+		}
+	}
+	b[0][0] = game.Empty
+	b[0][1], b[1][0], b[1][1] = game.Black, game.Black, game.Black
+	b[8][8] = game.Empty
+	table := &Table{}
+	rng := rand.New(rand.NewSource(1))
+	if move := table.PlayoutMove(b, game.Black, nil, rng); move == nil || *move != (game.Point{Row: 8, Col: 8}) {
+		t.Errorf("Expected Black to play (8,8) rather than its eye, got %+v", move)
+	}
+	b[8][8] = game.Black
+	if move := table.PlayoutMove(b, game.Black, nil, rng); move != nil {
+		t.Errorf("Expected a pass when only the eye is left, got %+v", move)
+	}
+}
@@ -0,0 +1,224 @@
+package pattern
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/sgf"
+)
+
+// Table holds a weight per pattern: how much more often than average a
+// move with that pattern was played when it was available. Patterns that
+// were never seen have weight 1.
+type Table struct {
+	Weights map[Code]float64 `json:"weights"`
+}
+
+// LoadTable reads a table written by Save.
+func LoadTable(path string) (*Table, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	t := &Table{}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("pattern: decode %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// Save writes the table as JSON.
+func (t *Table) Save(path string) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Weight returns the weight of pattern c.
+func (t *Table) Weight(c Code) float64 {
+	if w, ok := t.Weights[c]; ok {
+		return w
+	}
+	return 1
+}
+
+// Prior returns the weight of the pattern around p for player to move.
+func (t *Table) Prior(board game.Board, p game.Point, player game.FieldState) float64 {
+	return t.Weight(At(board, p, player))
+}
+
+// Priors returns the prior of every empty point; occupied points are 0.
+func (t *Table) Priors(board game.Board, player game.FieldState) [game.BoardSize][game.BoardSize]float64 {
+	var priors [game.BoardSize][game.BoardSize]float64
+	for i := int8(0); i < game.BoardSize; i++ {
+		for j := int8(0); j < game.BoardSize; j++ {
+			if board[i][j] == game.Empty {
+				priors[i][j] = t.Prior(board, game.Point{Row: i, Col: j}, player)
+			}
+		}
+	}
+	return priors
+}
+
+// Bonus converts a prior into a move ordering bonus: two points per
+// doubling of the weight.
+func Bonus(prior float64) int {
+	if prior <= 0 {
+		return 0
+	}
+	return int(math.Round(2 * math.Log2(prior)))
+}
+
+// PlayoutMove picks a legal move for player at random with probability
+// proportional to the pattern priors, as a playout policy. It does not
+// fill the player's own eyes (empty points surrounded by the player's
+// stones) and returns nil, a pass, if no other move is left.
+func (t *Table) PlayoutMove(board game.Board, player game.FieldState, ko *game.Point, rng *rand.Rand) *game.Point {
+	priors := t.Priors(board, player)
+	s := game.State{Board: board, ToMove: player, Ko: ko}
+	var moves []game.Point
+	var weights []float64
+	total := 0.0
+	for i := int8(0); i < game.BoardSize; i++ {
+		for j := int8(0); j < game.BoardSize; j++ {
+			p := game.Point{Row: i, Col: j}
+			if priors[i][j] <= 0 || (ko != nil && p == *ko) || ownEye(board, p, player) {
+				continue
+			}
+			moves = append(moves, p)
+			weights = append(weights, priors[i][j])
+			total += priors[i][j]
+		}
+	}
+	// Draw without replacement until a legal move turns up.
+	for len(moves) > 0 {
+		x := rng.Float64() * total
+		k := len(moves) - 1
+		for i, w := range weights {
+			x -= w
+			if x < 0 {
+				k = i
+				break
+			}
+		}
+		if _, _, err := game.Play(s, &moves[k]); err == nil {
+			return &moves[k]
+		}
+		total -= weights[k]
+		moves[k], weights[k] = moves[len(moves)-1], weights[len(weights)-1]
+		moves, weights = moves[:len(moves)-1], weights[:len(weights)-1]
+	}
+	return nil
+}
+
+func ownEye(board game.Board, p game.Point, player game.FieldState) bool {
+	for _, n := range game.Neighbors(p) {
+		if board[n.Row][n.Col] != player {
+			return false
+		}
+	}
+	return true
+}
+
+// Learner counts how often each pattern was available and how often it
+// was played.
+type Learner struct {
+	Seen   map[Code]int
+	Played map[Code]int
+}
+
+// NewLearner returns an empty Learner.
+func NewLearner() *Learner {
+	return &Learner{Seen: make(map[Code]int), Played: make(map[Code]int)}
+}
+
+// AddGame counts the moves of g. Every empty point counts as available;
+// legality is not checked, which only matters for the rare suicide and
+// ko points.
+func (l *Learner) AddGame(g *sgf.Game) {
+	var board game.Board
+	for _, s := range g.SetupBlack {
+		board[s.Row][s.Col] = game.Black
+	}
+	for _, s := range g.SetupWhite {
+		board[s.Row][s.Col] = game.White
+	}
+	for _, m := range g.Moves {
+		if m.Point == nil {
+			continue
+		}
+		if board[m.Point.Row][m.Point.Col] != game.Empty {
+			return
+		}
+		for i := int8(0); i < game.BoardSize; i++ {
+			for j := int8(0); j < game.BoardSize; j++ {
+				if board[i][j] == game.Empty {
+					l.Seen[At(board, game.Point{Row: i, Col: j}, m.Color)]++
+				}
+			}
+		}
+		l.Played[At(board, *m.Point, m.Color)]++
+		next, _, err := game.Play(game.NewState(board, m.Color), m.Point)
+		if err != nil {
+			return
+		}
+		board = next.Board
+	}
+}
+
+// smoothing is the number of average observations added to every pattern
+// so that rare patterns stay close to weight 1.
+const smoothing = 20
+
+// Table returns the learned weights: the pattern's smoothed play rate
+// divided by the overall play rate.
+func (l *Learner) Table() *Table {
+	seen, played := 0, 0
+	for _, n := range l.Seen {
+		seen += n
+	}
+	for _, n := range l.Played {
+		played += n
+	}
+	t := &Table{Weights: make(map[Code]float64)}
+	if played == 0 {
+		return t
+	}
+	rate := float64(played) / float64(seen)
+	for c, n := range l.Seen {
+		t.Weights[c] = (float64(l.Played[c]) + smoothing*rate) / (float64(n) + smoothing) / rate
+	}
+	return t
+}
+
+// Import counts the main line of every game in the SGF files and returns
+// the number of games read.
+func (l *Learner) Import(paths []string) (int, error) {
+	games := 0
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return games, err
+		}
+		roots, err := sgf.Parse(f)
+		f.Close()
+		if err != nil {
+			return games, fmt.Errorf("%s: %w", path, err)
+		}
+		for _, root := range roots {
+			g, err := sgf.MainLine(root)
+			if err != nil {
+				return games, fmt.Errorf("%s: %w", path, err)
+			}
+			l.AddGame(g)
+			games++
+		}
+	}
+	return games, nil
+}
@@ -0,0 +1,157 @@
+// Package perft counts the legal move sequences from a position, as a
+// correctness and speed check for the rules implementations. It runs the
+// count on the plain game.Board rules and on the incremental position of
+// pkg/position, which must agree.
+//
+// A sequence is any series of legal moves and passes in which no move
+// follows two consecutive passes, since those end the game. With simple
+// ko a move may not recreate the position before the opponent's last
+// move; with positional superko it may not recreate any earlier position.
+package perft
+
+import (
+	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/position"
+)
+
+// Rules selects the repetition rule.
+type Rules struct {
+	Superko bool
+}
+
+// State is the start position of a count.
+type State struct {
+	Board  game.Board
+	ToMove game.FieldState
+}
+
+// Move is a first move of a divided count; Point is nil for a pass.
+type Move struct {
+	Point *game.Point
+	Nodes uint64
+}
+
+// Simple returns the number of sequences of depth moves from s using
+// game.Play.
+func Simple(s State, depth int, rules Rules) uint64 {
+	return countSimple(game.State{Board: s.Board, ToMove: s.ToMove, Superko: rules.Superko}, 0, depth)
+}
+
+// Fast returns the number of sequences of depth moves from s using
+// pkg/position.
+func Fast(s State, depth int, rules Rules) uint64 {
+	c := newFast(s, rules)
+	return c.count(s.ToMove, 0, depth)
+}
+
+// Divide returns the count below every legal first move, using the fast
+// implementation. The counts add up to Fast(s, depth, rules).
+func Divide(s State, depth int, rules Rules) []Move {
+	if depth < 1 {
+		return nil
+	}
+	c := newFast(s, rules)
+	var moves []Move
+	for i := int8(0); i < game.BoardSize; i++ {
+		for j := int8(0); j < game.BoardSize; j++ {
+			p := game.Point{Row: i, Col: j}
+			if c.play(p, s.ToMove) {
+				moves = append(moves, Move{Point: &p, Nodes: c.count(game.Opponent(s.ToMove), 0, depth-1)})
+				c.undo()
+			}
+		}
+	}
+	c.pos.Pass()
+	moves = append(moves, Move{Nodes: c.count(game.Opponent(s.ToMove), 1, depth-1)})
+	c.pos.Undo()
+	return moves
+}
+
+// countSimple returns the number of sequences of depth moves from s after
+// passes consecutive passes.
+func countSimple(s game.State, passes, depth int) uint64 {
+	if depth == 0 {
+		return 1
+	}
+	var n uint64
+	for i := int8(0); i < game.BoardSize; i++ {
+		for j := int8(0); j < game.BoardSize; j++ {
+			if next, _, err := game.Play(s, &game.Point{Row: i, Col: j}); err == nil {
+				n += countSimple(next, 0, depth-1)
+			}
+		}
+	}
+	if passes == 0 {
+		next, _, _ := game.Play(s, nil)
+		n += countSimple(next, 1, depth-1)
+	} else if depth == 1 {
+		// The second pass ends the game.
+		n++
+	}
+	return n
+}
+
+// fast counts with Play and Undo on a single position.
+type fast struct {
+	rules Rules
+	pos   *position.Position
+	seen  map[game.Board]int
+}
+
+func newFast(s State, rules Rules) *fast {
+	return &fast{rules: rules, pos: position.FromBoard(s.Board), seen: map[game.Board]int{s.Board: 1}}
+}
+
+// play plays p and reports whether it was legal under the rules.
+func (c *fast) play(p game.Point, color game.FieldState) bool {
+	if _, err := c.pos.Play(p, color); err != nil {
+		return false
+	}
+	if c.rules.Superko {
+		b := c.pos.Board()
+		if c.seen[b] > 0 {
+			c.pos.Undo()
+			return false
+		}
+		c.seen[b]++
+	}
+	return true
+}
+
+func (c *fast) undo() {
+	if c.rules.Superko {
+		c.seen[c.pos.Board()]--
+	}
+	c.pos.Undo()
+}
+
+func (c *fast) count(color game.FieldState, passes, depth int) uint64 {
+	if depth == 0 {
+		return 1
+	}
+	var n uint64
+	for i := int8(0); i < game.BoardSize; i++ {
+		for j := int8(0); j < game.BoardSize; j++ {
+			p := game.Point{Row: i, Col: j}
+			if depth == 1 && !c.rules.Superko {
+				// Bulk count the last ply without playing it.
+				if c.pos.Check(p, color) == nil {
+					n++
+				}
+				continue
+			}
+			if c.play(p, color) {
+				n += c.count(game.Opponent(color), 0, depth-1)
+				c.undo()
+			}
+		}
+	}
+	if passes == 0 {
+		c.pos.Pass()
+		n += c.count(game.Opponent(color), 1, depth-1)
+		c.pos.Undo()
+	} else if depth == 1 {
+		n++
+	}
+	return n
+}
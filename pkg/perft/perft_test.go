@@ -0,0 +1,123 @@
+package perft
+
+import (
+	"testing"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+// koBoard has a ko in the top left: Black captures at (1,3) and White may
+// not retake at (1,2) immediately.
+func koBoard() game.Board {
+	var b game.Board
+	b[1][1], b[0][2], b[2][2] = game.Black, game.Black, game.Black
+	b[0][3], b[1][4], b[2][3], b[1][2] = game.White, game.White, game.White, game.White
+	return b
+}
+
+// cornerBoard leaves only the top left 2x3 corner empty; the rest is
+// divided between two living groups.
+func cornerBoard() game.Board {
+	var b game.Board
+	for i := 0; i < game.BoardSize; i++ {
+		for j := 0; j < game.BoardSize; j++ {
+			if i < 2 && j < 3 {
+				continue
+			}
+			b[i][j] = game.Black
+			if i >= 5 {
+				b[i][j] = game.White
+			}
+		}
+	}
+	// Two eyes for each group.
+	b[8][0], b[8][2] = game.Empty, game.Empty
+	b[4][8], b[4][6] = game.Empty, game.Empty
+	return b
+}
+
+// sendTwoBoard has a send-two-return-one in the top left corner: Black
+// throws in at (0,0), White captures two stones at (0,2) and Black takes
+// back one at (0,1), which recreates the position. Only positional superko
+// forbids that. White's group on the left and Black's on the right each
+// have two eyes.
+func sendTwoBoard() game.Board {
+	var b game.Board
+	for i := 1; i < game.BoardSize; i++ {
+		for j := 0; j < game.BoardSize; j++ {
+			b[i][j] = game.Black
+			if j < 2 {
+				b[i][j] = game.White
+			}
+		}
+	}
+	b[0][1] = game.Black
+	for j := 3; j < game.BoardSize; j++ {
+		b[0][j] = game.Black
+	}
+	b[4][0], b[6][0] = game.Empty, game.Empty
+	b[4][6], b[6][6] = game.Empty, game.Empty
+	return b
+}
+
+var known = []struct {
+	name   string
+	state  State
+	rules  Rules
+	counts []uint64 // by depth, starting at 1
+}{
+	// Every stone or pass; the second pass ends the game.
+	{"empty", State{ToMove: game.Black}, Rules{}, []uint64{82, 6643, 531522}},
+	{"empty superko", State{ToMove: game.Black}, Rules{Superko: true}, []uint64{82, 6643, 531522}},
+	{"ko", State{Board: koBoard(), ToMove: game.Black}, Rules{}, []uint64{75, 5551, 405227}},
+	{"ko superko", State{Board: koBoard(), ToMove: game.Black}, Rules{Superko: true}, []uint64{75, 5551, 405227}},
+	{"corner", State{Board: cornerBoard(), ToMove: game.Black}, Rules{}, []uint64{9, 91, 666, 5410, 31638}},
+	{"corner superko", State{Board: cornerBoard(), ToMove: game.Black}, Rules{Superko: true}, []uint64{9, 91, 666, 5410, 31638}},
+	// The return of one stone is the only difference at depth 3.
+	{"send two", State{Board: sendTwoBoard(), ToMove: game.Black}, Rules{}, []uint64{5, 20, 78, 265, 2121}},
+	{"send two superko", State{Board: sendTwoBoard(), ToMove: game.Black}, Rules{Superko: true}, []uint64{5, 20, 77, 261, 2093}},
+}
+
+func TestKnownCountsFast(t *testing.T) {
+	for _, k := range known {
+		for d, want := range k.counts {
+			if got := Fast(k.state, d+1, k.rules); got != want {
+				t.Errorf("%s: Expected %d sequences at depth %d, got %d", k.name, want, d+1, got)
+			}
+		}
+	}
+}
+
+func TestKnownCountsSimple(t *testing.T) {
+	for _, k := range known {
+		for d, want := range k.counts {
+			// The map-based rules are too slow for the larger counts.
+			if want > 50000 {
+				break
+			}
+			if got := Simple(k.state, d+1, k.rules); got != want {
+				t.Errorf("%s: Expected %d sequences at depth %d, got %d", k.name, want, d+1, got)
+			}
+		}
+	}
+}
+
+func TestDivide(t *testing.T) {
+	s := State{Board: koBoard(), ToMove: game.Black}
+	var total uint64
+	var capture uint64
+	for _, m := range Divide(s, 2, Rules{}) {
+		total += m.Nodes
+		if m.Point != nil && *m.Point == (game.Point{Row: 1, Col: 3}) {
+			capture = m.Nodes
+		}
+	}
+	if total != Fast(s, 2, Rules{}) {
+		t.Errorf("Expected divided counts to add up to %d, got %d", Fast(s, 2, Rules{}), total)
+	}
+	// After the capture White has 74 empty points but not the ko point,
+	// plus the pass.
+	if capture != 74 {
+		t.Errorf("Expected 74 replies to the ko capture, got %d", capture)
+	}
+}
@@ -0,0 +1,108 @@
+package position
+
+import (
+	"testing"
+
+	"github.com/RubikNube/GoInGo/pkg/bitboard"
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+// simplePlay plays color at p on a plain board the way the game package
+// does and returns the new board, the number of captured stones and
+// whether the point was empty and the move no suicide.
+func simplePlay(b game.Board, p game.Point, color game.FieldState) (game.Board, int, bool) {
+	if b[p.Row][p.Col] != game.Empty {
+		return b, 0, false
+	}
+	b[p.Row][p.Col] = color
+	captured := 0
+	for _, n := range game.Neighbors(p) {
+		if c := b[n.Row][n.Col]; c == game.Empty || c == color {
+			continue
+		}
+		stones, libs := game.Group(b, n)
+		if len(libs) > 0 {
+			continue
+		}
+		for s := range stones {
+			b[s.Row][s.Col] = game.Empty
+			captured++
+		}
+	}
+	if _, libs := game.Group(b, p); len(libs) == 0 {
+		return b, 0, false
+	}
+	return b, captured, true
+}
+
+// FuzzDifferential plays the moves encoded by the input, one byte per
+// move, on a plain game.Board, a bitboard.Position and a Position, and
+// checks that they agree on legality, captures and the resulting chains
+// after every move and that undoing all moves restores the start.
+func FuzzDifferential(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{40, 41, 31, 32, 49, 50, 39, 42, 30, 33})
+	f.Add([]byte{0, 3, 10, 11, 2, 1, 2, 40, 30, 2, 81, 81})
+	f.Add([]byte("the quick brown fox jumps over the lazy dog, twice and again"))
+	f.Fuzz(func(t *testing.T, moves []byte) {
+		var b, prev game.Board
+		bb := bitboard.Position{}
+		pos := New()
+		color := game.Black
+		played := 0
+		for _, c := range moves {
+			i := int(c) % (points + 1)
+			if i == points {
+				prev = b
+				pos.Pass()
+				played++
+				color = game.Opponent(color)
+				continue
+			}
+			p := point(i)
+			next, captured, ok := simplePlay(b, p, color)
+			legal := game.IsLegalMove(b, p, color, prev)
+			if got := bb.IsLegal(p, color); got != ok {
+				t.Fatalf("Expected bitboard legality %v for %v at %v, got %v on\n%v", ok, color, p, got, b)
+			}
+			if err := pos.Check(p, color); (err == nil) != legal {
+				t.Fatalf("Expected legality %v for %v at %v, got %v on\n%v", legal, color, p, err, b)
+			}
+			if !legal {
+				continue
+			}
+			nextBB, capturedBB, _ := bb.Play(p, color)
+			n, err := pos.Play(p, color)
+			if err != nil {
+				t.Fatal(err)
+			}
+			played++
+			if n != captured || capturedBB.Count() != captured {
+				t.Fatalf("Expected %d captured stones for %v at %v, got %d and %d", captured, color, p, n, capturedBB.Count())
+			}
+			if pos.Board() != next || nextBB.Board() != next {
+				t.Fatalf("Expected the boards to agree after %v at %v", color, p)
+			}
+			for j := 0; j < points; j++ {
+				q := point(j)
+				if next[q.Row][q.Col] == game.Empty {
+					continue
+				}
+				stones, libs := nextBB.Chain(q)
+				if stones.Count() != pos.ChainSize(q) || libs != pos.LibertyMask(q) {
+					t.Fatalf("Expected the chain at %v to agree after %v at %v", q, color, p)
+				}
+			}
+			prev, b, bb = b, next, nextBB
+			color = game.Opponent(color)
+		}
+		for ; played > 0; played-- {
+			if !pos.Undo() {
+				t.Fatalf("Expected %d more moves to undo", played)
+			}
+		}
+		if pos.Undo() || !sameChains(pos, New()) {
+			t.Fatalf("Expected the empty position after undoing all moves, got\n%v", pos.Board())
+		}
+	})
+}
@@ -0,0 +1,387 @@
+// Package position maintains a Go position incrementally: every chain keeps
+// its stones in a circular list and its liberties as a bit mask, and moves
+// are played and taken back with Play and Undo instead of copying the board
+// and flood-filling groups.
+package position
+
+import (
+	"github.com/RubikNube/GoInGo/pkg/bitboard"
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+const (
+	points = game.BoardSize * game.BoardSize
+	none   = -1
+)
+
+// The errors of Play are those of game.Play.
+var (
+	ErrOccupied = game.ErrOccupied
+	ErrSuicide  = game.ErrSuicide
+	ErrKo       = game.ErrKo
+)
+
+// Position is a board with incrementally maintained chains. The zero value
+// is not usable; create positions with New or FromBoard.
+type Position struct {
+	color [points]game.FieldState
+	head  [points]int16 // index of the chain's head stone, none if empty
+	next  [points]int16 // next stone of the chain, circular
+	// size and libs are valid for head stones only.
+	size [points]int16
+	libs [points]bitboard.Mask
+	ko   int16 // point that may not be played next, none if there is none
+	// hashes holds the Zobrist hash of each of the eight images of the
+	// board under game.Symmetry.
+	hashes [game.NumSymmetries]uint64
+	log    []change
+}
+
+// neighbors lists the adjacent points of every point by index.
+var neighbors = func() (t [points][]int16) {
+	for i := 0; i < points; i++ {
+		for _, n := range game.Neighbors(point(i)) {
+			t[i] = append(t[i], int16(index(n)))
+		}
+	}
+	return t
+}()
+
+// bits holds the single-point mask of every point.
+var bits = func() (t [points]bitboard.Mask) {
+	for i := range t {
+		t[i] = bitboard.Bit(point(i))
+	}
+	return t
+}()
+
+// images holds the index of the image of every point under every symmetry.
+var images = func() (t [game.NumSymmetries][points]int16) {
+	for s := range t {
+		for i := range t[s] {
+			t[s][i] = int16(index(game.Symmetry(s).Point(point(i))))
+		}
+	}
+	return t
+}()
+
+// zobrist holds a random key for a stone of either colour on every point;
+// the keys for Empty are zero.
+var zobrist = func() (t [points][3]uint64) {
+	// splitmix64 with a fixed seed, so that hashes are stable across runs.
+	x := uint64(0x9e3779b97f4a7c15)
+	next := func() uint64 {
+		x += 0x9e3779b97f4a7c15
+		z := x
+		z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+		z = (z ^ z>>27) * 0x94d049bb133111eb
+		return z ^ z>>31
+	}
+	for i := range t {
+		t[i][game.Black] = next()
+		t[i][game.White] = next()
+	}
+	return t
+}()
+
+func index(p game.Point) int { return int(p.Row)*game.BoardSize + int(p.Col) }
+
+func point(i int) game.Point {
+	return game.Point{Row: int8(i / game.BoardSize), Col: int8(i % game.BoardSize)}
+}
+
+// New returns an empty position.
+func New() *Position {
+	pos := &Position{ko: none}
+	for i := range pos.head {
+		pos.head[i] = none
+		pos.next[i] = none
+	}
+	return pos
+}
+
+// FromBoard returns the position of b with no ko.
+func FromBoard(b game.Board) *Position {
+	pos := New()
+	for i := 0; i < points; i++ {
+		pos.color[i] = b[i/game.BoardSize][i%game.BoardSize]
+		pos.rehash(int16(i), game.Empty, pos.color[i])
+	}
+	for i := 0; i < points; i++ {
+		if pos.color[i] == game.Empty || pos.head[i] != none {
+			continue
+		}
+		// Collect the chain by flood fill and link its stones.
+		stack := []int16{int16(i)}
+		pos.head[i] = int16(i)
+		last := int16(i)
+		var libs bitboard.Mask
+		size := int16(0)
+		for len(stack) > 0 {
+			s := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			size++
+			for _, n := range neighbors[s] {
+				switch {
+				case pos.color[n] == game.Empty:
+					libs = libs.Or(bits[n])
+				case pos.color[n] == pos.color[i] && pos.head[n] == none:
+					pos.head[n] = int16(i)
+					pos.next[last] = n
+					last = n
+					stack = append(stack, n)
+				}
+			}
+		}
+		pos.next[last] = int16(i)
+		pos.size[i] = size
+		pos.libs[i] = libs
+	}
+	return pos
+}
+
+// Board returns the stones as a game.Board.
+func (pos *Position) Board() game.Board {
+	var b game.Board
+	for i, c := range pos.color {
+		b[i/game.BoardSize][i%game.BoardSize] = c
+	}
+	return b
+}
+
+// At returns the colour at p.
+func (pos *Position) At(p game.Point) game.FieldState {
+	return pos.color[index(p)]
+}
+
+// Liberties returns the number of liberties of the chain at p, 0 if p is empty.
+func (pos *Position) Liberties(p game.Point) int {
+	h := pos.head[index(p)]
+	if h == none {
+		return 0
+	}
+	return pos.libs[h].Count()
+}
+
+// LibertyMask returns the liberties of the chain at p.
+func (pos *Position) LibertyMask(p game.Point) bitboard.Mask {
+	h := pos.head[index(p)]
+	if h == none {
+		return bitboard.Mask{}
+	}
+	return pos.libs[h]
+}
+
+// ChainSize returns the number of stones of the chain at p, 0 if p is empty.
+func (pos *Position) ChainSize(p game.Point) int {
+	h := pos.head[index(p)]
+	if h == none {
+		return 0
+	}
+	return int(pos.size[h])
+}
+
+// Hash returns a Zobrist hash of the stones that is the same for boards
+// that are rotations or reflections of each other: the smallest of the
+// hashes of the eight images, which are kept up to date move by move.
+func (pos *Position) Hash() uint64 {
+	h := pos.hashes[0]
+	for _, x := range pos.hashes[1:] {
+		h = min(h, x)
+	}
+	return h
+}
+
+// rehash updates the hashes for point i changing from colour old to c.
+func (pos *Position) rehash(i int16, old, c game.FieldState) {
+	for s := range pos.hashes {
+		img := images[s][i]
+		pos.hashes[s] ^= zobrist[img][old] ^ zobrist[img][c]
+	}
+}
+
+// Ko returns the point that may not be played next because it would retake
+// a ko, or nil.
+func (pos *Position) Ko() *game.Point {
+	if pos.ko == none {
+		return nil
+	}
+	p := point(int(pos.ko))
+	return &p
+}
+
+// SetKo forbids p for the next move; nil lifts the restriction. It can be
+// undone together with the previous Play or Pass.
+func (pos *Position) SetKo(p *game.Point) {
+	if p == nil {
+		pos.setKo(none)
+	} else {
+		pos.setKo(int16(index(*p)))
+	}
+}
+
+// Check returns the error Play would return for color at p, without
+// changing the position.
+func (pos *Position) Check(p game.Point, color game.FieldState) error {
+	i := index(p)
+	if pos.color[i] != game.Empty {
+		return ErrOccupied
+	}
+	if int16(i) == pos.ko {
+		return ErrKo
+	}
+	for _, n := range neighbors[i] {
+		switch c := pos.color[n]; {
+		case c == game.Empty:
+			return nil
+		case c == color:
+			// Connecting to a chain with another liberty.
+			if pos.libs[pos.head[n]].AndNot(bits[i]).Count() > 0 {
+				return nil
+			}
+		default:
+			// Capturing a chain in atari.
+			if pos.libs[pos.head[n]] == bits[i] {
+				return nil
+			}
+		}
+	}
+	return ErrSuicide
+}
+
+// Play places color at p, removes captured chains and returns the number
+// of captured stones. The move can be taken back with Undo.
+func (pos *Position) Play(p game.Point, color game.FieldState) (int, error) {
+	if err := pos.Check(p, color); err != nil {
+		return 0, err
+	}
+	i := int16(index(p))
+	pos.log = append(pos.log, change{kind: mark})
+	pos.setColor(i, color)
+	pos.setHead(i, i)
+	pos.setNext(i, i)
+	pos.setSize(i, 1)
+	var libs bitboard.Mask
+	for _, n := range neighbors[i] {
+		if pos.color[n] == game.Empty {
+			libs = libs.Or(bits[n])
+		}
+	}
+	pos.setLibs(i, libs)
+
+	// Join the adjacent chains of the same colour.
+	h := i
+	for _, n := range neighbors[i] {
+		if pos.color[n] == color && pos.head[n] != h {
+			h = pos.merge(h, pos.head[n])
+		}
+	}
+	pos.setLibs(h, pos.libs[h].AndNot(bits[i]))
+
+	// Take the liberty from the adjacent opponent chains and capture those
+	// left without liberties.
+	captured := 0
+	lastCapture := int16(none)
+	for _, n := range neighbors[i] {
+		if c := pos.color[n]; c == game.Empty || c == color {
+			continue
+		}
+		oh := pos.head[n]
+		pos.setLibs(oh, pos.libs[oh].AndNot(bits[i]))
+		if pos.libs[oh].IsEmpty() {
+			captured += int(pos.size[oh])
+			lastCapture = oh
+			pos.remove(oh)
+		}
+	}
+
+	h = pos.head[i]
+	if captured == 1 && pos.size[h] == 1 && pos.libs[h].Count() == 1 {
+		pos.setKo(lastCapture)
+	} else {
+		pos.setKo(none)
+	}
+	return captured, nil
+}
+
+// Pass clears the ko restriction. It can be taken back with Undo.
+func (pos *Position) Pass() {
+	pos.log = append(pos.log, change{kind: mark})
+	pos.setKo(none)
+}
+
+// Undo takes back the last Play or Pass. It reports false if there is none.
+func (pos *Position) Undo() bool {
+	for len(pos.log) > 0 {
+		c := pos.log[len(pos.log)-1]
+		pos.log = pos.log[:len(pos.log)-1]
+		switch c.kind {
+		case mark:
+			return true
+		case colorChange:
+			pos.rehash(c.at, pos.color[c.at], game.FieldState(c.old))
+			pos.color[c.at] = game.FieldState(c.old)
+		case headChange:
+			pos.head[c.at] = c.old
+		case nextChange:
+			pos.next[c.at] = c.old
+		case sizeChange:
+			pos.size[c.at] = c.old
+		case libsChange:
+			pos.libs[c.at] = c.mask
+		case koChange:
+			pos.ko = c.old
+		}
+	}
+	return false
+}
+
+// merge joins the chains with heads a and b and returns the new head, the
+// head of the larger chain. The liberties are united.
+func (pos *Position) merge(a, b int16) int16 {
+	if pos.size[a] < pos.size[b] {
+		a, b = b, a
+	}
+	s := b
+	for {
+		pos.setHead(s, a)
+		s = pos.next[s]
+		if s == b {
+			break
+		}
+	}
+	// Splice the circular lists.
+	na, nb := pos.next[a], pos.next[b]
+	pos.setNext(a, nb)
+	pos.setNext(b, na)
+	pos.setSize(a, pos.size[a]+pos.size[b])
+	pos.setLibs(a, pos.libs[a].Or(pos.libs[b]))
+	return a
+}
+
+// remove takes the chain with head h off the board and gives its points to
+// the adjacent chains as liberties.
+func (pos *Position) remove(h int16) {
+	var stones [points]int16
+	n := 0
+	for s := h; ; {
+		stones[n] = s
+		n++
+		s = pos.next[s]
+		if s == h {
+			break
+		}
+	}
+	for _, s := range stones[:n] {
+		pos.setColor(s, game.Empty)
+		pos.setHead(s, none)
+		pos.setNext(s, none)
+	}
+	for _, s := range stones[:n] {
+		for _, nb := range neighbors[s] {
+			if nh := pos.head[nb]; nh != none {
+				pos.setLibs(nh, pos.libs[nh].Or(bits[s]))
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package position
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+const playoutMoves = 100
+
+// BenchmarkPlayoutPosition plays random playouts with Play and takes them
+// back with Undo.
+func BenchmarkPlayoutPosition(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	pos := New()
+	for i := 0; i < b.N; i++ {
+		color := game.Black
+		played := 0
+		for n := 0; n < playoutMoves; n++ {
+			start := rng.Intn(points)
+			for k := 0; k < points; k++ {
+				if _, err := pos.Play(point((start+k)%points), color); err == nil {
+					played++
+					break
+				}
+			}
+			color = game.White + game.Black - color
+		}
+		for ; played > 0; played-- {
+			pos.Undo()
+		}
+	}
+}
+
+// BenchmarkPlayoutBoard plays the same kind of playouts the way the
+// engines did before: copy the board, remove captures with game.Group and
+// check for suicide.
+func BenchmarkPlayoutBoard(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < b.N; i++ {
+		var board game.Board
+		color := game.Black
+		for n := 0; n < playoutMoves; n++ {
+			start := rng.Intn(points)
+			for k := 0; k < points; k++ {
+				p := point((start + k) % points)
+				if next, ok := playBoard(board, p, color); ok {
+					board = next
+					break
+				}
+			}
+			color = game.White + game.Black - color
+		}
+	}
+}
+
+func playBoard(board game.Board, p game.Point, color game.FieldState) (game.Board, bool) {
+	if board[p.Row][p.Col] != game.Empty {
+		return board, false
+	}
+	next := board
+	next[p.Row][p.Col] = color
+	for _, n := range game.Neighbors(p) {
+		if c := next[n.Row][n.Col]; c != game.Empty && c != color {
+			group, libs := game.Group(next, n)
+			if len(libs) == 0 {
+				for s := range group {
+					next[s.Row][s.Col] = game.Empty
+				}
+			}
+		}
+	}
+	if _, libs := game.Group(next, p); len(libs) == 0 {
+		return board, false
+	}
+	return next, true
+}
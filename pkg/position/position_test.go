@@ -0,0 +1,171 @@
+package position
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+// sameChains reports whether a and b agree on stones, chain sizes,
+// liberties, ko and hashes.
+func sameChains(a, b *Position) bool {
+	if a.Board() != b.Board() || a.ko != b.ko || a.hashes != b.hashes {
+		return false
+	}
+	for i := 0; i < points; i++ {
+		p := point(i)
+		if a.ChainSize(p) != b.ChainSize(p) || a.LibertyMask(p) != b.LibertyMask(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// randomGame plays random legal moves and checks the chains against a
+// position rebuilt from scratch after every move.
+func randomGame(t *testing.T, rng *rand.Rand, moves int) *Position {
+	pos := New()
+	color := game.Black
+	for n := 0; n < moves; n++ {
+		var legal []game.Point
+		for i := 0; i < points; i++ {
+			if pos.Check(point(i), color) == nil {
+				legal = append(legal, point(i))
+			}
+		}
+		if len(legal) == 0 {
+			pos.Pass()
+		} else {
+			p := legal[rng.Intn(len(legal))]
+			before := pos.Board()
+			captured, err := pos.Play(p, color)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := countStones(before) + 1 - captured; countStones(pos.Board()) != want {
+				t.Fatalf("Expected %d stones after capturing %d", want, captured)
+			}
+			rebuilt := FromBoard(pos.Board())
+			rebuilt.ko = pos.ko
+			if !sameChains(pos, rebuilt) {
+				t.Fatalf("Expected incremental chains to match after %v at %v on\n%v", color, p, pos.Board())
+			}
+		}
+		if color == game.Black {
+			color = game.White
+		} else {
+			color = game.Black
+		}
+	}
+	return pos
+}
+
+func countStones(b game.Board) int {
+	n := 0
+	for i := range b {
+		for j := range b[i] {
+			if b[i][j] != game.Empty {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestRandomGames(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 20; n++ {
+		randomGame(t, rng, 200)
+	}
+}
+
+func TestUndoRestoresPosition(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	pos := randomGame(t, rng, 120)
+	snapshot := FromBoard(pos.Board())
+	snapshot.ko = pos.ko
+	// Play a random continuation and take it all back.
+	color := game.Black
+	played := 0
+	for n := 0; n < 60; n++ {
+		p := point(rng.Intn(points))
+		if _, err := pos.Play(p, color); err == nil {
+			played++
+		} else {
+			pos.Pass()
+			played++
+		}
+		color = game.White + game.Black - color
+	}
+	for ; played > 0; played-- {
+		if !pos.Undo() {
+			t.Fatal("Expected a move to undo")
+		}
+	}
+	if !sameChains(pos, snapshot) {
+		t.Errorf("Expected undo to restore the position")
+	}
+}
+
+func TestCheckMatchesRules(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	pos := randomGame(t, rng, 150)
+	b := pos.Board()
+	for i := 0; i < points; i++ {
+		for _, color := range []game.FieldState{game.Black, game.White} {
+			p := point(i)
+			err := pos.Check(p, color)
+			if err == ErrKo {
+				continue
+			}
+			if legal := game.IsLegalMove(b, p, color, game.Board{}); legal != (err == nil) {
+				t.Errorf("Expected legality %v for %v at %v, got %v", legal, color, p, err)
+			}
+		}
+	}
+}
+
+func TestKo(t *testing.T) {
+	var b game.Board
+	// Black (1,1),(0,2),(2,2); White (0,3),(1,4),(2,3),(1,2).
+	b[1][1], b[0][2], b[2][2] = game.Black, game.Black, game.Black
+	b[0][3], b[1][4], b[2][3], b[1][2] = game.White, game.White, game.White, game.White
+	pos := FromBoard(b)
+	captured, err := pos.Play(game.Point{Row: 1, Col: 3}, game.Black)
+	if err != nil || captured != 1 {
+		t.Fatalf("Expected a single capture, got %d %v", captured, err)
+	}
+	if ko := pos.Ko(); ko == nil || *ko != (game.Point{Row: 1, Col: 2}) {
+		t.Fatalf("Expected ko at (1,2), got %+v", ko)
+	}
+	if _, err := pos.Play(game.Point{Row: 1, Col: 2}, game.White); err != ErrKo {
+		t.Errorf("Expected ErrKo, got %v", err)
+	}
+	pos.Pass()
+	if _, err := pos.Play(game.Point{Row: 1, Col: 2}, game.White); err != nil {
+		t.Errorf("Expected the ko to be retaken after a pass, got %v", err)
+	}
+	pos.Undo()
+	pos.Undo()
+	pos.Undo()
+	if !sameChains(pos, FromBoard(b)) {
+		t.Errorf("Expected undo to restore the start")
+	}
+}
+
+func TestHashIsSymmetric(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	pos := randomGame(t, rng, 40)
+	b := pos.Board()
+	for s := game.Symmetry(0); s < game.NumSymmetries; s++ {
+		if h := FromBoard(s.Board(b)).Hash(); h != pos.Hash() {
+			t.Errorf("Expected the same hash under symmetry %d, got %x and %x", s, h, pos.Hash())
+		}
+	}
+	var corner, side game.Board
+	corner[0][0], side[0][1] = game.Black, game.Black
+	if FromBoard(corner).Hash() == FromBoard(side).Hash() {
+		t.Errorf("Expected different positions to hash differently")
+	}
+}
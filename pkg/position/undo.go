@@ -0,0 +1,60 @@
+package position
+
+import (
+	"github.com/RubikNube/GoInGo/pkg/bitboard"
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+// changeKind names the field a change restores.
+type changeKind uint8
+
+const (
+	mark changeKind = iota // start of a move
+	colorChange
+	headChange
+	nextChange
+	sizeChange
+	libsChange
+	koChange
+)
+
+// change is an entry of the undo log: the old value of one field.
+type change struct {
+	kind changeKind
+	at   int16
+	old  int16
+	mask bitboard.Mask
+}
+
+// The setters record the old value before changing a field.
+
+func (pos *Position) setColor(i int16, c game.FieldState) {
+	pos.log = append(pos.log, change{kind: colorChange, at: i, old: int16(pos.color[i])})
+	pos.rehash(i, pos.color[i], c)
+	pos.color[i] = c
+}
+
+func (pos *Position) setHead(i, h int16) {
+	pos.log = append(pos.log, change{kind: headChange, at: i, old: pos.head[i]})
+	pos.head[i] = h
+}
+
+func (pos *Position) setNext(i, n int16) {
+	pos.log = append(pos.log, change{kind: nextChange, at: i, old: pos.next[i]})
+	pos.next[i] = n
+}
+
+func (pos *Position) setSize(i, n int16) {
+	pos.log = append(pos.log, change{kind: sizeChange, at: i, old: pos.size[i]})
+	pos.size[i] = n
+}
+
+func (pos *Position) setLibs(i int16, m bitboard.Mask) {
+	pos.log = append(pos.log, change{kind: libsChange, at: i, mask: pos.libs[i]})
+	pos.libs[i] = m
+}
+
+func (pos *Position) setKo(i int16) {
+	pos.log = append(pos.log, change{kind: koChange, old: pos.ko})
+	pos.ko = i
+}
@@ -0,0 +1,258 @@
+// Package selfplay generates game datasets by letting engines play against
+// each other with randomised openings and writes them as SGF files.
+package selfplay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+
+	"github.com/RubikNube/GoInGo/pkg/compareengines"
+	"github.com/RubikNube/GoInGo/pkg/engine"
+	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/sgf"
+)
+
+// Player is an engine taking part in self-play.
+type Player struct {
+	Name string
+	New  func() engine.Engine
+}
+
+// Config controls game generation.
+type Config struct {
+	Games    int
+	MaxMoves int
+	Komi     float64
+	// RandomOpeningMoves is the number of initial moves chosen uniformly at
+	// random among the legal moves.
+	RandomOpeningMoves int
+	// Temperature, if positive, makes engines implementing engine.Analyzer
+	// sample their move from a softmax over the move scores instead of
+	// always playing the best move. Scores are in evaluation units.
+	Temperature float64
+	// TemperatureMoves limits temperature sampling to the first moves of a
+	// game. Zero applies it to the whole game.
+	TemperatureMoves int
+	Seed             int64
+}
+
+// DefaultConfig returns the configuration used by the selfplay command.
+func DefaultConfig() Config {
+	return Config{
+		Games:              100,
+		MaxMoves:           150,
+		Komi:               7,
+		RandomOpeningMoves: 4,
+		Temperature:        10,
+		TemperatureMoves:   20,
+		Seed:               1,
+	}
+}
+
+// IndexEntry is one line of the JSONL index written next to the SGF files.
+type IndexEntry struct {
+	File       string  `json:"file"`
+	Black      string  `json:"black"`
+	White      string  `json:"white"`
+	Result     string  `json:"result"`
+	Winner     string  `json:"winner"`
+	Moves      int     `json:"moves"`
+	BlackScore float64 `json:"black_score"`
+	WhiteScore float64 `json:"white_score"`
+	// Seed plays the game again: PlayGame of a generator with Seed as
+	// Config.Seed makes the same random choices.
+	Seed int64 `json:"seed"`
+}
+
+// Generator plays self-play games.
+type Generator struct {
+	Config Config
+	rng    *rand.Rand
+}
+
+// NewGenerator returns a generator seeded with cfg.Seed.
+func NewGenerator(cfg Config) *Generator {
+	return &Generator{Config: cfg, rng: rand.New(rand.NewSource(cfg.Seed))}
+}
+
+// PlayGame plays a single game between black and white and returns its
+// record together with the final area scores including komi.
+func (g *Generator) PlayGame(black, white Player) (result *sgf.Game, blackScore, whiteScore float64) {
+	record := compareengines.PlayGame(
+		g.moveFunc(black.New()),
+		g.moveFunc(white.New()),
+		game.NewBoard(), game.Black, g.Config.MaxMoves,
+	)
+	b, w := game.CalculateScore(record.Board)
+	blackScore, whiteScore = float64(b), float64(w)+g.Config.Komi
+	result = &sgf.Game{
+		Black:  black.Name,
+		White:  white.Name,
+		Komi:   g.Config.Komi,
+		Result: sgf.FormatResult(blackScore, whiteScore),
+	}
+	for _, m := range record.Moves {
+		result.Moves = append(result.Moves, sgf.Move{Color: m.Player, Point: m.Point, Comment: m.Comment})
+	}
+	return result, blackScore, whiteScore
+}
+
+// moveFunc wraps e with the opening randomisation and temperature sampling
+// of the configuration. Moves chosen by an analyzer carry their score as comment.
+func (g *Generator) moveFunc(e engine.Engine) compareengines.MoveFunc {
+	return func(board game.Board, player game.FieldState, ko *game.Point, moveNumber int) (*game.Point, string) {
+		if moveNumber < g.Config.RandomOpeningMoves {
+			if move := g.randomMove(board, player, ko); move != nil {
+				return move, "random opening move"
+			}
+		}
+		analyzer, ok := e.(engine.Analyzer)
+		if !ok {
+			return e.Move(board, player, ko), ""
+		}
+		scores := analyzer.Analyze(board, player, ko)
+		var chosen engine.MoveScore
+		if g.Config.Temperature > 0 && (g.Config.TemperatureMoves == 0 || moveNumber < g.Config.TemperatureMoves) {
+			chosen = g.sample(scores)
+		} else {
+			chosen = best(scores)
+		}
+		return chosen.Move, fmt.Sprintf("eval %d", chosen.Score)
+	}
+}
+
+// randomMove returns a uniformly chosen legal move, or nil if there is none.
+func (g *Generator) randomMove(board game.Board, player game.FieldState, ko *game.Point) *game.Point {
+	legal := game.LegalMoves(game.State{Board: board, ToMove: player, Ko: ko})
+	if len(legal) == 0 {
+		return nil
+	}
+	pt := legal[g.rng.Intn(len(legal))]
+	return &pt
+}
+
+// sample picks a move with probability proportional to exp(score/Temperature).
+func (g *Generator) sample(scores []engine.MoveScore) engine.MoveScore {
+	top := best(scores).Score
+	weights := make([]float64, len(scores))
+	total := 0.0
+	for i, ms := range scores {
+		weights[i] = math.Exp(float64(ms.Score-top) / g.Config.Temperature)
+		total += weights[i]
+	}
+	x := g.rng.Float64() * total
+	for i, w := range weights {
+		x -= w
+		if x < 0 {
+			return scores[i]
+		}
+	}
+	return scores[len(scores)-1]
+}
+
+// best returns the highest scoring move. On ties the earlier move wins,
+// except that passing wins ties as in AlphaBetaEngine.Move.
+func best(scores []engine.MoveScore) engine.MoveScore {
+	chosen := scores[0]
+	for _, ms := range scores[1:] {
+		if ms.Score > chosen.Score || (ms.Move == nil && ms.Score == chosen.Score) {
+			chosen = ms
+		}
+	}
+	return chosen
+}
+
+// Run plays cfg.Games games, alternating colours between a and b, writes
+// each game to dir as game-NNNNNN.sgf and appends an entry per game to
+// dir/index.jsonl. Numbering continues after the games already in dir.
+// progress, if not nil, receives one line per game.
+func (g *Generator) Run(a, b Player, dir string, progress io.Writer) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	first, err := nextGame(dir)
+	if err != nil {
+		return err
+	}
+	index, err := os.OpenFile(filepath.Join(dir, "index.jsonl"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer index.Close()
+	enc := json.NewEncoder(index)
+
+	// Every game gets a seed of its own, so that it can be played again
+	// without the games before it.
+	seeds := rand.New(rand.NewSource(g.Config.Seed))
+	for i := 0; i < g.Config.Games; i++ {
+		black, white := a, b
+		if i%2 == 1 {
+			black, white = b, a
+		}
+		seed := seeds.Int63()
+		g.rng.Seed(seed)
+		record, blackScore, whiteScore := g.PlayGame(black, white)
+		name := fmt.Sprintf("game-%06d.sgf", first+i)
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		err = sgf.Write(f, record.Tree())
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+
+		entry := IndexEntry{
+			File:       name,
+			Black:      black.Name,
+			White:      white.Name,
+			Result:     record.Result,
+			Winner:     winnerName(record.Winner()),
+			Moves:      len(record.Moves),
+			BlackScore: blackScore,
+			WhiteScore: whiteScore,
+			Seed:       seed,
+		}
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+		if progress != nil {
+			fmt.Fprintf(progress, "%s: %s (B) vs %s (W) %s\n", name, black.Name, white.Name, record.Result)
+		}
+	}
+	return nil
+}
+
+// nextGame returns the number after the highest game-NNNNNN.sgf in dir.
+func nextGame(dir string) (int, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "game-*.sgf"))
+	if err != nil {
+		return 0, err
+	}
+	next := 1
+	for _, name := range names {
+		var n int
+		if _, err := fmt.Sscanf(filepath.Base(name), "game-%d.sgf", &n); err == nil {
+			next = max(next, n+1)
+		}
+	}
+	return next, nil
+}
+
+func winnerName(c game.FieldState) string {
+	switch c {
+	case game.Black:
+		return "B"
+	case game.White:
+		return "W"
+	}
+	return ""
+}
@@ -0,0 +1,129 @@
+package selfplay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/RubikNube/GoInGo/pkg/engine"
+	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/sgf"
+)
+
+func TestRunWritesGamesAndIndex(t *testing.T) {
+	dir := t.TempDir()
+	random := Player{Name: "random", New: func() engine.Engine { return engine.NewRandomEngine() }}
+	cfg := Config{Games: 3, MaxMoves: 20, Komi: 7, RandomOpeningMoves: 2, Seed: 42}
+	if err := NewGenerator(cfg).Run(random, random, dir, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "index.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var entries []IndexEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e IndexEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 index entries, got %d", len(entries))
+	}
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, e.File))
+		if err != nil {
+			t.Fatal(err)
+		}
+		roots, err := sgf.Parse(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		g, err := sgf.MainLine(roots[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(g.Moves) != e.Moves || g.Result != e.Result {
+			t.Errorf("Index entry %+v does not match game with %d moves and result %s", e, len(g.Moves), g.Result)
+		}
+		if g.Moves[0].Comment != "random opening move" {
+			t.Errorf("Expected opening move comment, got %q", g.Moves[0].Comment)
+		}
+	}
+}
+
+func TestRunContinuesAndRecordsSeedsPerGame(t *testing.T) {
+	dir := t.TempDir()
+	random := Player{Name: "random", New: func() engine.Engine { return engine.NewRandomEngine() }}
+	// Only random opening moves, which come from the generator's seed.
+	cfg := Config{Games: 2, MaxMoves: 10, RandomOpeningMoves: 10, Seed: 7}
+	for range 2 {
+		if err := NewGenerator(cfg).Run(random, random, dir, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "index.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []IndexEntry
+	for _, line := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+		var e IndexEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) != 4 || entries[2].File != "game-000003.sgf" || entries[3].File != "game-000004.sgf" {
+		t.Fatalf("Expected the second run to continue the numbering, got %+v", entries)
+	}
+	if entries[0].Seed == entries[1].Seed {
+		t.Errorf("Expected a seed per game, got %d twice", entries[0].Seed)
+	}
+
+	record, err := os.ReadFile(filepath.Join(dir, entries[1].File))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay := cfg
+	replay.Seed = entries[1].Seed
+	g, _, _ := NewGenerator(replay).PlayGame(random, random)
+	var buf bytes.Buffer
+	if err := sgf.Write(&buf, g.Tree()); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != string(record) {
+		t.Errorf("Expected the seed to play the game again, got\n%s\nwant\n%s", buf.String(), record)
+	}
+}
+
+func TestTemperatureSamplingAddsEvaluation(t *testing.T) {
+	ab := Player{Name: "alphabeta", New: func() engine.Engine { return engine.NewAlphaBetaEngine() }}
+	random := Player{Name: "random", New: func() engine.Engine { return engine.NewRandomEngine() }}
+	cfg := Config{MaxMoves: 2, Temperature: 5, Seed: 1}
+	g, _, _ := NewGenerator(cfg).PlayGame(ab, random)
+	if len(g.Moves) == 0 || g.Moves[0].Comment == "" {
+		t.Fatalf("Expected an evaluation comment on the analyzer's move, got %+v", g.Moves)
+	}
+	if len(g.Moves) > 1 && g.Moves[1].Comment != "" {
+		t.Errorf("Expected no comment for an engine without analysis, got %q", g.Moves[1].Comment)
+	}
+}
+
+func TestBestPrefersPassOnTie(t *testing.T) {
+	move := engine.MoveScore{Move: &game.Point{Row: 4, Col: 4}, Score: 3}
+	pass := engine.MoveScore{Move: nil, Score: 3}
+	for _, scores := range [][]engine.MoveScore{{move, pass}, {pass, move}} {
+		if best(scores).Move != nil {
+			t.Errorf("Expected pass on a tie, got %+v", best(scores).Move)
+		}
+	}
+}
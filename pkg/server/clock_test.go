@@ -0,0 +1,127 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTime is a time source that only moves when told to.
+type fakeTime struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (f *fakeTime) now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.t
+}
+
+func (f *fakeTime) advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.t = f.t.Add(d)
+}
+
+func newTimedClient(t *testing.T) (*client, *Server, *fakeTime) {
+	f := &fakeTime{t: time.Unix(0, 0)}
+	s := New(NewMemoryStore())
+	s.now = f.now
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return &client{t: t, srv: srv}, s, f
+}
+
+func TestClock(t *testing.T) {
+	c, s, f := newTimedClient(t)
+	v := c.create(`{"time": "fischer:1m+10s"}`)
+	if v.Clock == nil || v.Clock.Running != "black" || v.Clock.Black.Main != 60 || v.Clock.TimeControl != "fischer:1m0s+10s" {
+		t.Fatalf("Expected Black's clock to run, got %+v", v.Clock)
+	}
+	ws := c.subscribe(v.ID, "")
+	ws.expect(EventSync)
+
+	f.advance(20 * time.Second)
+	c.do("POST", "/games/"+v.ID+"/play", `{"row":4,"col":4}`, &v)
+	if v.Clock.Running != "white" || v.Clock.Black.Main != 50 || v.Clock.Black.Display != "0:50" {
+		t.Errorf("Expected 40s plus the increment for Black and White's clock to run, got %+v", v.Clock)
+	}
+	if e := ws.expect(EventMove, EventClock)[1]; e.Clock.Running != "white" {
+		t.Errorf("Expected a clock event after the move, got %+v", e)
+	}
+
+	f.advance(2 * time.Minute)
+	s.flag(v.ID)
+	events := ws.expect(EventGameOver, EventClock)
+	if events[0].Result != "B+T" || !events[1].Clock.White.Flagged || events[1].Clock.Running != "" {
+		t.Errorf("Expected White to lose on time, got %+v and %+v", events[0], events[1].Clock)
+	}
+	if status := c.do("POST", "/games/"+v.ID+"/play", `{"row":3,"col":3}`, &map[string]string{}); status != http.StatusConflict {
+		t.Errorf("Expected 409 after the flag fell, got %d", status)
+	}
+	if status := c.do("POST", "/games/"+v.ID+"/undo", "", &map[string]string{}); status != http.StatusConflict {
+		t.Errorf("Expected a loss on time not to be undone, got %d", status)
+	}
+	if len(s.clocks) != 0 || len(s.timers) != 0 {
+		t.Errorf("Expected the clock of the finished game to be dropped, got %d clocks and %d timers", len(s.clocks), len(s.timers))
+	}
+	c.do("GET", "/games/"+v.ID, "", &v)
+	if !v.Clock.White.Flagged || v.Clock.Black.Main != 50 {
+		t.Errorf("Expected the final clocks of the finished game, got %+v", v.Clock)
+	}
+}
+
+func TestClockAfterResignAndUndo(t *testing.T) {
+	c, s, f := newTimedClient(t)
+	id := c.create(`{"time": "absolute:1m"}`).ID
+	f.advance(20 * time.Second)
+	var v View
+	c.do("POST", "/games/"+id+"/resign", "", &v)
+	if v.Clock.Running != "" || v.Clock.Black.Main != 40 || len(s.clocks) != 0 || len(s.timers) != 0 {
+		t.Errorf("Expected the clock to stop and be dropped, got %+v and %d clocks", v.Clock, len(s.clocks))
+	}
+	f.advance(time.Hour)
+	c.do("POST", "/games/"+id+"/undo", "", &v)
+	if v.Clock.Running != "black" || v.Clock.Black.Main != 40 || len(s.timers) != 1 {
+		t.Errorf("Expected Black's clock to run on from 40s, got %+v", v.Clock)
+	}
+}
+
+func TestClockFlagOnRequest(t *testing.T) {
+	c, _, f := newTimedClient(t)
+	id := c.create(`{"time": "byoyomi:10s+2x5s"}`).ID
+	f.advance(15 * time.Second)
+	var v View
+	c.do("GET", "/games/"+id, "", &v)
+	if v.Over || !v.Clock.Black.Overtime || v.Clock.Black.Periods != 1 {
+		t.Errorf("Expected Black in the last period, got %+v", v.Clock.Black)
+	}
+	f.advance(5 * time.Second)
+	c.do("GET", "/games/"+id, "", &v)
+	if !v.Over || v.Result != "W+T" {
+		t.Errorf("Expected the game to end when read after the flag fell, got %+v", v)
+	}
+}
+
+func TestClockInSGF(t *testing.T) {
+	c, _, _ := newTimedClient(t)
+	id := c.create(`{"time": "byoyomi:10m+5x30s"}`).ID
+	resp, err := http.Get(c.srv.URL + "/games/" + id + "/sgf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if record := string(data); !strings.Contains(record, "TM[600]") || !strings.Contains(record, "OT[5x30 byo-yomi]") {
+		t.Errorf("Expected the time control in the record, got %s", record)
+	}
+	var e map[string]string
+	if status := c.do("POST", "/games", `{"time": "hourglass:1m"}`, &e); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown time system, got %d", status)
+	}
+}
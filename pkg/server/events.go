@@ -0,0 +1,177 @@
+package server
+
+import (
+	"sync"
+)
+
+// Event types sent to the subscribers of a game.
+const (
+	// EventSync carries the whole game. It is the first event of a
+	// subscription unless the missed events can be replayed.
+	EventSync = "sync"
+	// EventMove and EventPass carry the move played.
+	EventMove = "move"
+	EventPass = "pass"
+	// EventCapture follows a move that captured stones and carries it.
+	EventCapture = "capture"
+	// EventUndo carries the game after a move or resignation was taken back.
+	EventUndo = "undo"
+	// EventGameOver carries the finished game.
+	EventGameOver = "gameover"
+	// EventClock carries the clocks of a timed game after every change of
+	// the running clock.
+	EventClock = "clock"
+)
+
+// Event is a change of a game. Seq numbers the events of a game without
+// gaps so that a client reconnecting after the event with Seq n can ask for
+// the events after it. Numbering starts at 1, or after the highest number
+// of the finished games the hub forgot, so that a game reopened after its
+// events were released never numbers two events alike.
+type Event struct {
+	Seq    int        `json:"seq"`
+	Type   string     `json:"type"`
+	Move   *MoveView  `json:"move,omitempty"`
+	Result string     `json:"result,omitempty"`
+	Game   *View      `json:"game,omitempty"`
+	Clock  *ClockView `json:"clock,omitempty"`
+}
+
+// maxBacklog is the number of events kept per game for replay; clients
+// further behind receive a sync event instead.
+const maxBacklog = 1024
+
+// subscriberBuffer is the number of events a subscriber may fall behind
+// before it is dropped; it resyncs when it reconnects.
+const subscriberBuffer = 64
+
+// feed holds the recent events and the subscribers of a game.
+type feed struct {
+	seq      int
+	backlog  []Event
+	subs     map[chan Event]struct{}
+	released bool // whether the game ended; the feed goes with its last subscriber
+}
+
+// hub distributes the events of all games.
+type hub struct {
+	mu    sync.Mutex
+	feeds map[string]*feed
+	// floor is the highest sequence number of the feeds removed.
+	floor int
+}
+
+func newHub() *hub {
+	return &hub{feeds: make(map[string]*feed)}
+}
+
+func (h *hub) feed(id string) *feed {
+	f, ok := h.feeds[id]
+	if !ok {
+		f = &feed{seq: h.floor, subs: make(map[chan Event]struct{})}
+		h.feeds[id] = f
+	}
+	return f
+}
+
+// release drops the backlog of the finished game id, and its feed once
+// nobody follows the game.
+func (h *hub) release(id string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if f, ok := h.feeds[id]; ok {
+		f.backlog, f.released = nil, true
+		h.drop(id, f)
+	}
+}
+
+// drop removes the released feed f of game id if it has no subscribers.
+// The caller must hold h.mu.
+func (h *hub) drop(id string, f *feed) {
+	if f.released && len(f.subs) == 0 {
+		h.floor = max(h.floor, f.seq)
+		delete(h.feeds, id)
+	}
+}
+
+// publish numbers events and sends them to the subscribers of game id.
+// Subscribers that cannot keep up are closed.
+func (h *hub) publish(id string, events ...Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	f := h.feed(id)
+	f.released = false
+	for _, e := range events {
+		f.seq++
+		e.Seq = f.seq
+		f.backlog = append(f.backlog, e)
+		if len(f.backlog) > maxBacklog {
+			f.backlog = f.backlog[len(f.backlog)-maxBacklog:]
+		}
+		for ch := range f.subs {
+			select {
+			case ch <- e:
+			default:
+				delete(f.subs, ch)
+				close(ch)
+			}
+		}
+	}
+}
+
+// subscribe returns the events of game id after since, or a sync event
+// with view if they are no longer known, followed by a channel of later
+// events. The caller must hold the server lock so that view shows the game
+// as of the last published event.
+func (h *hub) subscribe(id string, since int, view View) ([]Event, chan Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	f := h.feed(id)
+	ch := make(chan Event, subscriberBuffer)
+	f.subs[ch] = struct{}{}
+	// The backlog holds the events up to f.seq without gaps.
+	if missed := f.seq - since; since >= 0 && missed >= 0 && missed <= len(f.backlog) {
+		return append([]Event(nil), f.backlog[len(f.backlog)-missed:]...), ch
+	}
+	return []Event{{Seq: f.seq, Type: EventSync, Game: &view}}, ch
+}
+
+// unsubscribe removes ch from game id unless publish already dropped it.
+func (h *hub) unsubscribe(id string, ch chan Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if f, ok := h.feeds[id]; ok {
+		if _, ok := f.subs[ch]; ok {
+			delete(f.subs, ch)
+			close(ch)
+		}
+		h.drop(id, f)
+	}
+}
+
+// changes returns the events that turn before into after, one of which is
+// the result of a single game operation.
+func changes(before, after *Game) []Event {
+	var events []Event
+	switch {
+	case len(after.Moves) > len(before.Moves):
+		mv := NewView(after).Moves[len(after.Moves)-1]
+		m := after.Moves[len(after.Moves)-1]
+		if m.Point == nil {
+			events = append(events, Event{Type: EventPass, Move: &mv})
+		} else {
+			events = append(events, Event{Type: EventMove, Move: &mv})
+		}
+		if len(m.Captured) > 0 {
+			events = append(events, Event{Type: EventCapture, Move: &mv})
+		}
+	case len(after.Moves) < len(before.Moves) || before.Over() && !after.Over():
+		v := NewView(after)
+		events = append(events, Event{Type: EventUndo, Game: &v})
+	}
+	if after.Over() && !before.Over() {
+		v := NewView(after)
+		events = append(events, Event{Type: EventGameOver, Result: after.Result, Game: &v})
+	}
+	return events
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// wsClient is a minimal WebSocket client reading the event stream.
+type wsClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func (c *client) subscribe(id, query string) *wsClient {
+	c.t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(c.srv.URL, "http://"))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	c.t.Cleanup(func() { conn.Close() })
+	fmt.Fprintf(conn, "GET /games/%s/events%s HTTP/1.1\r\nHost: test\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n", id, query)
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		c.t.Fatalf("Expected 101, got %d", resp.StatusCode)
+	}
+	if accept := resp.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		c.t.Errorf("Expected the accept key of RFC 6455, got %q", accept)
+	}
+	return &wsClient{t: c.t, conn: conn, r: r}
+}
+
+// next reads the next event.
+func (ws *wsClient) next() Event {
+	ws.t.Helper()
+	ws.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	op, payload, err := readFrame(ws.r, false)
+	if err != nil {
+		ws.t.Fatalf("Expected an event, got %v", err)
+	}
+	if op != opText {
+		ws.t.Fatalf("Expected a text frame, got opcode %d", op)
+	}
+	var e Event
+	if err := json.Unmarshal(payload, &e); err != nil {
+		ws.t.Fatal(err)
+	}
+	return e
+}
+
+// expect reads events and checks their types.
+func (ws *wsClient) expect(types ...string) []Event {
+	ws.t.Helper()
+	events := make([]Event, len(types))
+	for i, typ := range types {
+		events[i] = ws.next()
+		if events[i].Type != typ {
+			ws.t.Fatalf("Expected event %d to be %q, got %+v", i, typ, events[i])
+		}
+	}
+	return events
+}
+
+func TestEventsSpectators(t *testing.T) {
+	c := newClient(t)
+	id := c.create("").ID
+	a, b := c.subscribe(id, ""), c.subscribe(id, "")
+	for _, ws := range []*wsClient{a, b} {
+		if e := ws.expect(EventSync)[0]; e.Seq != 0 || e.Game == nil || e.Game.ID != id {
+			t.Errorf("Expected a sync event of the new game, got %+v", e)
+		}
+	}
+	c.do("POST", "/games/"+id+"/play", `{"row":0,"col":1}`, nil)
+	c.do("POST", "/games/"+id+"/play", `{"row":0,"col":0}`, nil)
+	c.do("POST", "/games/"+id+"/play", `{"row":1,"col":0}`, nil)
+	c.do("POST", "/games/"+id+"/pass", "", nil)
+	c.do("POST", "/games/"+id+"/pass", "", nil)
+	for _, ws := range []*wsClient{a, b} {
+		events := ws.expect(EventMove, EventMove, EventMove, EventCapture, EventPass, EventPass, EventGameOver)
+		for i, e := range events {
+			if e.Seq != i+1 {
+				t.Errorf("Expected event %d to have seq %d, got %d", i, i+1, e.Seq)
+			}
+		}
+		if m := events[3].Move; m.Number != 3 || m.Point != "ab" || len(m.Captured) != 1 {
+			t.Errorf("Expected the capture of move 3, got %+v", m)
+		}
+		if events[6].Result == "" || !events[6].Game.Over {
+			t.Errorf("Expected the result with the game over event, got %+v", events[6])
+		}
+	}
+}
+
+func TestEventsUndo(t *testing.T) {
+	c := newClient(t)
+	id := c.create("").ID
+	ws := c.subscribe(id, "")
+	ws.expect(EventSync)
+	c.do("POST", "/games/"+id+"/play", `{"row":4,"col":4}`, nil)
+	c.do("POST", "/games/"+id+"/undo", "", nil)
+	c.do("POST", "/games/"+id+"/resign", "", nil)
+	c.do("POST", "/games/"+id+"/undo", "", nil)
+	events := ws.expect(EventMove, EventUndo, EventGameOver, EventUndo)
+	if len(events[1].Game.Moves) != 0 || events[2].Result != "W+R" || events[3].Game.Over {
+		t.Errorf("Expected undo events with the game, got %+v", events)
+	}
+}
+
+func TestEventsResync(t *testing.T) {
+	c := newClient(t)
+	id := c.create("").ID
+	c.do("POST", "/games/"+id+"/play", `{"row":4,"col":4}`, nil)
+	c.do("POST", "/games/"+id+"/play", `{"row":3,"col":3}`, nil)
+	c.do("POST", "/games/"+id+"/pass", "", nil)
+
+	events := c.subscribe(id, "?since=1").expect(EventMove, EventPass)
+	if events[0].Seq != 2 || events[0].Move.Point != "dd" || events[1].Seq != 3 {
+		t.Errorf("Expected the events after seq 1, got %+v", events)
+	}
+	ws := c.subscribe(id, "?since=3")
+	c.do("POST", "/games/"+id+"/play", `{"row":0,"col":0}`, nil)
+	if e := ws.expect(EventMove)[0]; e.Seq != 4 {
+		t.Errorf("Expected only the new move after catching up, got %+v", e)
+	}
+	if e := c.subscribe(id, "?since=99").expect(EventSync)[0]; e.Seq != 4 || len(e.Game.Moves) != 4 {
+		t.Errorf("Expected a sync event for an unknown seq, got %+v", e)
+	}
+}
+
+func TestEventsErrors(t *testing.T) {
+	c := newClient(t)
+	id := c.create("").ID
+	for path, want := range map[string]int{
+		"/games/nope/events":               http.StatusNotFound,
+		"/games/" + id + "/events":         http.StatusBadRequest, // not a websocket
+		"/games/" + id + "/events?since=x": http.StatusBadRequest,
+	} {
+		if status := c.do("GET", path, "", &map[string]string{}); status != want {
+			t.Errorf("Expected %d for %s, got %d", want, path, status)
+		}
+	}
+}
+
+func TestEventsReleasedWhenGameEnds(t *testing.T) {
+	c, s, _ := newTimedClient(t)
+	id := c.create(`{"engine": "random"}`).ID
+	ws := c.subscribe(id, "")
+	ws.expect(EventSync)
+	c.do("POST", "/games/"+id+"/engine-move", "", nil)
+	c.do("POST", "/games/"+id+"/resign", "", nil)
+	last := ws.next()
+	for last.Type != EventGameOver {
+		last = ws.next()
+	}
+	s.mu.Lock()
+	players := len(s.players)
+	s.mu.Unlock()
+	if players != 0 {
+		t.Errorf("Expected the engine of the finished game to be released, got %d", players)
+	}
+
+	ws.conn.Close()
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		s.events.mu.Lock()
+		feeds := len(s.events.feeds)
+		s.events.mu.Unlock()
+		if feeds == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the feed to go with its last subscriber, got %d feeds", feeds)
+		}
+	}
+
+	c.do("POST", "/games/"+id+"/undo", "", nil)
+	if e := c.subscribe(id, fmt.Sprintf("?since=%d", last.Seq)).expect(EventUndo)[0]; e.Seq != last.Seq+1 {
+		t.Errorf("Expected the numbering to go on after the reopened game's last event, got %d", e.Seq)
+	}
+	if e := c.subscribe(id, "?since=1").expect(EventSync)[0]; e.Game.Over {
+		t.Errorf("Expected a sync event of the reopened game, got %+v", e)
+	}
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/RubikNube/GoInGo/pkg/clock"
+	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/sgf"
+)
+
+var (
+	ErrGameOver      = errors.New("server: game is over")
+	ErrNothingToUndo = errors.New("server: nothing to undo")
+)
+
+// handicapPoints lists the handicap stones for 2 to 9 stones on 9x9.
+var handicapPoints = func() map[int][]game.Point {
+	star := func(codes ...string) []game.Point {
+		points := make([]game.Point, len(codes))
+		for i, c := range codes {
+			p, _ := sgf.DecodePoint(c)
+			points[i] = *p
+		}
+		return points
+	}
+	return map[int][]game.Point{
+		2: star("gc", "cg"),
+		3: star("gc", "cg", "gg"),
+		4: star("gc", "cg", "gg", "cc"),
+		5: star("gc", "cg", "gg", "cc", "ee"),
+		6: star("gc", "cg", "gg", "cc", "ce", "ge"),
+		7: star("gc", "cg", "gg", "cc", "ce", "ge", "ee"),
+		8: star("gc", "cg", "gg", "cc", "ce", "ge", "ec", "eg"),
+		9: star("gc", "cg", "gg", "cc", "ce", "ge", "ec", "eg", "ee"),
+	}
+}()
+
+// Move is a move of a game. Point is nil for a pass.
+type Move struct {
+	Color    game.FieldState
+	Point    *game.Point
+	Captured []game.Point
+}
+
+// Game is a game played through the server.
+type Game struct {
+	ID       string
+	Komi     float64
+	Handicap int
+	Engine   string // engine for engine moves
+	Moves    []Move
+	// States holds the state before every move followed by the current one.
+	States []game.State
+	// Resigned is the colour that resigned, Empty if none did.
+	Resigned game.FieldState
+	// TimeControl is the time control of the game, nil if it is untimed;
+	// TimedOut is the colour that ran out of time, Empty if none did;
+	// TimeLeft is the time Black and White had left when the game last
+	// ended, nil if it never did.
+	TimeControl *clock.TimeControl
+	TimedOut    game.FieldState
+	TimeLeft    *[2]clock.State
+	Result      string // SGF result once the game is over
+}
+
+// NewGame returns a game with the handicap stones placed; with a handicap
+// White moves first.
+func NewGame(komi float64, handicap int, engineName string) *Game {
+	var board game.Board
+	for _, p := range handicapPoints[handicap] {
+		board[p.Row][p.Col] = game.Black
+	}
+	toMove := game.Black
+	if handicap >= 2 {
+		toMove = game.White
+	}
+	return &Game{
+		Komi:     komi,
+		Handicap: handicap,
+		Engine:   engineName,
+		States:   []game.State{game.NewState(board, toMove)},
+	}
+}
+
+// State returns the current position.
+func (g *Game) State() game.State {
+	return g.States[len(g.States)-1]
+}
+
+// Over reports whether the game has ended by resignation or two passes.
+func (g *Game) Over() bool {
+	return g.Result != ""
+}
+
+// Play plays p for the side to move, nil for a pass. Two consecutive
+// passes end the game, which is then scored by area with komi.
+func (g *Game) Play(p *game.Point) error {
+	if g.Over() {
+		return ErrGameOver
+	}
+	s := g.State()
+	next, captured, err := game.Play(s, p)
+	if err != nil {
+		return err
+	}
+	g.Moves = append(g.Moves, Move{Color: s.ToMove, Point: p, Captured: captured})
+	g.States = append(g.States, next)
+	if n := len(g.Moves); n >= 2 && g.Moves[n-1].Point == nil && g.Moves[n-2].Point == nil {
+		black, white := g.Score()
+		g.Result = sgf.FormatResult(black, white)
+	}
+	return nil
+}
+
+// Resign ends the game with a loss for color.
+func (g *Game) Resign(color game.FieldState) error {
+	if g.Over() {
+		return ErrGameOver
+	}
+	g.Resigned = color
+	if color == game.Black {
+		g.Result = "W+R"
+	} else {
+		g.Result = "B+R"
+	}
+	return nil
+}
+
+// LoseOnTime ends the game with a loss on time for color.
+func (g *Game) LoseOnTime(color game.FieldState) error {
+	if g.Over() {
+		return ErrGameOver
+	}
+	g.TimedOut = color
+	if color == game.Black {
+		g.Result = "W+T"
+	} else {
+		g.Result = "B+T"
+	}
+	return nil
+}
+
+// Undo takes back a resignation, or else the last move, and reopens a
+// finished game. A loss on time cannot be taken back.
+func (g *Game) Undo() error {
+	if g.TimedOut != game.Empty {
+		return ErrGameOver
+	}
+	if g.Resigned != game.Empty {
+		g.Resigned, g.Result = game.Empty, ""
+		return nil
+	}
+	if len(g.Moves) == 0 {
+		return ErrNothingToUndo
+	}
+	g.Moves = g.Moves[:len(g.Moves)-1]
+	g.States = g.States[:len(g.States)-1]
+	g.Result = ""
+	return nil
+}
+
+// Score returns the area scores of the current position, with komi for White.
+func (g *Game) Score() (black, white float64) {
+	b, w := game.CalculateScore(g.State().Board)
+	return float64(b), float64(w) + g.Komi
+}
+
+// Captures returns the number of stones captured by Black and by White.
+func (g *Game) Captures() (black, white int) {
+	for _, m := range g.Moves {
+		if m.Color == game.Black {
+			black += len(m.Captured)
+		} else {
+			white += len(m.Captured)
+		}
+	}
+	return black, white
+}
+
+// SGF returns the game record.
+func (g *Game) SGF() *sgf.Node {
+	record := &sgf.Game{Komi: g.Komi, Result: g.Result, SetupBlack: handicapPoints[g.Handicap]}
+	if g.Engine != "" {
+		record.Comment = "Engine: " + g.Engine
+	}
+	for _, m := range g.Moves {
+		record.Moves = append(record.Moves, sgf.Move{Color: m.Color, Point: m.Point})
+	}
+	root := record.Tree()
+	if g.Handicap > 0 {
+		root.Set("HA", strconv.Itoa(g.Handicap))
+	}
+	if tc := g.TimeControl; tc != nil {
+		root.Set("TM", strconv.FormatFloat(tc.Main.Seconds(), 'f', -1, 64))
+		if ot := tc.Overtime(); ot != "" {
+			root.Set("OT", ot)
+		}
+	}
+	return root
+}
+
+// clone returns a deep copy of g.
+func (g *Game) clone() *Game {
+	c := *g
+	c.Moves = append([]Move(nil), g.Moves...)
+	c.States = append([]game.State(nil), g.States...)
+	if g.TimeLeft != nil {
+		left := *g.TimeLeft
+		c.TimeLeft = &left
+	}
+	return &c
+}
@@ -0,0 +1,514 @@
+// Package server serves games over a JSON API. Games are created with a
+// komi, a handicap and an engine; moves, passes, resignations, undos and
+// engine moves are posted to the game, whose state and SGF record can be
+// read back:
+//
+//	POST /games                     create a game, returns its state
+//	GET  /games/{id}                the state of a game
+//	GET  /games/{id}/sgf            the game record
+//	POST /games/{id}/play           play {"row": r, "col": c} for the side to move
+//	POST /games/{id}/pass           pass for the side to move
+//	POST /games/{id}/resign         resign for the side to move
+//	POST /games/{id}/undo           take back the last move or resignation
+//	POST /games/{id}/engine-move    let the game's engine play for the side to move
+//	GET  /games/{id}/events         WebSocket stream of the game's events
+//
+// The event stream starts with a sync event holding the whole game, or,
+// when the client passes ?since=n with the sequence number of the last
+// event it saw, with the events it missed. Any number of clients may
+// follow a game.
+//
+// Errors are returned as {"error": "..."} with status 400 for invalid
+// requests, 404 for unknown games, 409 for finished games and 422 for
+// illegal moves.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/RubikNube/GoInGo/pkg/clock"
+	"github.com/RubikNube/GoInGo/pkg/engine"
+	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/league"
+	"github.com/RubikNube/GoInGo/pkg/sgf"
+)
+
+// DefaultEngine is the engine of games created without one.
+const DefaultEngine = "alphabeta"
+
+// Server is the HTTP handler of the API.
+type Server struct {
+	store   Store
+	engines []league.Entrant
+	mux     *http.ServeMux
+
+	// mu serialises the changes of games so that concurrent requests do
+	// not overwrite each other's moves.
+	mu sync.Mutex
+	// players holds an engine instance per game in progress, created on
+	// first use.
+	players map[string]*player
+	events  *hub
+	// clocks and timers hold the clocks of timed games in progress and
+	// the timers that end them when a flag falls.
+	clocks map[string]*clock.Clock
+	timers map[string]*time.Timer
+	now    func() time.Time
+}
+
+// player is the engine of a game; its mutex keeps concurrent engine move
+// requests from sharing the engine's search state.
+type player struct {
+	sync.Mutex
+	engine engine.Engine
+}
+
+// New returns a server keeping its games in store and offering the engines
+// of league.Builtin.
+func New(store Store) *Server {
+	s := &Server{
+		store:   store,
+		engines: league.Builtin(),
+		mux:     http.NewServeMux(),
+		players: make(map[string]*player),
+		events:  newHub(),
+		clocks:  make(map[string]*clock.Clock),
+		timers:  make(map[string]*time.Timer),
+		now:     time.Now,
+	}
+	s.mux.HandleFunc("POST /games", s.create)
+	s.mux.HandleFunc("GET /games/{id}", s.get)
+	s.mux.HandleFunc("GET /games/{id}/sgf", s.sgf)
+	s.mux.HandleFunc("POST /games/{id}/play", s.play)
+	s.mux.HandleFunc("POST /games/{id}/pass", s.update(func(g *Game) error { return g.Play(nil) }))
+	s.mux.HandleFunc("POST /games/{id}/resign", s.update(func(g *Game) error { return g.Resign(g.State().ToMove) }))
+	s.mux.HandleFunc("POST /games/{id}/undo", s.update((*Game).Undo))
+	s.mux.HandleFunc("POST /games/{id}/engine-move", s.engineMove)
+	s.mux.HandleFunc("GET /games/{id}/events", s.stream)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// CreateRequest is the body of POST /games. Omitted fields take their
+// defaults: size 9, komi 7 (0.5 with a handicap), no handicap,
+// DefaultEngine and no time control. Time is a time control in the format
+// of clock.Parse, e.g. "byoyomi:10m+5x30s".
+type CreateRequest struct {
+	Size     int      `json:"size"`
+	Komi     *float64 `json:"komi"`
+	Handicap int      `json:"handicap"`
+	Engine   string   `json:"engine"`
+	Time     string   `json:"time"`
+}
+
+// PlayRequest is the body of POST /games/{id}/play.
+type PlayRequest struct {
+	Row *int `json:"row"`
+	Col *int `json:"col"`
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	var req CreateRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Size != 0 && req.Size != game.BoardSize {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unsupported board size %d, only %d is supported", req.Size, game.BoardSize))
+		return
+	}
+	if req.Handicap < 0 || req.Handicap == 1 || req.Handicap > 9 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("handicap must be 0 or between 2 and 9, got %d", req.Handicap))
+		return
+	}
+	if req.Engine == "" {
+		req.Engine = DefaultEngine
+	}
+	if _, ok := league.Lookup(s.engines, req.Engine); !ok {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown engine %q", req.Engine))
+		return
+	}
+	komi := 7.0
+	if req.Handicap > 0 {
+		komi = 0.5
+	}
+	if req.Komi != nil {
+		komi = *req.Komi
+	}
+	g := NewGame(komi, req.Handicap, req.Engine)
+	if req.Time != "" {
+		tc, err := clock.Parse(req.Time)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		g.TimeControl = &tc
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.store.Create(g); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, s.view(g))
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g, ok := s.load(w, r)
+	if ok {
+		s.checkFlag(g)
+		writeJSON(w, http.StatusOK, s.view(g))
+	}
+}
+
+func (s *Server) sgf(w http.ResponseWriter, r *http.Request) {
+	g, ok := s.load(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-go-sgf")
+	sgf.Write(w, g.SGF())
+}
+
+func (s *Server) play(w http.ResponseWriter, r *http.Request) {
+	var req PlayRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Row == nil || req.Col == nil {
+		writeError(w, http.StatusBadRequest, errors.New("row and col are required"))
+		return
+	}
+	row, col := *req.Row, *req.Col
+	if row < 0 || row >= game.BoardSize || col < 0 || col >= game.BoardSize {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("point (%d, %d) is off the board", row, col))
+		return
+	}
+	p := game.Point{Row: int8(row), Col: int8(col)}
+	s.update(func(g *Game) error { return g.Play(&p) })(w, r)
+}
+
+// update returns a handler that applies f to the game, stores it and
+// publishes the change.
+func (s *Server) update(f func(g *Game) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		g, ok := s.load(w, r)
+		if !ok {
+			return
+		}
+		s.checkFlag(g)
+		before := g.clone()
+		if err := f(g); err != nil {
+			writeError(w, statusOf(err), err)
+			return
+		}
+		s.keepTime(g)
+		if err := s.store.Update(g); err != nil {
+			writeError(w, statusOf(err), err)
+			return
+		}
+		s.publish(before, g)
+		writeJSON(w, http.StatusOK, s.view(g))
+	}
+}
+
+// engineMove searches without holding the server lock and plays the move
+// only if the game has not changed in the meantime.
+func (s *Server) engineMove(w http.ResponseWriter, r *http.Request) {
+	g, ok := s.load(w, r)
+	if !ok {
+		return
+	}
+	if g.Over() {
+		writeError(w, http.StatusConflict, ErrGameOver)
+		return
+	}
+	pl, err := s.player(g)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	state, moves := g.State(), len(g.Moves)
+	var clockState *clock.State
+	s.mu.Lock()
+	if c := s.clock(g); c != nil {
+		cs := c.State(state.ToMove)
+		clockState = &cs
+	}
+	s.mu.Unlock()
+	pl.Lock()
+	if t, ok := pl.engine.(engine.TimeAware); ok && clockState != nil {
+		remaining, stones := clockState.TimeLeft()
+		if ts, ok := t.(engine.TimeSettingsAware); ok {
+			ts.TimeSettings(*g.TimeControl)
+		}
+		t.TimeLeft(state.ToMove, remaining, stones)
+	}
+	move := pl.engine.Move(state.Board, state.ToMove, state.Ko)
+	pl.Unlock()
+
+	s.update(func(g *Game) error {
+		if len(g.Moves) != moves || g.Over() {
+			return errChanged
+		}
+		return g.Play(move)
+	})(w, r)
+}
+
+// stream sends the events of a game over a WebSocket until the client
+// disconnects or falls too far behind.
+func (s *Server) stream(w http.ResponseWriter, r *http.Request) {
+	since := -1
+	if v := r.URL.Query().Get("since"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid since %q", v))
+			return
+		}
+		since = n
+	}
+	s.mu.Lock()
+	g, ok := s.load(w, r)
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+	s.checkFlag(g)
+	missed, ch := s.events.subscribe(g.ID, since, s.view(g))
+	if g.Over() {
+		s.events.release(g.ID)
+	}
+	s.mu.Unlock()
+	defer s.events.unsubscribe(g.ID, ch)
+	conn, err := upgrade(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	defer conn.Close()
+
+	closed := make(chan struct{})
+	go func() {
+		conn.readLoop()
+		close(closed)
+	}()
+	send := func(e Event) bool {
+		data, err := json.Marshal(e)
+		return err == nil && conn.WriteText(data) == nil
+	}
+	for _, e := range missed {
+		if !send(e) {
+			return
+		}
+	}
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok || !send(e) {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+// view returns the view of g with its clocks. The caller must hold s.mu.
+func (s *Server) view(g *Game) View {
+	v := NewView(g)
+	if c := s.clock(g); c != nil {
+		v.Clock = NewClockView(c)
+	} else if g.TimeControl != nil {
+		v.Clock = NewClockView(s.stoppedClock(g))
+	}
+	return v
+}
+
+// clock returns the running clocks of g, nil if it is untimed or over. The
+// clocks of a game the server has not seen yet, e.g. one created by an
+// earlier process or taken up again by an undo, are started for the side
+// to move. The caller must hold s.mu.
+func (s *Server) clock(g *Game) *clock.Clock {
+	if g.TimeControl == nil || g.Over() {
+		return nil
+	}
+	c, ok := s.clocks[g.ID]
+	if !ok {
+		c = s.stoppedClock(g)
+		c.Start(g.State().ToMove)
+		s.clocks[g.ID] = c
+		s.schedule(g.ID, c)
+	}
+	return c
+}
+
+// stoppedClock returns stopped clocks of the timed game g with the time
+// left when it last ended.
+func (s *Server) stoppedClock(g *Game) *clock.Clock {
+	if g.TimeLeft == nil {
+		return clock.New(*g.TimeControl, s.now)
+	}
+	return clock.Resume(*g.TimeControl, g.TimeLeft[0], g.TimeLeft[1], s.now)
+}
+
+// keepTime records the time left in g if the change to g ended it, since
+// the clocks of finished games are not kept. The caller must hold s.mu.
+func (s *Server) keepTime(g *Game) {
+	if c, ok := s.clocks[g.ID]; ok && g.Over() {
+		g.TimeLeft = &[2]clock.State{c.State(game.Black), c.State(game.White)}
+	}
+}
+
+// publish runs the clocks of g after the change from before, and sends
+// the events of the change. Once g is over, its clocks, engine and event
+// backlog are released; an undo that reopens it creates them again. The
+// caller must hold s.mu.
+func (s *Server) publish(before, g *Game) {
+	events := changes(before, g)
+	if c := s.clock(g); c != nil {
+		if len(g.Moves) == len(before.Moves)+1 {
+			c.Press()
+		} else {
+			c.Start(g.State().ToMove)
+		}
+		s.schedule(g.ID, c)
+		events = append(events, Event{Type: EventClock, Clock: NewClockView(c)})
+	} else if g.TimeControl != nil {
+		if t, ok := s.timers[g.ID]; ok {
+			t.Stop()
+			delete(s.timers, g.ID)
+		}
+		delete(s.clocks, g.ID)
+		events = append(events, Event{Type: EventClock, Clock: NewClockView(s.stoppedClock(g))})
+	}
+	s.events.publish(g.ID, events...)
+	if g.Over() {
+		delete(s.players, g.ID)
+		s.events.release(g.ID)
+	}
+}
+
+// checkFlag ends g if a player of g ran out of time, and reports whether
+// it did. The caller must hold s.mu.
+func (s *Server) checkFlag(g *Game) bool {
+	c := s.clock(g)
+	if c == nil {
+		return false
+	}
+	loser := c.Flagged()
+	if loser == game.Empty {
+		return false
+	}
+	before := g.clone()
+	g.LoseOnTime(loser)
+	s.keepTime(g)
+	if err := s.store.Update(g); err != nil {
+		return false
+	}
+	s.publish(before, g)
+	return true
+}
+
+// schedule arranges for game id to be checked when the running clock's
+// flag falls. The caller must hold s.mu.
+func (s *Server) schedule(id string, c *clock.Clock) {
+	if t, ok := s.timers[id]; ok {
+		t.Stop()
+		delete(s.timers, id)
+	}
+	if d, ok := c.UntilFlag(); ok {
+		s.timers[id] = time.AfterFunc(d, func() { s.flag(id) })
+	}
+}
+
+// flag ends game id if its flag fell, and otherwise checks again later.
+func (s *Server) flag(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g, err := s.store.Get(id)
+	if err != nil {
+		return
+	}
+	if !s.checkFlag(g) {
+		if c := s.clock(g); c != nil {
+			s.schedule(id, c)
+		}
+	}
+}
+
+var errChanged = errors.New("server: game changed during the engine's search")
+
+// player returns the engine instance of g.
+func (s *Server) player(g *Game) (*player, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if pl, ok := s.players[g.ID]; ok {
+		return pl, nil
+	}
+	e, ok := league.Lookup(s.engines, g.Engine)
+	if !ok {
+		return nil, fmt.Errorf("unknown engine %q", g.Engine)
+	}
+	pl := &player{engine: e.New()}
+	s.players[g.ID] = pl
+	return pl, nil
+}
+
+// load returns the game of the request, writing the error response if
+// there is none.
+func (s *Server) load(w http.ResponseWriter, r *http.Request) (*Game, bool) {
+	g, err := s.store.Get(r.PathValue("id"))
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return nil, false
+	}
+	return g, true
+}
+
+// statusOf returns the HTTP status for an error of a game operation.
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrGameOver), errors.Is(err, ErrNothingToUndo), errors.Is(err, errChanged):
+		return http.StatusConflict
+	case errors.Is(err, game.ErrOccupied), errors.Is(err, game.ErrSuicide),
+		errors.Is(err, game.ErrKo), errors.Is(err, game.ErrSuperko):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+// decode reads the JSON body of r into v; an empty body leaves v as is.
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,215 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RubikNube/GoInGo/pkg/sgf"
+)
+
+// client sends requests to a test server.
+type client struct {
+	t   *testing.T
+	srv *httptest.Server
+}
+
+func newClient(t *testing.T) *client {
+	srv := httptest.NewServer(New(NewMemoryStore()))
+	t.Cleanup(srv.Close)
+	return &client{t: t, srv: srv}
+}
+
+// do sends a request and decodes the JSON response into v, returning the status.
+func (c *client) do(method, path, body string, v any) int {
+	c.t.Helper()
+	req, err := http.NewRequest(method, c.srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			c.t.Fatalf("Expected JSON response to %s %s: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func (c *client) create(body string) View {
+	c.t.Helper()
+	var v View
+	if status := c.do("POST", "/games", body, &v); status != http.StatusCreated {
+		c.t.Fatalf("Expected 201 creating a game, got %d", status)
+	}
+	return v
+}
+
+func TestCreateGame(t *testing.T) {
+	c := newClient(t)
+	v := c.create("")
+	if v.ID == "" || v.Size != 9 || v.Komi != 7 || v.Engine != DefaultEngine || v.ToMove != "black" {
+		t.Errorf("Expected a default game, got %+v", v)
+	}
+	if len(v.Board) != 9 || v.Board[0] != "........." {
+		t.Errorf("Expected an empty board, got %v", v.Board)
+	}
+	var got View
+	if status := c.do("GET", "/games/"+v.ID, "", &got); status != http.StatusOK || got.ID != v.ID {
+		t.Errorf("Expected to read the game back, got %d %+v", status, got)
+	}
+}
+
+func TestCreateGameValidation(t *testing.T) {
+	c := newClient(t)
+	for _, body := range []string{
+		`{"size": 19}`,
+		`{"handicap": 1}`,
+		`{"handicap": 10}`,
+		`{"engine": "nope"}`,
+		`{"colour": "black"}`,
+		`{`,
+	} {
+		var e map[string]string
+		if status := c.do("POST", "/games", body, &e); status != http.StatusBadRequest || e["error"] == "" {
+			t.Errorf("Expected 400 with an error for %s, got %d %v", body, status, e)
+		}
+	}
+}
+
+func TestHandicap(t *testing.T) {
+	c := newClient(t)
+	v := c.create(`{"handicap": 2, "engine": "random"}`)
+	if v.ToMove != "white" || v.Komi != 0.5 {
+		t.Errorf("Expected White to move with komi 0.5, got %+v", v)
+	}
+	if v.Board[2][6] != 'X' || v.Board[6][2] != 'X' {
+		t.Errorf("Expected handicap stones on the star points, got %v", v.Board)
+	}
+}
+
+func TestPlayAndCapture(t *testing.T) {
+	c := newClient(t)
+	id := c.create(`{"komi": 5.5}`).ID
+	var v View
+	for _, move := range []string{`{"row":0,"col":1}`, `{"row":0,"col":0}`, `{"row":1,"col":0}`} {
+		if status := c.do("POST", "/games/"+id+"/play", move, &v); status != http.StatusOK {
+			t.Fatalf("Expected move %s to be played, got %d", move, status)
+		}
+	}
+	if v.Board[0][0] != '.' || v.Captures.Black != 1 || v.ToMove != "white" {
+		t.Errorf("Expected Black to capture the corner stone, got %+v", v)
+	}
+	if len(v.Moves) != 3 || v.Moves[2].Point != "ab" || len(v.Moves[2].Captured) != 1 || v.Moves[2].Captured[0] != "aa" {
+		t.Errorf("Expected the capture in the move list, got %+v", v.Moves)
+	}
+}
+
+func TestIllegalMoves(t *testing.T) {
+	c := newClient(t)
+	id := c.create("").ID
+	c.do("POST", "/games/"+id+"/play", `{"row":4,"col":4}`, nil)
+	for body, want := range map[string]int{
+		`{"row":4,"col":4}`: http.StatusUnprocessableEntity,
+		`{"row":9,"col":0}`: http.StatusBadRequest,
+		`{"row":1}`:         http.StatusBadRequest,
+	} {
+		if status := c.do("POST", "/games/"+id+"/play", body, &map[string]string{}); status != want {
+			t.Errorf("Expected %d for %s, got %d", want, body, status)
+		}
+	}
+}
+
+func TestPassesEndGame(t *testing.T) {
+	c := newClient(t)
+	id := c.create("").ID
+	var v View
+	c.do("POST", "/games/"+id+"/play", `{"row":4,"col":4}`, nil)
+	c.do("POST", "/games/"+id+"/pass", "", nil)
+	c.do("POST", "/games/"+id+"/pass", "", &v)
+	if !v.Over || v.Result != "B+74" || v.Score == nil || v.Score.Black != 81 || v.Score.White != 7 {
+		t.Errorf("Expected Black to win by 74, got %+v", v)
+	}
+	if status := c.do("POST", "/games/"+id+"/play", `{"row":0,"col":0}`, &map[string]string{}); status != http.StatusConflict {
+		t.Errorf("Expected 409 playing in a finished game, got %d", status)
+	}
+	var undone View
+	if status := c.do("POST", "/games/"+id+"/undo", "", &undone); status != http.StatusOK || undone.Over || len(undone.Moves) != 2 {
+		t.Errorf("Expected undo to reopen the game, got %d %+v", status, undone)
+	}
+}
+
+func TestResignAndUndo(t *testing.T) {
+	c := newClient(t)
+	id := c.create("").ID
+	var v View
+	if status := c.do("POST", "/games/"+id+"/undo", "", &map[string]string{}); status != http.StatusConflict {
+		t.Errorf("Expected 409 undoing without moves, got %d", status)
+	}
+	c.do("POST", "/games/"+id+"/resign", "", &v)
+	if !v.Over || v.Result != "W+R" {
+		t.Errorf("Expected Black to resign, got %+v", v)
+	}
+	var undone View
+	c.do("POST", "/games/"+id+"/undo", "", &undone)
+	if undone.Over || undone.Result != "" {
+		t.Errorf("Expected undo to take back the resignation, got %+v", undone)
+	}
+}
+
+func TestEngineMove(t *testing.T) {
+	c := newClient(t)
+	id := c.create(`{"engine": "random"}`).ID
+	var v View
+	if status := c.do("POST", "/games/"+id+"/engine-move", "", &v); status != http.StatusOK {
+		t.Fatalf("Expected the engine to move, got %d", status)
+	}
+	if len(v.Moves) != 1 || v.Moves[0].Color != "black" || v.ToMove != "white" {
+		t.Errorf("Expected one engine move for Black, got %+v", v)
+	}
+}
+
+func TestSGF(t *testing.T) {
+	c := newClient(t)
+	id := c.create(`{"handicap": 2, "engine": "random"}`).ID
+	c.do("POST", "/games/"+id+"/play", `{"row":2,"col":2}`, nil)
+	resp, err := http.Get(c.srv.URL + "/games/" + id + "/sgf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/x-go-sgf" {
+		t.Errorf("Expected an SGF content type, got %q", ct)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	roots, err := sgf.ParseString(string(data))
+	if err != nil {
+		t.Fatalf("Expected a valid SGF record, got %v: %s", err, data)
+	}
+	record, err := sgf.MainLine(roots[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(record.SetupBlack) != 2 || len(record.Moves) != 1 || record.Komi != 0.5 {
+		t.Errorf("Expected the handicap stones and one move, got %+v", record)
+	}
+	if ha, _ := roots[0].Get("HA"); ha != "2" {
+		t.Errorf("Expected HA[2], got %q", ha)
+	}
+}
+
+func TestUnknownGame(t *testing.T) {
+	c := newClient(t)
+	for _, req := range [][2]string{{"GET", "/games/nope"}, {"POST", "/games/nope/pass"}, {"GET", "/games/nope/sgf"}, {"POST", "/games/nope/engine-move"}} {
+		if status := c.do(req[0], req[1], "", &map[string]string{}); status != http.StatusNotFound {
+			t.Errorf("Expected 404 for %s %s, got %d", req[0], req[1], status)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+)
+
+// ErrNotFound is returned for unknown game ids.
+var ErrNotFound = errors.New("server: game not found")
+
+// Store keeps the games of a server. Implementations must be safe for
+// concurrent use and must not share the games they return with the caller.
+type Store interface {
+	// Create stores a new game and assigns its ID.
+	Create(g *Game) error
+	// Get returns the game with the given id or ErrNotFound.
+	Get(id string) (*Game, error)
+	// Update replaces a stored game or returns ErrNotFound.
+	Update(g *Game) error
+}
+
+// MemoryStore keeps games in memory.
+type MemoryStore struct {
+	mu    sync.Mutex
+	games map[string]*Game
+}
+
+// NewMemoryStore returns an empty store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{games: make(map[string]*Game)}
+}
+
+func (s *MemoryStore) Create(g *Game) error {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g.ID = hex.EncodeToString(id)
+	s.games[g.ID] = g.clone()
+	return nil
+}
+
+func (s *MemoryStore) Get(id string) (*Game, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g, ok := s.games[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return g.clone(), nil
+}
+
+func (s *MemoryStore) Update(g *Game) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.games[g.ID]; !ok {
+		return ErrNotFound
+	}
+	s.games[g.ID] = g.clone()
+	return nil
+}
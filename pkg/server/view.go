@@ -0,0 +1,141 @@
+package server
+
+import (
+	"github.com/RubikNube/GoInGo/pkg/clock"
+	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/sgf"
+)
+
+// View is the JSON representation of a game. Board rows are strings of
+// '.', 'X' (Black) and 'O' (White); points are SGF coordinates.
+type View struct {
+	ID       string     `json:"id"`
+	Size     int        `json:"size"`
+	Komi     float64    `json:"komi"`
+	Handicap int        `json:"handicap"`
+	Engine   string     `json:"engine"`
+	Board    []string   `json:"board"`
+	ToMove   string     `json:"toMove"`
+	Ko       string     `json:"ko,omitempty"`
+	Moves    []MoveView `json:"moves"`
+	Captures Captures   `json:"captures"`
+	Over     bool       `json:"over"`
+	Result   string     `json:"result,omitempty"`
+	Score    *ScoreView `json:"score,omitempty"`
+	Clock    *ClockView `json:"clock,omitempty"`
+}
+
+// MoveView is a move of a View; Point is empty for a pass.
+type MoveView struct {
+	Number   int      `json:"number"`
+	Color    string   `json:"color"`
+	Point    string   `json:"point"`
+	Captured []string `json:"captured,omitempty"`
+}
+
+// Captures counts the stones captured by each colour.
+type Captures struct {
+	Black int `json:"black"`
+	White int `json:"white"`
+}
+
+// ScoreView is the area score of a finished game, komi included.
+type ScoreView struct {
+	Black float64 `json:"black"`
+	White float64 `json:"white"`
+}
+
+// ClockView shows the clocks of a timed game.
+type ClockView struct {
+	TimeControl string      `json:"timeControl"`
+	Running     string      `json:"running,omitempty"`
+	Black       PlayerClock `json:"black"`
+	White       PlayerClock `json:"white"`
+}
+
+// PlayerClock is the time a player has left; durations are in seconds.
+// Display is the time as a clock shows it, e.g. "4:59" or "0:25 (3)".
+type PlayerClock struct {
+	Display  string  `json:"display"`
+	Main     float64 `json:"main"`
+	Overtime bool    `json:"overtime,omitempty"`
+	Period   float64 `json:"period,omitempty"`
+	Periods  int     `json:"periods,omitempty"`
+	Stones   int     `json:"stones,omitempty"`
+	Flagged  bool    `json:"flagged,omitempty"`
+}
+
+// NewClockView returns the view of c.
+func NewClockView(c *clock.Clock) *ClockView {
+	player := func(color game.FieldState) PlayerClock {
+		s := c.State(color)
+		return PlayerClock{
+			Display:  s.String(),
+			Main:     s.Main.Seconds(),
+			Overtime: s.Overtime(),
+			Period:   s.Period.Seconds(),
+			Periods:  s.Periods,
+			Stones:   s.Stones,
+			Flagged:  s.Flagged,
+		}
+	}
+	v := &ClockView{TimeControl: c.TimeControl().String(), Black: player(game.Black), White: player(game.White)}
+	if c.Running() != game.Empty {
+		v.Running = colorName(c.Running())
+	}
+	return v
+}
+
+// NewView returns the view of g.
+func NewView(g *Game) View {
+	s := g.State()
+	v := View{
+		ID:       g.ID,
+		Size:     game.BoardSize,
+		Komi:     g.Komi,
+		Handicap: g.Handicap,
+		Engine:   g.Engine,
+		ToMove:   colorName(s.ToMove),
+		Moves:    make([]MoveView, len(g.Moves)),
+		Over:     g.Over(),
+		Result:   g.Result,
+	}
+	for _, row := range s.Board {
+		line := make([]byte, len(row))
+		for j, f := range row {
+			switch f {
+			case game.Black:
+				line[j] = 'X'
+			case game.White:
+				line[j] = 'O'
+			default:
+				line[j] = '.'
+			}
+		}
+		v.Board = append(v.Board, string(line))
+	}
+	if s.Ko != nil {
+		v.Ko = sgf.EncodePoint(s.Ko)
+	}
+	for i, m := range g.Moves {
+		mv := MoveView{Number: i + 1, Color: colorName(m.Color), Point: sgf.EncodePoint(m.Point)}
+		for _, c := range m.Captured {
+			mv.Captured = append(mv.Captured, sgf.EncodePoint(&c))
+		}
+		v.Moves[i] = mv
+	}
+	v.Captures.Black, v.Captures.White = g.Captures()
+	if g.Over() {
+		black, white := g.Score()
+		v.Score = &ScoreView{Black: black, White: white}
+	}
+	return v
+}
+
+// colorName returns "black" or "white".
+func colorName(c game.FieldState) string {
+	if c == game.White {
+		return "white"
+	}
+	return "black"
+}
@@ -0,0 +1,199 @@
+package sgf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+// Move is a move of a game record. Point is nil for a pass.
+type Move struct {
+	Color   game.FieldState
+	Point   *game.Point
+	Comment string
+}
+
+// Game is the main line of a game record.
+type Game struct {
+	Black   string
+	White   string
+	Komi    float64
+	Result  string
+	Comment string
+	// SetupBlack and SetupWhite are stones placed before the first move,
+	// e.g. handicap stones or a problem position.
+	SetupBlack []game.Point
+	SetupWhite []game.Point
+	Moves      []Move
+}
+
+// Tree converts g into an SGF game tree with a single line of play.
+func (g *Game) Tree() *Node {
+	root := &Node{}
+	root.Set("GM", "1")
+	root.Set("FF", "4")
+	root.Set("CA", "UTF-8")
+	root.Set("AP", "GoInGo")
+	root.Set("SZ", strconv.Itoa(game.BoardSize))
+	root.Set("KM", strconv.FormatFloat(g.Komi, 'f', -1, 64))
+	if g.Black != "" {
+		root.Set("PB", g.Black)
+	}
+	if g.White != "" {
+		root.Set("PW", g.White)
+	}
+	if g.Result != "" {
+		root.Set("RE", g.Result)
+	}
+	if g.Comment != "" {
+		root.Set("C", g.Comment)
+	}
+	if len(g.SetupBlack) > 0 {
+		root.Set("AB", encodePoints(g.SetupBlack)...)
+	}
+	if len(g.SetupWhite) > 0 {
+		root.Set("AW", encodePoints(g.SetupWhite)...)
+	}
+	node := root
+	for _, m := range g.Moves {
+		child := &Node{}
+		child.Set(colorID(m.Color), EncodePoint(m.Point))
+		if m.Comment != "" {
+			child.Set("C", m.Comment)
+		}
+		node = node.AddChild(child)
+	}
+	return root
+}
+
+// String returns g as SGF text.
+func (g *Game) String() string {
+	return g.Tree().String()
+}
+
+// MainLine reads the game information and the first variation of the tree
+// rooted at root.
+func MainLine(root *Node) (*Game, error) {
+	g := &Game{}
+	if size, ok := root.Get("SZ"); ok && size != strconv.Itoa(game.BoardSize) {
+		return nil, fmt.Errorf("sgf: unsupported board size %s", size)
+	}
+	g.Black, _ = root.Get("PB")
+	g.White, _ = root.Get("PW")
+	g.Result, _ = root.Get("RE")
+	g.Comment, _ = root.Get("C")
+	if km, ok := root.Get("KM"); ok {
+		komi, err := strconv.ParseFloat(strings.TrimSpace(km), 64)
+		if err != nil {
+			return nil, fmt.Errorf("sgf: invalid komi %q", km)
+		}
+		g.Komi = komi
+	}
+	var err error
+	if g.SetupBlack, err = decodePoints(root.Values("AB")); err != nil {
+		return nil, err
+	}
+	if g.SetupWhite, err = decodePoints(root.Values("AW")); err != nil {
+		return nil, err
+	}
+	for n := root; n != nil; {
+		m, ok, err := NodeMove(n)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			g.Moves = append(g.Moves, m)
+		}
+		if len(n.Children) == 0 {
+			break
+		}
+		n = n.Children[0]
+	}
+	return g, nil
+}
+
+// NodeMove returns the move played in n, if any.
+func NodeMove(n *Node) (Move, bool, error) {
+	for _, c := range []game.FieldState{game.Black, game.White} {
+		v, ok := n.Get(colorID(c))
+		if !ok {
+			continue
+		}
+		p, err := DecodePoint(v)
+		if err != nil {
+			return Move{}, false, err
+		}
+		comment, _ := n.Get("C")
+		return Move{Color: c, Point: p, Comment: comment}, true, nil
+	}
+	return Move{}, false, nil
+}
+
+// Winner returns the colour named in the result, or game.Empty for a draw,
+// a void game or an unknown result.
+func (g *Game) Winner() game.FieldState {
+	switch {
+	case strings.HasPrefix(g.Result, "B+"):
+		return game.Black
+	case strings.HasPrefix(g.Result, "W+"):
+		return game.White
+	}
+	return game.Empty
+}
+
+// FormatResult returns the SGF result for the given final scores, e.g.
+// "B+3.5", "W+12" or "0" for a draw.
+func FormatResult(black, white float64) string {
+	switch {
+	case black > white:
+		return "B+" + strconv.FormatFloat(black-white, 'f', -1, 64)
+	case white > black:
+		return "W+" + strconv.FormatFloat(white-black, 'f', -1, 64)
+	}
+	return "0"
+}
+
+func colorID(c game.FieldState) string {
+	if c == game.White {
+		return "W"
+	}
+	return "B"
+}
+
+func encodePoints(points []game.Point) []string {
+	values := make([]string, len(points))
+	for i := range points {
+		values[i] = EncodePoint(&points[i])
+	}
+	return values
+}
+
+// decodePoints decodes a point list, expanding compressed "aa:cc" ranges.
+func decodePoints(values []string) ([]game.Point, error) {
+	var points []game.Point
+	for _, v := range values {
+		from, to, isRange := strings.Cut(v, ":")
+		if !isRange {
+			to = from
+		}
+		a, err := DecodePoint(from)
+		if err != nil {
+			return nil, err
+		}
+		b, err := DecodePoint(to)
+		if err != nil {
+			return nil, err
+		}
+		if a == nil || b == nil {
+			continue
+		}
+		for r := min(a.Row, b.Row); r <= max(a.Row, b.Row); r++ {
+			for c := min(a.Col, b.Col); c <= max(a.Col, b.Col); c++ {
+				points = append(points, game.Point{Row: r, Col: c})
+			}
+		}
+	}
+	return points, nil
+}
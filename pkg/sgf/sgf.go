@@ -0,0 +1,318 @@
+// Package sgf reads and writes game records in the Smart Game Format (FF[4]).
+package sgf
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+// Property is a single SGF property such as B[dd] or AB[aa][bb].
+type Property struct {
+	ID     string
+	Values []string
+}
+
+// Node is a node of an SGF game tree. Properties keep their file order.
+type Node struct {
+	Properties []Property
+	Children   []*Node
+	Parent     *Node
+}
+
+// Get returns the first value of property id.
+func (n *Node) Get(id string) (string, bool) {
+	for _, p := range n.Properties {
+		if p.ID == id && len(p.Values) > 0 {
+			return p.Values[0], true
+		}
+	}
+	return "", false
+}
+
+// Values returns all values of property id.
+func (n *Node) Values(id string) []string {
+	for _, p := range n.Properties {
+		if p.ID == id {
+			return p.Values
+		}
+	}
+	return nil
+}
+
+// Set replaces the values of property id, adding it if necessary.
+func (n *Node) Set(id string, values ...string) {
+	for i, p := range n.Properties {
+		if p.ID == id {
+			n.Properties[i].Values = values
+			return
+		}
+	}
+	n.Properties = append(n.Properties, Property{ID: id, Values: values})
+}
+
+// AddChild appends child to n and returns child.
+func (n *Node) AddChild(child *Node) *Node {
+	child.Parent = n
+	n.Children = append(n.Children, child)
+	return child
+}
+
+// ErrSyntax is returned for malformed SGF input.
+var ErrSyntax = errors.New("sgf: syntax error")
+
+// Parse reads an SGF collection and returns the root node of every game tree.
+func Parse(r io.Reader) ([]*Node, error) {
+	data, err := io.ReadAll(bufio.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{data: data}
+	var roots []*Node
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			break
+		}
+		root, err := p.gameTree(nil)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, root)
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("%w: no game tree", ErrSyntax)
+	}
+	return roots, nil
+}
+
+// ParseString is like Parse for a string.
+func ParseString(s string) ([]*Node, error) {
+	return Parse(strings.NewReader(s))
+}
+
+type parser struct {
+	data []byte
+	pos  int
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w at offset %d: %s", ErrSyntax, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case ' ', '\t', '\r', '\n':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// gameTree parses "(" sequence { gameTree } ")" and returns the first node
+// of the sequence attached to parent.
+func (p *parser) gameTree(parent *Node) (*Node, error) {
+	p.skipSpace()
+	if p.pos >= len(p.data) || p.data[p.pos] != '(' {
+		return nil, p.errorf("expected '('")
+	}
+	p.pos++
+	var first, last *Node
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return nil, p.errorf("unterminated game tree")
+		}
+		if p.data[p.pos] != ';' {
+			break
+		}
+		p.pos++
+		n := &Node{}
+		if err := p.properties(n); err != nil {
+			return nil, err
+		}
+		if last == nil {
+			first = n
+			if parent != nil {
+				parent.AddChild(n)
+			}
+		} else {
+			last.AddChild(n)
+		}
+		last = n
+	}
+	if first == nil {
+		return nil, p.errorf("empty sequence")
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return nil, p.errorf("unterminated game tree")
+		}
+		switch p.data[p.pos] {
+		case '(':
+			if _, err := p.gameTree(last); err != nil {
+				return nil, err
+			}
+		case ')':
+			p.pos++
+			return first, nil
+		default:
+			return nil, p.errorf("unexpected %q", p.data[p.pos])
+		}
+	}
+}
+
+func (p *parser) properties(n *Node) error {
+	for {
+		p.skipSpace()
+		start := p.pos
+		for p.pos < len(p.data) && p.data[p.pos] >= 'A' && p.data[p.pos] <= 'Z' {
+			p.pos++
+		}
+		if start == p.pos {
+			return nil
+		}
+		prop := Property{ID: string(p.data[start:p.pos])}
+		for {
+			p.skipSpace()
+			if p.pos >= len(p.data) || p.data[p.pos] != '[' {
+				break
+			}
+			p.pos++
+			value, err := p.value()
+			if err != nil {
+				return err
+			}
+			prop.Values = append(prop.Values, value)
+		}
+		if len(prop.Values) == 0 {
+			return p.errorf("property %s without value", prop.ID)
+		}
+		n.Properties = append(n.Properties, prop)
+	}
+}
+
+func (p *parser) value() (string, error) {
+	var b strings.Builder
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		switch c {
+		case ']':
+			return b.String(), nil
+		case '\\':
+			if p.pos >= len(p.data) {
+				return "", p.errorf("unterminated value")
+			}
+			next := p.data[p.pos]
+			p.pos++
+			// A soft line break is removed.
+			if next == '\n' {
+				continue
+			}
+			if next == '\r' {
+				if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+					p.pos++
+				}
+				continue
+			}
+			b.WriteByte(next)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated value")
+}
+
+// Write writes the game trees rooted at roots as an SGF collection.
+func Write(w io.Writer, roots ...*Node) error {
+	bw := bufio.NewWriter(w)
+	for _, root := range roots {
+		writeTree(bw, root)
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// String returns the SGF text of the game tree rooted at n.
+func (n *Node) String() string {
+	var b strings.Builder
+	bw := bufio.NewWriter(&b)
+	writeTree(bw, n)
+	bw.Flush()
+	return b.String()
+}
+
+func writeTree(w *bufio.Writer, n *Node) {
+	w.WriteByte('(')
+	for {
+		writeNode(w, n)
+		if len(n.Children) != 1 {
+			break
+		}
+		n = n.Children[0]
+	}
+	for _, child := range n.Children {
+		w.WriteByte('\n')
+		writeTree(w, child)
+	}
+	w.WriteByte(')')
+}
+
+func writeNode(w *bufio.Writer, n *Node) {
+	w.WriteByte(';')
+	for _, p := range n.Properties {
+		w.WriteString(p.ID)
+		for _, v := range p.Values {
+			w.WriteByte('[')
+			w.WriteString(escape(v))
+			w.WriteByte(']')
+		}
+	}
+}
+
+func escape(v string) string {
+	if !strings.ContainsAny(v, `]\`) {
+		return v
+	}
+	var b strings.Builder
+	for _, r := range v {
+		if r == ']' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// EncodePoint returns the SGF coordinate of p, e.g. "cd" for column 2,
+// row 3. A nil point encodes a pass as the empty string.
+func EncodePoint(p *game.Point) string {
+	if p == nil {
+		return ""
+	}
+	return string([]byte{byte('a' + p.Col), byte('a' + p.Row)})
+}
+
+// DecodePoint parses an SGF coordinate. It returns nil for a pass, which is
+// written either as an empty value or as "tt" on boards up to 19x19.
+func DecodePoint(s string) (*game.Point, error) {
+	if s == "" || (s == "tt" && game.BoardSize <= 19) {
+		return nil, nil
+	}
+	if len(s) != 2 {
+		return nil, fmt.Errorf("sgf: invalid point %q", s)
+	}
+	col, row := int8(s[0])-'a', int8(s[1])-'a'
+	if row < 0 || row >= game.BoardSize || col < 0 || col >= game.BoardSize {
+		return nil, fmt.Errorf("sgf: point %q outside the board", s)
+	}
+	return &game.Point{Row: row, Col: col}, nil
+}
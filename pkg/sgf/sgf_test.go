@@ -0,0 +1,122 @@
+package sgf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+func TestPointRoundTrip(t *testing.T) {
+	p := &game.Point{Row: 3, Col: 2}
+	s := EncodePoint(p)
+	if s != "cd" {
+		t.Fatalf("Expected cd, got %q", s)
+	}
+	got, err := DecodePoint(s)
+	if err != nil || got == nil || *got != *p {
+		t.Errorf("Expected %+v, got %+v (%v)", p, got, err)
+	}
+	for _, pass := range []string{"", "tt"} {
+		if got, err := DecodePoint(pass); got != nil || err != nil {
+			t.Errorf("Expected pass for %q, got %+v (%v)", pass, got, err)
+		}
+	}
+	if _, err := DecodePoint("zz"); err == nil {
+		t.Error("Expected error for point outside the board")
+	}
+}
+
+func TestParseVariations(t *testing.T) {
+	roots, err := ParseString(`(;GM[1]SZ[9]C[root \] comment];B[ee](;W[cc]C[main])(;W[gg];B[gc]))`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := roots[0]
+	if c, _ := root.Get("C"); c != "root ] comment" {
+		t.Errorf("Expected escaped comment, got %q", c)
+	}
+	b := root.Children[0]
+	if len(b.Children) != 2 {
+		t.Fatalf("Expected 2 variations, got %d", len(b.Children))
+	}
+	if b.Children[1].Parent != b {
+		t.Error("Expected parent link on variation")
+	}
+	if v, _ := b.Children[1].Children[0].Get("B"); v != "gc" {
+		t.Errorf("Expected B[gc] in second variation, got %q", v)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{"", "(;B[aa]", "(;B)", "(B[aa])", "(;C[open)"} {
+		if _, err := ParseString(input); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestGameRoundTrip(t *testing.T) {
+	g := &Game{
+		Black:      "alphabeta",
+		White:      "random",
+		Komi:       6.5,
+		Result:     "B+3.5",
+		SetupBlack: []game.Point{{Row: 2, Col: 2}},
+		Moves: []Move{
+			{Color: game.Black, Point: &game.Point{Row: 4, Col: 4}, Comment: "eval 12"},
+			{Color: game.White, Point: nil},
+		},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, g.Tree()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "PB[alphabeta]") {
+		t.Errorf("Expected player name in %s", buf.String())
+	}
+	roots, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := MainLine(roots[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Black != g.Black || got.White != g.White || got.Komi != g.Komi || got.Result != g.Result {
+		t.Errorf("Expected game info %+v, got %+v", g, got)
+	}
+	if len(got.SetupBlack) != 1 || got.SetupBlack[0] != g.SetupBlack[0] {
+		t.Errorf("Expected setup stones %v, got %v", g.SetupBlack, got.SetupBlack)
+	}
+	if len(got.Moves) != 2 || *got.Moves[0].Point != *g.Moves[0].Point || got.Moves[0].Comment != "eval 12" || got.Moves[1].Point != nil {
+		t.Errorf("Expected moves %+v, got %+v", g.Moves, got.Moves)
+	}
+	if got.Winner() != game.Black {
+		t.Errorf("Expected Black to win, got %v", got.Winner())
+	}
+}
+
+func TestCompressedPointList(t *testing.T) {
+	roots, err := ParseString("(;AB[aa:bc])")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := MainLine(roots[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.SetupBlack) != 6 {
+		t.Errorf("Expected 6 stones from aa:bc, got %d", len(g.SetupBlack))
+	}
+}
+
+func TestFormatResult(t *testing.T) {
+	tests := map[string][2]float64{"B+3.5": {10, 6.5}, "W+1": {5, 6}, "0": {7, 7}}
+	for want, scores := range tests {
+		if got := FormatResult(scores[0], scores[1]); got != want {
+			t.Errorf("Expected %s, got %s", want, got)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package tsumego
+
+import (
+	"time"
+
+	"github.com/RubikNube/GoInGo/pkg/clock"
+	"github.com/RubikNube/GoInGo/pkg/engine"
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+// Engine plays the vital point of small chains whose life depends on who
+// moves first: it saves its own chains and kills the opponent's. Otherwise
+// it asks Fallback for a move.
+type Engine struct {
+	Fallback engine.Engine
+	// MaxLiberties limits the chains that are read to those with at most
+	// this many liberties.
+	MaxLiberties int
+	// MaxRegion skips chains whose region has more empty points.
+	MaxRegion int
+	// MaxNodes is the search budget per chain and side to move.
+	MaxNodes int
+}
+
+// NewEngine returns an Engine with budgets small enough for play.
+func NewEngine(fallback engine.Engine) *Engine {
+	return &Engine{Fallback: fallback, MaxLiberties: 3, MaxRegion: 8, MaxNodes: 2000}
+}
+
+// Move implements engine.Engine. Of several urgent chains the largest one
+// is played first. The ko point is respected.
+func (e *Engine) Move(board game.Board, player game.FieldState, ko *game.Point) *game.Point {
+	var best *game.Point
+	bestSize := 0
+	visited := make(map[game.Point]struct{})
+	for i := int8(0); i < game.BoardSize; i++ {
+		for j := int8(0); j < game.BoardSize; j++ {
+			p := game.Point{Row: i, Col: j}
+			if _, ok := visited[p]; ok || board[i][j] == game.Empty {
+				continue
+			}
+			stones, libs := game.Group(board, p)
+			for s := range stones {
+				visited[s] = struct{}{}
+			}
+			if len(stones) <= bestSize || len(libs) > e.MaxLiberties {
+				continue
+			}
+			if move := e.vitalPoint(board, p, player); move != nil && (ko == nil || *move != *ko) {
+				best, bestSize = move, len(stones)
+			}
+		}
+	}
+	if best != nil {
+		return best
+	}
+	return e.Fallback.Move(board, player, ko)
+}
+
+// vitalPoint returns the move of player that decides the life of the chain
+// at target, or nil if the chain's status does not depend on the side to
+// move or cannot be read within the budget.
+func (e *Engine) vitalPoint(board game.Board, target game.Point, player game.FieldState) *game.Point {
+	region := RegionAround(board, target, 1)
+	if len(region) == 0 || len(region) > e.MaxRegion {
+		return nil
+	}
+	p := Problem{Board: board, Target: target, ToMove: player, Region: region}
+	r, err := Solve(p, e.MaxNodes)
+	if err != nil || r.Move == nil {
+		return nil
+	}
+	p.ToMove = game.Opponent(player)
+	other, err := Solve(p, e.MaxNodes)
+	if err != nil || other.Status == Unknown || other.Status == r.Status {
+		return nil
+	}
+	return r.Move
+}
+
+// TimeLeft implements engine.TimeAware by passing the time on to Fallback.
+func (e *Engine) TimeLeft(player game.FieldState, remaining time.Duration, stones int) {
+	if t, ok := e.Fallback.(engine.TimeAware); ok {
+		t.TimeLeft(player, remaining, stones)
+	}
+}
+
+// TimeSettings implements engine.TimeSettingsAware by passing the time
+// control on to Fallback.
+func (e *Engine) TimeSettings(tc clock.TimeControl) {
+	if t, ok := e.Fallback.(engine.TimeSettingsAware); ok {
+		t.TimeSettings(tc)
+	}
+}
@@ -0,0 +1,82 @@
+package tsumego
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/sgf"
+)
+
+// FromSGF reads the problem position of root: the setup stones and the
+// side to move, taken from the PL property or else the colour of the first
+// move. The target is left for the caller to set.
+func FromSGF(root *sgf.Node) (Problem, error) {
+	g, err := sgf.MainLine(root)
+	if err != nil {
+		return Problem{}, err
+	}
+	p := Problem{ToMove: game.Black}
+	for _, s := range g.SetupBlack {
+		p.Board[s.Row][s.Col] = game.Black
+	}
+	for _, s := range g.SetupWhite {
+		p.Board[s.Row][s.Col] = game.White
+	}
+	if pl, ok := root.Get("PL"); ok {
+		switch strings.ToUpper(strings.TrimSpace(pl)) {
+		case "B":
+			p.ToMove = game.Black
+		case "W":
+			p.ToMove = game.White
+		default:
+			return Problem{}, fmt.Errorf("tsumego: invalid PL %q", pl)
+		}
+	} else if len(g.Moves) > 0 {
+		p.ToMove = g.Moves[0].Color
+	}
+	return p, nil
+}
+
+// SGF returns the problem with the solution tree of r as variations.
+func (p Problem) SGF(r Result) *sgf.Node {
+	root := (&sgf.Game{Comment: fmt.Sprintf("target %s is %s", sgf.EncodePoint(&p.Target), r.Status)}).Tree()
+	var black, white []string
+	for i := int8(0); i < game.BoardSize; i++ {
+		for j := int8(0); j < game.BoardSize; j++ {
+			switch p.Board[i][j] {
+			case game.Black:
+				black = append(black, sgf.EncodePoint(&game.Point{Row: i, Col: j}))
+			case game.White:
+				white = append(white, sgf.EncodePoint(&game.Point{Row: i, Col: j}))
+			}
+		}
+	}
+	if len(black) > 0 {
+		root.Set("AB", black...)
+	}
+	if len(white) > 0 {
+		root.Set("AW", white...)
+	}
+	if p.ToMove == game.White {
+		root.Set("PL", "W")
+	} else {
+		root.Set("PL", "B")
+	}
+	if r.Tree != nil {
+		addVariations(root, r.Tree)
+	}
+	return root
+}
+
+func addVariations(n *sgf.Node, v *Variation) {
+	for _, c := range v.Children {
+		child := &sgf.Node{}
+		id := "B"
+		if c.Color == game.White {
+			id = "W"
+		}
+		child.Set(id, sgf.EncodePoint(c.Move))
+		addVariations(n.AddChild(child), c)
+	}
+}
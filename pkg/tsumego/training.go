@@ -0,0 +1,249 @@
+package tsumego
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/sgf"
+)
+
+// A training problem is an SGF game tree whose variations are the lines the
+// solver may play. A line is correct if one of its nodes is marked as such,
+// by a TE (good move) property or a comment containing "correct" or
+// "right", and none is marked wrong, by a BM (bad move) property or a
+// comment containing "wrong" or "incorrect". Unmarked lines are wrong.
+
+// TrainingProblem is a problem loaded for training.
+type TrainingProblem struct {
+	Name    string // file name, with the tree number for collections
+	Root    *sgf.Node
+	Problem Problem // the start position; Target and Region are unused
+}
+
+// LoadTraining reads every game tree of the SGF files as a problem.
+func LoadTraining(paths []string) ([]TrainingProblem, error) {
+	var problems []TrainingProblem
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		roots, err := sgf.Parse(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for i, root := range roots {
+			p, err := FromSGF(root)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			name := filepath.Base(path)
+			if len(roots) > 1 {
+				name = fmt.Sprintf("%s #%d", name, i+1)
+			}
+			problems = append(problems, TrainingProblem{Name: name, Root: root, Problem: p})
+		}
+	}
+	return problems, nil
+}
+
+// AttemptState is the progress of an attempt.
+type AttemptState int
+
+const (
+	Playing AttemptState = iota
+	Solved
+	Failed
+)
+
+var (
+	// ErrIllegalMove is returned for occupied points and suicide; the
+	// player may try again.
+	ErrIllegalMove = errors.New("tsumego: illegal move")
+	// ErrFinished is returned when moves are played after the attempt ended.
+	ErrFinished = errors.New("tsumego: problem finished")
+)
+
+// Attempt is one try at a training problem. The player plays the side to
+// move of the problem, the opponent's replies are taken from the tree.
+type Attempt struct {
+	Board  game.Board
+	Player game.FieldState
+	State  AttemptState
+	// Comment is the comment of the last node reached, if any.
+	Comment string
+	node    *sgf.Node
+}
+
+// NewAttempt starts an attempt at p.
+func NewAttempt(p TrainingProblem) *Attempt {
+	return &Attempt{Board: p.Problem.Board, Player: p.Problem.ToMove, node: p.Root}
+}
+
+// Play plays the player's move p (nil for a pass) and returns the reply,
+// which is nil for a pass or when there is none. A move outside the
+// problem's variations fails the attempt.
+func (a *Attempt) Play(p *game.Point) (*game.Point, error) {
+	if a.State != Playing {
+		return nil, ErrFinished
+	}
+	if p != nil {
+		b, ok := play(a.Board, *p, a.Player)
+		if !ok {
+			return nil, ErrIllegalMove
+		}
+		a.Board = b
+	}
+	child := a.child(p)
+	if child == nil {
+		a.State, a.Comment = Failed, "That move is not in the problem's variations."
+		return nil, nil
+	}
+	a.enter(child)
+	if a.State != Playing {
+		return nil, nil
+	}
+
+	// Reply with a refutation if there is one, else with the first line.
+	reply := a.node.Children[0]
+	for _, c := range a.node.Children {
+		if !reachesCorrect(c) {
+			reply = c
+			break
+		}
+	}
+	m, _, err := sgf.NodeMove(reply)
+	if err != nil {
+		return nil, err
+	}
+	if m.Point != nil {
+		if b, ok := play(a.Board, *m.Point, m.Color); ok {
+			a.Board = b
+		}
+	}
+	a.enter(reply)
+	return m.Point, nil
+}
+
+// child returns the child of the current node playing p for the player.
+func (a *Attempt) child(p *game.Point) *sgf.Node {
+	for _, c := range a.node.Children {
+		m, ok, err := sgf.NodeMove(c)
+		if err != nil || !ok || m.Color != a.Player {
+			continue
+		}
+		if (m.Point == nil && p == nil) || (m.Point != nil && p != nil && *m.Point == *p) {
+			return c
+		}
+	}
+	return nil
+}
+
+// enter moves to n and ends the attempt at the end of a line.
+func (a *Attempt) enter(n *sgf.Node) {
+	a.node = n
+	a.Comment, _ = n.Get("C")
+	if len(n.Children) > 0 {
+		return
+	}
+	a.State = Failed
+	if lineCorrect(n) {
+		a.State = Solved
+	}
+}
+
+// mark returns +1 for a node marked correct, -1 for one marked wrong and 0 otherwise.
+func mark(n *sgf.Node) int {
+	if _, ok := n.Get("BM"); ok {
+		return -1
+	}
+	if _, ok := n.Get("TE"); ok {
+		return 1
+	}
+	c, _ := n.Get("C")
+	c = strings.ToLower(c)
+	switch {
+	case strings.Contains(c, "wrong"), strings.Contains(c, "incorrect"):
+		return -1
+	case strings.Contains(c, "correct"), strings.Contains(c, "right"):
+		return 1
+	}
+	return 0
+}
+
+// lineCorrect reports whether the line ending in n is correct.
+func lineCorrect(n *sgf.Node) bool {
+	correct := false
+	for ; n != nil; n = n.Parent {
+		switch mark(n) {
+		case -1:
+			return false
+		case 1:
+			correct = true
+		}
+	}
+	return correct
+}
+
+// reachesCorrect reports whether some line through n is correct.
+func reachesCorrect(n *sgf.Node) bool {
+	if len(n.Children) == 0 {
+		return lineCorrect(n)
+	}
+	for _, c := range n.Children {
+		if reachesCorrect(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// Session works through a list of training problems and counts the
+// solved and failed ones.
+type Session struct {
+	Problems []TrainingProblem
+	Index    int
+	Attempt  *Attempt
+	Solved   int
+	Failed   int
+}
+
+// NewSession starts a session at the first of problems, which must not be empty.
+func NewSession(problems []TrainingProblem) *Session {
+	return &Session{Problems: problems, Attempt: NewAttempt(problems[0])}
+}
+
+// Play plays p in the current attempt and counts the result once it ends.
+func (s *Session) Play(p *game.Point) (*game.Point, error) {
+	reply, err := s.Attempt.Play(p)
+	if err != nil {
+		return nil, err
+	}
+	switch s.Attempt.State {
+	case Solved:
+		s.Solved++
+	case Failed:
+		s.Failed++
+	}
+	return reply, nil
+}
+
+// Next moves on to the next problem, starting over after the last one. An
+// unfinished attempt counts as failed.
+func (s *Session) Next() {
+	if s.Attempt.State == Playing {
+		s.Failed++
+	}
+	s.Index = (s.Index + 1) % len(s.Problems)
+	s.Attempt = NewAttempt(s.Problems[s.Index])
+}
+
+// Current returns the problem being attempted.
+func (s *Session) Current() TrainingProblem {
+	return s.Problems[s.Index]
+}
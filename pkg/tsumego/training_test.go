@@ -0,0 +1,104 @@
+package tsumego
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+// trainingSGF is the straight three problem with one correct line and two
+// wrong ones. The second wrong move allows a white mistake as well as the
+// refutation.
+const trainingSGF = `(;GM[1]FF[4]SZ[9]PL[B]AW[da][ab:db]AB[ea][eb][ac:ec]
+(;B[ba];W[aa];B[ca]C[Correct!])
+(;B[aa]C[Wrong];W[ba])
+(;B[ca](;W[aa];B[ba]C[RIGHT])(;W[ba]C[White lives.])))`
+
+func loadTraining(t *testing.T) []TrainingProblem {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "problems.sgf")
+	if err := os.WriteFile(path, []byte(trainingSGF+trainingSGF), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	problems, err := LoadTraining([]string{path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 2 || problems[1].Name != "problems.sgf #2" {
+		t.Fatalf("Expected two named problems, got %+v", problems)
+	}
+	return problems
+}
+
+func TestTrainingCorrectLine(t *testing.T) {
+	a := NewAttempt(loadTraining(t)[0])
+	reply, err := a.Play(&game.Point{Row: 0, Col: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply == nil || *reply != (game.Point{Row: 0, Col: 0}) || a.State != Playing {
+		t.Fatalf("Expected reply at (0,0), got %+v in state %v", reply, a.State)
+	}
+	if _, err := a.Play(&game.Point{Row: 0, Col: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if a.State != Solved || a.Comment != "Correct!" {
+		t.Errorf("Expected solved, got %v %q", a.State, a.Comment)
+	}
+	if a.Board[1][0] != game.Empty {
+		t.Errorf("Expected the white group to be captured")
+	}
+}
+
+func TestTrainingRefutation(t *testing.T) {
+	a := NewAttempt(loadTraining(t)[0])
+	// Of the two replies to (0,2) the app picks the one that refutes it.
+	reply, err := a.Play(&game.Point{Row: 0, Col: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply == nil || *reply != (game.Point{Row: 0, Col: 1}) {
+		t.Fatalf("Expected the refutation (0,1), got %+v", reply)
+	}
+	if a.State != Failed || a.Comment != "White lives." {
+		t.Errorf("Expected failed, got %v %q", a.State, a.Comment)
+	}
+	if _, err := a.Play(nil); err != ErrFinished {
+		t.Errorf("Expected ErrFinished, got %v", err)
+	}
+}
+
+func TestTrainingWrongAndIllegalMoves(t *testing.T) {
+	a := NewAttempt(loadTraining(t)[0])
+	if _, err := a.Play(&game.Point{Row: 1, Col: 0}); err != ErrIllegalMove {
+		t.Errorf("Expected ErrIllegalMove, got %v", err)
+	}
+	if _, err := a.Play(&game.Point{Row: 0, Col: 0}); err != nil {
+		t.Fatal(err)
+	}
+	if a.State != Failed {
+		t.Errorf("Expected failed after a wrong move, got %v", a.State)
+	}
+	b := NewAttempt(loadTraining(t)[0])
+	if _, err := b.Play(&game.Point{Row: 5, Col: 5}); err != nil || b.State != Failed {
+		t.Errorf("Expected a move outside the tree to fail, got %v %v", b.State, err)
+	}
+}
+
+func TestSessionCounts(t *testing.T) {
+	s := NewSession(loadTraining(t))
+	s.Play(&game.Point{Row: 0, Col: 1})
+	s.Play(&game.Point{Row: 0, Col: 2})
+	s.Next()
+	s.Play(&game.Point{Row: 0, Col: 0})
+	if s.Solved != 1 || s.Failed != 1 || s.Index != 1 {
+		t.Errorf("Expected 1 solved and 1 failed at problem 2, got %+v", s)
+	}
+	s.Next()
+	s.Next()
+	if s.Index != 1 || s.Failed != 2 {
+		t.Errorf("Expected an unfinished problem to count as failed, got %+v", s)
+	}
+}
@@ -0,0 +1,294 @@
+// Package tsumego solves life-and-death problems with proof-number search.
+//
+// The attacker tries to capture the target chain, the defender tries to keep
+// it on the board. Only moves inside the region of interest and passes are
+// considered. Positions may not repeat along a line of play, so ko fights
+// are decided by who runs out of moves first. The defender has won once the
+// target chain has two liberties that are eyes of the chain only, since the
+// attacker can never fill either of them.
+package tsumego
+
+import (
+	"errors"
+	"math"
+
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+// Status is the outcome of a life-and-death problem.
+type Status int
+
+const (
+	Unknown Status = iota // not solved within the node budget
+	Dead                  // the attacker captures the target
+	Alive                 // the target cannot be captured
+)
+
+func (s Status) String() string {
+	switch s {
+	case Dead:
+		return "dead"
+	case Alive:
+		return "alive"
+	}
+	return "unknown"
+}
+
+// Problem is a life-and-death problem.
+type Problem struct {
+	Board  game.Board
+	Target game.Point // any stone of the chain in question
+	ToMove game.FieldState
+	// Region lists the points where moves are considered. Use RegionAround
+	// to derive it from the target chain.
+	Region []game.Point
+}
+
+// Variation is a node of a solution tree. Move is nil for a pass and for
+// the root, which holds the problem position.
+type Variation struct {
+	Color    game.FieldState
+	Move     *game.Point
+	Children []*Variation
+}
+
+// Result is the solution of a problem.
+type Result struct {
+	Status Status
+	// Move is the winning first move if the side to move wins, nil if it
+	// wins by passing or loses.
+	Move *game.Point
+	// Tree contains one winning move wherever the winner is to move and
+	// every reply of the loser, i.e. the proof or refutation tree.
+	Tree *Variation
+	// Nodes is the number of positions created by the search.
+	Nodes int
+}
+
+// ErrNoTarget is returned when the problem's target point is empty.
+var ErrNoTarget = errors.New("tsumego: no stone at target")
+
+// RegionAround returns the empty points within margin (in both directions)
+// of the chain at target.
+func RegionAround(b game.Board, target game.Point, margin int) []game.Point {
+	stones, _ := game.Group(b, target)
+	var region []game.Point
+	for i := int8(0); i < game.BoardSize; i++ {
+		for j := int8(0); j < game.BoardSize; j++ {
+			if b[i][j] != game.Empty {
+				continue
+			}
+			for s := range stones {
+				if abs(int(s.Row-i)) <= margin && abs(int(s.Col-j)) <= margin {
+					region = append(region, game.Point{Row: i, Col: j})
+					break
+				}
+			}
+		}
+	}
+	return region
+}
+
+// infinity is the proof or disproof number of a solved node.
+const infinity = math.MaxUint32
+
+type node struct {
+	board    game.Board
+	toMove   game.FieldState
+	move     *game.Point
+	passes   int
+	parent   *node
+	children []*node
+	pn, dn   uint32
+}
+
+// solver holds the state of a single search.
+type solver struct {
+	problem  Problem
+	defender game.FieldState
+	attacker game.FieldState
+	nodes    int
+}
+
+// Solve runs proof-number search on p until the problem is solved or
+// maxNodes positions have been created.
+func Solve(p Problem, maxNodes int) (Result, error) {
+	defender := p.Board[p.Target.Row][p.Target.Col]
+	if defender == game.Empty {
+		return Result{}, ErrNoTarget
+	}
+	s := &solver{problem: p, defender: defender, attacker: game.Opponent(defender)}
+	root := s.newNode(nil, p.Board, p.ToMove, nil, 0)
+	for root.pn != 0 && root.dn != 0 && s.nodes < maxNodes {
+		n := s.mostProving(root)
+		s.expand(n)
+		s.update(n)
+	}
+
+	result := Result{Nodes: s.nodes}
+	var winner game.FieldState
+	switch {
+	case root.pn == 0:
+		result.Status, winner = Dead, s.attacker
+	case root.dn == 0:
+		result.Status, winner = Alive, s.defender
+	default:
+		return result, nil
+	}
+	result.Tree = s.solution(root, winner)
+	result.Tree.Color = game.Empty
+	if p.ToMove == winner && len(result.Tree.Children) == 1 {
+		result.Move = result.Tree.Children[0].Move
+	}
+	return result, nil
+}
+
+// isOr reports whether n is an OR node, i.e. the attacker is to move.
+func (s *solver) isOr(n *node) bool {
+	return n.toMove == s.attacker
+}
+
+func (s *solver) newNode(parent *node, b game.Board, toMove game.FieldState, move *game.Point, passes int) *node {
+	s.nodes++
+	n := &node{board: b, toMove: toMove, move: move, passes: passes, parent: parent, pn: 1, dn: 1}
+	switch {
+	case b[s.problem.Target.Row][s.problem.Target.Col] != s.defender:
+		n.pn, n.dn = 0, infinity
+	case passes >= 2 || s.unconditionallyAlive(b):
+		n.pn, n.dn = infinity, 0
+	}
+	return n
+}
+
+// mostProving descends to the most-proving leaf.
+func (s *solver) mostProving(n *node) *node {
+	for n.children != nil {
+		var next *node
+		for _, c := range n.children {
+			if (s.isOr(n) && c.pn == n.pn) || (!s.isOr(n) && c.dn == n.dn) {
+				next = c
+				break
+			}
+		}
+		n = next
+	}
+	return n
+}
+
+// expand creates the children of n: every legal move in the region that
+// does not repeat a position of the current line, and a pass.
+func (s *solver) expand(n *node) {
+	next := game.Opponent(n.toMove)
+	n.children = []*node{}
+	for _, p := range s.problem.Region {
+		b, ok := play(n.board, p, n.toMove)
+		if !ok || s.repeats(n, b) {
+			continue
+		}
+		move := p
+		n.children = append(n.children, s.newNode(n, b, next, &move, 0))
+	}
+	n.children = append(n.children, s.newNode(n, n.board, next, nil, n.passes+1))
+	s.setNumbers(n)
+}
+
+// repeats reports whether b occurred on the line leading to n. A pass
+// repeats the position on purpose and is not checked.
+func (s *solver) repeats(n *node, b game.Board) bool {
+	for a := n; a != nil; a = a.parent {
+		if a.board == b {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *solver) setNumbers(n *node) {
+	if s.isOr(n) {
+		n.pn, n.dn = infinity, 0
+		for _, c := range n.children {
+			n.pn = min(n.pn, c.pn)
+			n.dn = add(n.dn, c.dn)
+		}
+	} else {
+		n.pn, n.dn = 0, infinity
+		for _, c := range n.children {
+			n.pn = add(n.pn, c.pn)
+			n.dn = min(n.dn, c.dn)
+		}
+	}
+}
+
+// update recomputes the proof numbers from n up to the root.
+func (s *solver) update(n *node) {
+	for ; n != nil; n = n.parent {
+		s.setNumbers(n)
+	}
+}
+
+// solution extracts the proof tree for winner below n.
+func (s *solver) solution(n *node, winner game.FieldState) *Variation {
+	v := &Variation{Color: game.Opponent(n.toMove), Move: n.move}
+	if n.children == nil {
+		return v
+	}
+	won := func(c *node) bool {
+		if winner == s.attacker {
+			return c.pn == 0
+		}
+		return c.dn == 0
+	}
+	for _, c := range n.children {
+		if !won(c) {
+			continue
+		}
+		v.Children = append(v.Children, s.solution(c, winner))
+		if n.toMove == winner {
+			break
+		}
+	}
+	return v
+}
+
+// unconditionallyAlive reports whether the target chain has two liberties
+// whose neighbours all belong to the chain. The attacker can fill neither,
+// because filling one would be suicide while the other is open.
+func (s *solver) unconditionallyAlive(b game.Board) bool {
+	stones, libs := game.Group(b, s.problem.Target)
+	eyes := 0
+	for l := range libs {
+		eye := true
+		for _, n := range game.Neighbors(l) {
+			if _, ok := stones[n]; !ok {
+				eye = false
+				break
+			}
+		}
+		if eye {
+			eyes++
+		}
+	}
+	return eyes >= 2
+}
+
+// play places color at p and removes captured stones. It reports false for
+// occupied points and suicide.
+func play(b game.Board, p game.Point, color game.FieldState) (game.Board, bool) {
+	next, _, err := game.Play(game.NewState(b, color), &p)
+	return next.Board, err == nil
+}
+
+// add adds proof numbers, saturating at infinity.
+func add(a, b uint32) uint32 {
+	if a >= infinity-b {
+		return infinity
+	}
+	return a + b
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,154 @@
+package tsumego
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RubikNube/GoInGo/pkg/clock"
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+// straightThree returns a white corner group with a straight three eye
+// space at (0,0)-(0,2), completely surrounded by Black.
+func straightThree() game.Board {
+	var b game.Board
+	b[0][3] = game.White
+	for j := 0; j <= 3; j++ {
+		b[1][j] = game.White
+	}
+	b[0][4], b[1][4] = game.Black, game.Black
+	for j := 0; j <= 4; j++ {
+		b[2][j] = game.Black
+	}
+	return b
+}
+
+func problem(b game.Board, toMove game.FieldState) Problem {
+	target := game.Point{Row: 1, Col: 0}
+	return Problem{Board: b, Target: target, ToMove: toMove, Region: RegionAround(b, target, 1)}
+}
+
+func TestStraightThreeAttackerToMoveKills(t *testing.T) {
+	r, err := Solve(problem(straightThree(), game.Black), 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Status != Dead {
+		t.Fatalf("Expected dead, got %v after %d nodes", r.Status, r.Nodes)
+	}
+	if r.Move == nil || *r.Move != (game.Point{Row: 0, Col: 1}) {
+		t.Errorf("Expected the vital point (0,1), got %+v", r.Move)
+	}
+	// Every white reply must be refuted in the tree.
+	reply := r.Tree.Children[0]
+	if len(reply.Children) < 2 {
+		t.Errorf("Expected refutations for all white replies, got %d", len(reply.Children))
+	}
+}
+
+func TestStraightThreeDefenderToMoveLives(t *testing.T) {
+	r, err := Solve(problem(straightThree(), game.White), 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Status != Alive {
+		t.Fatalf("Expected alive, got %v after %d nodes", r.Status, r.Nodes)
+	}
+	if r.Move == nil || *r.Move != (game.Point{Row: 0, Col: 1}) {
+		t.Errorf("Expected the vital point (0,1), got %+v", r.Move)
+	}
+}
+
+func TestTwoEyesAreAlive(t *testing.T) {
+	b := straightThree()
+	b[0][1] = game.White
+	r, err := Solve(problem(b, game.Black), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Status != Alive || r.Nodes != 1 {
+		t.Errorf("Expected alive at the root, got %v after %d nodes", r.Status, r.Nodes)
+	}
+}
+
+func TestNodeBudget(t *testing.T) {
+	r, err := Solve(problem(straightThree(), game.Black), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Status != Unknown || r.Tree != nil {
+		t.Errorf("Expected unknown result, got %+v", r)
+	}
+}
+
+func TestNoTarget(t *testing.T) {
+	_, err := Solve(Problem{ToMove: game.Black}, 10)
+	if err != ErrNoTarget {
+		t.Errorf("Expected ErrNoTarget, got %v", err)
+	}
+}
+
+type passEngine struct{}
+
+func (passEngine) Move(game.Board, game.FieldState, *game.Point) *game.Point { return nil }
+
+func TestEnginePlaysVitalPoint(t *testing.T) {
+	e := NewEngine(passEngine{})
+	for _, player := range []game.FieldState{game.Black, game.White} {
+		move := e.Move(straightThree(), player, nil)
+		if move == nil || *move != (game.Point{Row: 0, Col: 1}) {
+			t.Errorf("Expected %v to play the vital point (0,1), got %+v", player, move)
+		}
+	}
+	if move := e.Move(game.Board{}, game.Black, nil); move != nil {
+		t.Errorf("Expected the fallback on an empty board, got %+v", move)
+	}
+}
+
+// clockEngine records the time and the time control it was told.
+type clockEngine struct {
+	passEngine
+	remaining time.Duration
+	control   clock.TimeControl
+}
+
+func (e *clockEngine) TimeLeft(_ game.FieldState, remaining time.Duration, _ int) {
+	e.remaining = remaining
+}
+
+func (e *clockEngine) TimeSettings(tc clock.TimeControl) {
+	e.control = tc
+}
+
+func TestEngineForwardsTime(t *testing.T) {
+	fallback := &clockEngine{}
+	e := NewEngine(fallback)
+	tc := clock.TimeControl{System: clock.Canadian, Main: time.Minute, Period: 5 * time.Minute, Stones: 25}
+	e.TimeSettings(tc)
+	e.TimeLeft(game.Black, time.Minute, 0)
+	if fallback.control != tc || fallback.remaining != time.Minute {
+		t.Errorf("Expected the fallback to be told the time control and the time left, got %+v and %v", fallback.control, fallback.remaining)
+	}
+}
+
+func TestSGFRoundTrip(t *testing.T) {
+	p := problem(straightThree(), game.Black)
+	r, err := Solve(p, 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := p.SGF(r)
+	if len(root.Children) != 1 {
+		t.Fatalf("Expected a single first move, got %d", len(root.Children))
+	}
+	if v, _ := root.Children[0].Get("B"); v != "ba" {
+		t.Errorf("Expected first move B[ba], got %q", v)
+	}
+	back, err := FromSGF(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.Board != p.Board || back.ToMove != game.Black {
+		t.Errorf("Expected the problem position to round-trip")
+	}
+}
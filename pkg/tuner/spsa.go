@@ -0,0 +1,87 @@
+package tuner
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/RubikNube/GoInGo/pkg/compareengines"
+	"github.com/RubikNube/GoInGo/pkg/engine"
+	"github.com/RubikNube/GoInGo/pkg/game"
+)
+
+// SPSAOptions configures SPSA tuning.
+type SPSAOptions struct {
+	Iterations int
+	// GamesPerIteration is the number of games between the two perturbed
+	// engines per iteration. Colours alternate.
+	GamesPerIteration int
+	MaxMoves          int
+	// StepSize (a) and Perturbation (c) are the SPSA gain constants.
+	StepSize     float64
+	Perturbation float64
+	Seed         int64
+	// Progress, if set, is called after every iteration.
+	Progress func(iteration int, w engine.Weights)
+}
+
+// DefaultSPSAOptions returns the options used by the tune command.
+func DefaultSPSAOptions() SPSAOptions {
+	return SPSAOptions{
+		Iterations:        100,
+		GamesPerIteration: 2,
+		MaxMoves:          100,
+		StepSize:          4,
+		Perturbation:      2,
+		Seed:              1,
+	}
+}
+
+// SPSA tunes the weights with simultaneous perturbation stochastic
+// approximation: each iteration perturbs all weights at once in a random
+// direction, lets the two perturbed engines play each other and moves the
+// weights towards the winner.
+func SPSA(start engine.Weights, opts SPSAOptions) engine.Weights {
+	rng := rand.New(rand.NewSource(opts.Seed))
+	theta := vector(start)
+	// Stability constant A of the standard gain sequence.
+	stability := float64(opts.Iterations) / 10
+	for k := 0; k < opts.Iterations; k++ {
+		ak := opts.StepSize / math.Pow(float64(k+1)+stability, 0.602)
+		ck := opts.Perturbation / math.Pow(float64(k+1), 0.101)
+		delta := make([]float64, len(theta))
+		plus := make([]float64, len(theta))
+		minus := make([]float64, len(theta))
+		for i := range theta {
+			delta[i] = float64(2*rng.Intn(2) - 1)
+			plus[i] = theta[i] + ck*delta[i]
+			minus[i] = theta[i] - ck*delta[i]
+		}
+		score := match(fromVector(plus), fromVector(minus), opts)
+		for i := range theta {
+			theta[i] += ak * score / (2 * ck) * delta[i]
+		}
+		if opts.Progress != nil {
+			opts.Progress(k+1, fromVector(theta))
+		}
+	}
+	return fromVector(theta)
+}
+
+// match plays a and b against each other and returns a's average result
+// in [-1, 1].
+func match(a, b engine.Weights, opts SPSAOptions) float64 {
+	if opts.GamesPerIteration == 0 {
+		return 0
+	}
+	total := 0
+	for g := 0; g < opts.GamesPerIteration; g++ {
+		engineA := engine.NewAlphaBetaEngineWithWeights(a)
+		engineB := engine.NewAlphaBetaEngineWithWeights(b)
+		if g%2 == 0 {
+			total += compareengines.CompareEngines(engineA, engineB, game.NewBoard(), game.Black, opts.MaxMoves)
+		} else {
+			total -= compareengines.CompareEngines(engineB, engineA, game.NewBoard(), game.Black, opts.MaxMoves)
+		}
+	}
+	return float64(total) / float64(opts.GamesPerIteration)
+}
@@ -0,0 +1,167 @@
+package tuner
+
+import (
+	"math"
+	"os"
+
+	"github.com/RubikNube/GoInGo/pkg/engine"
+	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/sgf"
+)
+
+// Sample is a training position: its evaluation features from the side to
+// move's point of view and the final result for that side (1 win, 0.5 draw,
+// 0 loss).
+type Sample struct {
+	Features engine.Features
+	Result   float64
+}
+
+// SamplesFromGame replays g and returns one sample per position after the
+// first skip moves. Games without a decisive or drawn result yield no samples.
+func SamplesFromGame(g *sgf.Game, skip int) []Sample {
+	winner := g.Winner()
+	if winner == game.Empty && g.Result != "0" && g.Result != "Draw" {
+		return nil
+	}
+	board := game.NewBoard()
+	for _, p := range g.SetupBlack {
+		board[p.Row][p.Col] = game.Black
+	}
+	for _, p := range g.SetupWhite {
+		board[p.Row][p.Col] = game.White
+	}
+	var samples []Sample
+	for i, m := range g.Moves {
+		next, _, err := game.Play(game.NewState(board, m.Color), m.Point)
+		if err != nil {
+			break
+		}
+		board = next.Board
+		if i+1 < skip {
+			continue
+		}
+		toMove := game.Opponent(m.Color)
+		result := 0.5
+		if winner == toMove {
+			result = 1
+		} else if winner != game.Empty {
+			result = 0
+		}
+		samples = append(samples, Sample{
+			Features: engine.ExtractFeatures(board, toMove, m.Color),
+			Result:   result,
+		})
+	}
+	return samples
+}
+
+// LoadSamples reads the SGF files at paths and returns the samples of all
+// games they contain.
+func LoadSamples(paths []string, skip int) ([]Sample, error) {
+	var samples []Sample
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		roots, err := sgf.Parse(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, root := range roots {
+			g, err := sgf.MainLine(root)
+			if err != nil {
+				return nil, err
+			}
+			samples = append(samples, SamplesFromGame(g, skip)...)
+		}
+	}
+	return samples, nil
+}
+
+// MeanSquaredError returns the mean squared difference between the game
+// results and the win probability sigmoid(k*eval) predicted by w.
+func MeanSquaredError(samples []Sample, w engine.Weights, k float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, s := range samples {
+		d := s.Result - sigmoid(k*float64(w.Evaluate(s.Features)))
+		sum += d * d
+	}
+	return sum / float64(len(samples))
+}
+
+// FitScale returns the scaling constant k that minimises the error of w on
+// samples. It maps evaluation units to win probability and is kept fixed
+// while the weights are tuned.
+func FitScale(samples []Sample, w engine.Weights) float64 {
+	// Golden section search on log k.
+	lo, hi := math.Log(1e-5), math.Log(1)
+	f := func(x float64) float64 { return MeanSquaredError(samples, w, math.Exp(x)) }
+	const phi = 0.6180339887498949
+	a, b := hi-phi*(hi-lo), lo+phi*(hi-lo)
+	fa, fb := f(a), f(b)
+	for i := 0; i < 60; i++ {
+		if fa < fb {
+			hi, b, fb = b, a, fa
+			a = hi - phi*(hi-lo)
+			fa = f(a)
+		} else {
+			lo, a, fa = a, b, fb
+			b = lo + phi*(hi-lo)
+			fb = f(b)
+		}
+	}
+	return math.Exp((lo + hi) / 2)
+}
+
+// TexelOptions configures Texel tuning.
+type TexelOptions struct {
+	// Scale is the sigmoid scaling constant; zero fits it to the start weights.
+	Scale float64
+	// MaxPasses limits the number of passes over all weights.
+	MaxPasses int
+	// Progress, if set, is called after every pass with the current weights and error.
+	Progress func(pass int, w engine.Weights, err float64)
+}
+
+// Texel tunes the weights with the local search of Texel's tuning method:
+// every weight is moved by one in both directions and the change is kept
+// if it lowers the prediction error, until no weight improves any more.
+func Texel(samples []Sample, start engine.Weights, opts TexelOptions) (engine.Weights, float64) {
+	k := opts.Scale
+	if k == 0 {
+		k = FitScale(samples, start)
+	}
+	best := vector(start)
+	bestErr := MeanSquaredError(samples, start, k)
+	for pass := 1; opts.MaxPasses == 0 || pass <= opts.MaxPasses; pass++ {
+		improved := false
+		for i := range best {
+			for _, step := range []float64{1, -1} {
+				candidate := append([]float64(nil), best...)
+				candidate[i] += step
+				if err := MeanSquaredError(samples, fromVector(candidate), k); err < bestErr {
+					best, bestErr = candidate, err
+					improved = true
+					break
+				}
+			}
+		}
+		if opts.Progress != nil {
+			opts.Progress(pass, fromVector(best), bestErr)
+		}
+		if !improved {
+			break
+		}
+	}
+	return fromVector(best), bestErr
+}
+
+func sigmoid(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
@@ -0,0 +1,25 @@
+// Package tuner optimises the evaluation weights of the AlphaBetaEngine,
+// either by Texel-style logistic regression on game records or by SPSA
+// self-play matches.
+package tuner
+
+import (
+	"math"
+
+	"github.com/RubikNube/GoInGo/pkg/engine"
+)
+
+// vector returns the weights as a parameter vector in a fixed order.
+func vector(w engine.Weights) []float64 {
+	return []float64{float64(w.Stones), float64(w.Liberties), float64(w.Capturable), float64(w.Groups)}
+}
+
+// fromVector rounds a parameter vector back to integer weights.
+func fromVector(v []float64) engine.Weights {
+	return engine.Weights{
+		Stones:     int(math.Round(v[0])),
+		Liberties:  int(math.Round(v[1])),
+		Capturable: int(math.Round(v[2])),
+		Groups:     int(math.Round(v[3])),
+	}
+}
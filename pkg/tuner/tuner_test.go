@@ -0,0 +1,82 @@
+package tuner
+
+import (
+	"testing"
+
+	"github.com/RubikNube/GoInGo/pkg/engine"
+	"github.com/RubikNube/GoInGo/pkg/game"
+	"github.com/RubikNube/GoInGo/pkg/sgf"
+)
+
+func TestSamplesFromGame(t *testing.T) {
+	g := &sgf.Game{
+		Result: "B+5",
+		Moves: []sgf.Move{
+			{Color: game.Black, Point: &game.Point{Row: 4, Col: 4}},
+			{Color: game.White, Point: &game.Point{Row: 2, Col: 2}},
+			{Color: game.Black, Point: nil},
+		},
+	}
+	samples := SamplesFromGame(g, 1)
+	if len(samples) != 3 {
+		t.Fatalf("Expected 3 samples, got %d", len(samples))
+	}
+	// After Black's first move White is to move and lost.
+	if samples[0].Result != 0 || samples[0].Features.Stones != -1 {
+		t.Errorf("Expected White to move with one stone less and a lost result, got %+v", samples[0])
+	}
+	if samples[1].Result != 1 || samples[1].Features.Stones != 0 {
+		t.Errorf("Expected Black to move with equal stones and a won result, got %+v", samples[1])
+	}
+	if len(SamplesFromGame(&sgf.Game{Result: "?", Moves: g.Moves}, 0)) != 0 {
+		t.Error("Expected no samples for a game without result")
+	}
+}
+
+func TestTexelReducesError(t *testing.T) {
+	// The side with more stones wins, liberties are noise.
+	var samples []Sample
+	for s := -5; s <= 5; s++ {
+		if s == 0 {
+			continue
+		}
+		for l := -3; l <= 3; l++ {
+			result := 0.0
+			if s > 0 {
+				result = 1
+			}
+			samples = append(samples, Sample{Features: engine.Features{Stones: s, Liberties: l * 5}, Result: result})
+		}
+	}
+	start := engine.Weights{Stones: 1, Liberties: 4}
+	k := FitScale(samples, start)
+	before := MeanSquaredError(samples, start, k)
+	tuned, after := Texel(samples, start, TexelOptions{Scale: k, MaxPasses: 20})
+	if after >= before {
+		t.Errorf("Expected error to decrease from %f, got %f", before, after)
+	}
+	if tuned.Liberties >= start.Liberties {
+		t.Errorf("Expected liberty weight to shrink, got %+v", tuned)
+	}
+}
+
+func TestSPSARuns(t *testing.T) {
+	opts := SPSAOptions{Iterations: 1, GamesPerIteration: 2, MaxMoves: 2, StepSize: 1, Perturbation: 1, Seed: 3}
+	calls := 0
+	opts.Progress = func(int, engine.Weights) { calls++ }
+	w := SPSA(engine.DefaultWeights(), opts)
+	if calls != 1 {
+		t.Errorf("Expected 1 progress call, got %d", calls)
+	}
+	d := engine.DefaultWeights()
+	if abs(w.Stones-d.Stones) > 1 || abs(w.Groups-d.Groups) > 1 {
+		t.Errorf("Expected a single small step from %+v, got %+v", d, w)
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
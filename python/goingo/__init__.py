@@ -0,0 +1,311 @@
+"""Python bindings for libgoengine, the shared library built from export/.
+
+Boards and engines own handles into the library and release them when
+closed, preferably with a ``with`` block::
+
+    from goingo import Board, Engine
+
+    with Board() as board, Engine.alpha_beta() as engine:
+        move = engine.move(board)
+        if move is not None:
+            board.play(*move)
+
+The library is looked up in the ``GOINGO_LIBRARY`` environment variable,
+next to this package and in the repository root; ``build_library`` builds
+it with the Go toolchain.
+"""
+
+from __future__ import annotations
+
+import ctypes
+import ctypes.util
+import enum
+import os
+import subprocess
+from pathlib import Path
+from typing import List, Optional, Tuple
+
+__all__ = [
+    "Board",
+    "Color",
+    "Engine",
+    "ErrorCode",
+    "GoInGoError",
+    "IllegalMoveError",
+    "InvalidHandleError",
+    "Match",
+    "MatchResult",
+    "build_library",
+    "load_library",
+]
+
+LIBRARY_NAME = "libgoengine.so"
+_REPO_ROOT = Path(__file__).resolve().parents[2]
+
+
+class Color(enum.IntEnum):
+    EMPTY = 0
+    BLACK = 1
+    WHITE = 2
+
+
+class ErrorCode(enum.IntEnum):
+    """The GoInGoError codes of libgoengine.h."""
+
+    OK = 0
+    INVALID_HANDLE = -1
+    OCCUPIED = -2
+    SUICIDE = -3
+    KO = -4
+    SUPERKO = -5
+    OUT_OF_RANGE = -6
+    BUFFER_TOO_SMALL = -7
+
+
+class MatchResult(enum.IntEnum):
+    DRAW = 0
+    A_WINS = 1
+    B_WINS = 2
+
+
+class GoInGoError(Exception):
+    """A failed library call with its error code and message."""
+
+    def __init__(self, code: ErrorCode, message: str):
+        super().__init__(f"{code.name}: {message}" if message else code.name)
+        self.code = code
+        self.message = message
+
+
+class IllegalMoveError(GoInGoError):
+    """The move is on an occupied point, suicide or a ko or superko violation."""
+
+
+class InvalidHandleError(GoInGoError):
+    """The board or engine has been closed or was never created."""
+
+
+_ILLEGAL = {ErrorCode.OCCUPIED, ErrorCode.SUICIDE, ErrorCode.KO, ErrorCode.SUPERKO}
+
+_lib: Optional[ctypes.CDLL] = None
+
+
+def build_library(output: Optional[os.PathLike] = None) -> Path:
+    """Builds the shared library with ``go build`` and returns its path,
+    by default next to this package."""
+    path = Path(output) if output is not None else Path(__file__).resolve().parent / LIBRARY_NAME
+    subprocess.run(
+        ["go", "build", "-buildmode=c-shared", "-o", str(path), "./export"],
+        cwd=_REPO_ROOT,
+        check=True,
+    )
+    return path
+
+
+def load_library(path: Optional[os.PathLike] = None) -> ctypes.CDLL:
+    """Loads the library from path, or the default locations, declares the
+    signatures of its functions and makes it the library used by this
+    module. Loading again returns the library already loaded."""
+    global _lib
+    if _lib is not None and path is None:
+        return _lib
+    candidates = [path] if path is not None else _default_paths()
+    for candidate in candidates:
+        if candidate is not None and Path(candidate).exists():
+            _lib = _declare(ctypes.CDLL(str(candidate)))
+            return _lib
+    raise OSError(f"{LIBRARY_NAME} not found in {[str(c) for c in candidates if c]}; "
+                  "build it with goingo.build_library() or set GOINGO_LIBRARY")
+
+
+def _default_paths() -> List[Optional[str]]:
+    return [
+        os.environ.get("GOINGO_LIBRARY"),
+        str(Path(__file__).resolve().parent / LIBRARY_NAME),
+        str(_REPO_ROOT / LIBRARY_NAME),
+        ctypes.util.find_library("goengine"),
+    ]
+
+
+def _declare(lib: ctypes.CDLL) -> ctypes.CDLL:
+    u64, i, p = ctypes.c_uint64, ctypes.c_int, ctypes.POINTER(ctypes.c_int)
+    signatures = {
+        "LastErrorMessage": ([], ctypes.c_char_p),
+        "NewAlphaBetaEngine": ([], u64),
+        "OldAlphaBetaEngine": ([], u64),
+        "NewRandomEngine": ([], u64),
+        "FreeEngine": ([u64], i),
+        "NewBoard": ([i], u64),
+        "FreeBoard": ([u64], i),
+        "CompareEngines": ([u64, u64, u64, i, i], i),
+        "BoardSize": ([], i),
+        "PlayMove": ([u64, i, i], i),
+        "PassMove": ([u64], i),
+        "IsLegalMove": ([u64, i, i], i),
+        "ToMove": ([u64], i),
+        "LegalMoves": ([u64, p, i], i),
+        "GetBoard": ([u64, p, i], i),
+        "EngineMove": ([u64, u64, p, p], i),
+        "Score": ([u64, p, p], i),
+    }
+    for name, (argtypes, restype) in signatures.items():
+        f = getattr(lib, name)
+        f.argtypes = argtypes
+        f.restype = restype
+    return lib
+
+
+def _check(result: int) -> int:
+    """Returns result, or raises the error it encodes if it is negative."""
+    if result >= 0:
+        return result
+    code = ErrorCode(result)
+    message = load_library().LastErrorMessage().decode()
+    if code in _ILLEGAL:
+        raise IllegalMoveError(code, message)
+    if code == ErrorCode.INVALID_HANDLE:
+        raise InvalidHandleError(code, message)
+    raise GoInGoError(code, message)
+
+
+class _Handle:
+    """Owns a library handle and frees it on close."""
+
+    _free = ""
+
+    def __init__(self, handle: int):
+        self._handle = 0
+        if handle == 0:
+            message = load_library().LastErrorMessage().decode()
+            raise GoInGoError(ErrorCode.OUT_OF_RANGE, message)
+        self._handle = handle
+
+    @property
+    def handle(self) -> int:
+        if self._handle == 0:
+            raise InvalidHandleError(ErrorCode.INVALID_HANDLE, f"{type(self).__name__} is closed")
+        return self._handle
+
+    @property
+    def closed(self) -> bool:
+        return self._handle == 0
+
+    def close(self) -> None:
+        """Frees the handle; closing twice does nothing."""
+        if self._handle != 0:
+            _check(getattr(load_library(), self._free)(self._handle))
+            self._handle = 0
+
+    def __enter__(self):
+        return self
+
+    def __exit__(self, *exc) -> None:
+        self.close()
+
+    def __del__(self) -> None:
+        try:
+            self.close()
+        except Exception:
+            pass
+
+
+class Board(_Handle):
+    """A board with the side to move and the ko point."""
+
+    _free = "FreeBoard"
+
+    def __init__(self, size: int = 9):
+        super().__init__(load_library().NewBoard(size))
+
+    @property
+    def size(self) -> int:
+        return load_library().BoardSize()
+
+    @property
+    def to_move(self) -> Color:
+        return Color(_check(load_library().ToMove(self.handle)))
+
+    def play(self, row: int, col: int) -> int:
+        """Plays the side to move at row, col and returns the number of
+        captured stones. Raises IllegalMoveError for illegal moves."""
+        return _check(load_library().PlayMove(self.handle, row, col))
+
+    def pass_move(self) -> None:
+        _check(load_library().PassMove(self.handle))
+
+    def is_legal(self, row: int, col: int) -> bool:
+        return _check(load_library().IsLegalMove(self.handle, row, col)) == 1
+
+    def legal_moves(self) -> List[Tuple[int, int]]:
+        """Returns the points the side to move may play, in row-major order."""
+        n = self.size * self.size
+        buf = (ctypes.c_int * n)()
+        count = _check(load_library().LegalMoves(self.handle, buf, n))
+        return [divmod(buf[k], self.size) for k in range(count)]
+
+    def cells(self) -> List[List[Color]]:
+        """Returns the board as rows of colours."""
+        size = self.size
+        buf = (ctypes.c_int * (size * size))()
+        _check(load_library().GetBoard(self.handle, buf, size * size))
+        return [[Color(buf[r * size + c]) for c in range(size)] for r in range(size)]
+
+    def score(self) -> Tuple[int, int]:
+        """Returns the area scores of Black and White without komi."""
+        black, white = ctypes.c_int(), ctypes.c_int()
+        _check(load_library().Score(self.handle, ctypes.byref(black), ctypes.byref(white)))
+        return black.value, white.value
+
+    def __str__(self) -> str:
+        symbols = {Color.EMPTY: ".", Color.BLACK: "X", Color.WHITE: "O"}
+        return "\n".join("".join(symbols[c] for c in row) for row in self.cells())
+
+
+class Engine(_Handle):
+    """A move generator of the library."""
+
+    _free = "FreeEngine"
+
+    @classmethod
+    def alpha_beta(cls) -> "Engine":
+        return cls(load_library().NewAlphaBetaEngine())
+
+    @classmethod
+    def old_alpha_beta(cls) -> "Engine":
+        return cls(load_library().OldAlphaBetaEngine())
+
+    @classmethod
+    def random(cls) -> "Engine":
+        return cls(load_library().NewRandomEngine())
+
+    def move(self, board: Board) -> Optional[Tuple[int, int]]:
+        """Returns the engine's move for the side to move of board, or None
+        for a pass. The move is not played."""
+        row, col = ctypes.c_int(), ctypes.c_int()
+        if _check(load_library().EngineMove(self.handle, board.handle, ctypes.byref(row), ctypes.byref(col))) == 0:
+            return None
+        return row.value, col.value
+
+
+class Match:
+    """A game between two engines from a board's position, which is not changed."""
+
+    def __init__(self, engine_a: Engine, engine_b: Engine, board: Optional[Board] = None,
+                 first_player: Color = Color.BLACK, max_moves: int = 100):
+        self.engine_a = engine_a
+        self.engine_b = engine_b
+        self.board = board
+        self.first_player = first_player
+        self.max_moves = max_moves
+
+    def play(self) -> MatchResult:
+        """Plays the game with engine_a moving first."""
+        if self.board is not None:
+            return self._play(self.board)
+        with Board() as board:
+            return self._play(board)
+
+    def _play(self, board: Board) -> MatchResult:
+        result = load_library().CompareEngines(
+            self.engine_a.handle, self.engine_b.handle, board.handle, int(self.first_player), self.max_moves)
+        return MatchResult(_check(result))
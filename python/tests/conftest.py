@@ -0,0 +1,15 @@
+import shutil
+
+import pytest
+
+import goingo
+
+
+@pytest.fixture(scope="session", autouse=True)
+def library(tmp_path_factory):
+    """Builds the library from the Go sources so that the tests run against
+    the current exports."""
+    if shutil.which("go") is None:
+        pytest.skip("the Go toolchain is needed to build libgoengine")
+    path = goingo.build_library(tmp_path_factory.mktemp("lib") / goingo.LIBRARY_NAME)
+    return goingo.load_library(path)
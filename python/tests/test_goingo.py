@@ -0,0 +1,99 @@
+import pytest
+
+from goingo import (
+    Board,
+    Color,
+    Engine,
+    ErrorCode,
+    GoInGoError,
+    IllegalMoveError,
+    InvalidHandleError,
+    Match,
+    MatchResult,
+)
+
+
+def ko_board():
+    """Returns a board with a ko on the top edge just taken by White at (0,1)."""
+    board = Board()
+    for move in [(0, 0), (0, 3), (1, 1), (1, 2), (0, 2)]:
+        assert board.play(*move) == 0
+    assert board.play(0, 1) == 1
+    return board
+
+
+def test_new_board_is_empty_with_black_to_move():
+    with Board() as board:
+        assert board.size == 9
+        assert board.to_move == Color.BLACK
+        assert all(c == Color.EMPTY for row in board.cells() for c in row)
+        assert len(board.legal_moves()) == 81
+
+
+def test_unsupported_size():
+    with pytest.raises(GoInGoError) as info:
+        Board(19)
+    assert info.value.code == ErrorCode.OUT_OF_RANGE
+    assert "19" in info.value.message
+
+
+def test_capture_and_ko():
+    with ko_board() as board:
+        cells = board.cells()
+        assert cells[0][1] == Color.WHITE
+        assert cells[0][2] == Color.EMPTY
+        assert board.to_move == Color.BLACK
+        assert not board.is_legal(0, 2)
+        assert (0, 2) not in board.legal_moves()
+        with pytest.raises(IllegalMoveError) as info:
+            board.play(0, 2)
+        assert info.value.code == ErrorCode.KO
+
+
+def test_illegal_moves():
+    with ko_board() as board:
+        with pytest.raises(IllegalMoveError) as info:
+            board.play(0, 0)
+        assert info.value.code == ErrorCode.OCCUPIED
+        with pytest.raises(GoInGoError) as info:
+            board.play(9, 0)
+        assert info.value.code == ErrorCode.OUT_OF_RANGE
+
+
+def test_pass_switches_player():
+    with Board() as board:
+        board.pass_move()
+        assert board.to_move == Color.WHITE
+
+
+def test_score():
+    with Board() as board:
+        board.play(4, 4)
+        assert board.score() == (81, 0)
+
+
+def test_engine_move_is_legal():
+    with Board() as board, Engine.random() as engine:
+        move = engine.move(board)
+        assert move is not None
+        assert board.is_legal(*move)
+        assert board.cells()[move[0]][move[1]] == Color.EMPTY
+
+
+def test_match():
+    with Engine.random() as a, Engine.random() as b:
+        assert Match(a, b, max_moves=20).play() in set(MatchResult)
+
+
+def test_closed_handles():
+    board = Board()
+    board.close()
+    board.close()
+    assert board.closed
+    with pytest.raises(InvalidHandleError):
+        board.play(4, 4)
+    engine = Engine.random()
+    with engine:
+        pass
+    with pytest.raises(InvalidHandleError):
+        engine.move(Board())